@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/db/pg"
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	adminService "github.com/igortoigildin/goph-keeper/internal/server/service/admin"
+	dataRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/minio"
+	certRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/cert"
+	userRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/user"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/spf13/cobra"
+)
+
+// adminCmd manages users directly in storages of the server, so it works without running server
+// and without administrator account, e.g. to create the first one.
+var adminCmd = &cobra.Command{
+	Use:   "admin",
+	Short: "Manage users in storages of the server",
+}
+
+var adminCreateUserCmd = &cobra.Command{
+	Use:     "create-user LOGIN",
+	Short:   "Create user, password is read from standard input unless --password is set",
+	Example: "  echo \"$PASSWORD\" | goph-keeper-server admin create-user admin@example.com --role admin",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		role, _ := cmd.Flags().GetString("role")
+
+		password, err := readPassword(cmd)
+		if err != nil {
+			return err
+		}
+
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			if err := svc.CreateUser(ctx, args[0], password, role); err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "user %s created with role %s\n", args[0], role)
+
+			return nil
+		})
+	},
+}
+
+var adminSetRoleCmd = &cobra.Command{
+	Use:     "set-role LOGIN ROLE",
+	Short:   "Grant the role, user or admin, to the user",
+	Example: "  goph-keeper-server admin set-role admin@example.com admin",
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.SetRole(ctx, args[0], args[1])
+		})
+	},
+}
+
+var adminListUsersCmd = &cobra.Command{
+	Use:   "list-users",
+	Short: "List users with storage used by them",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			users, err := svc.ListUsers(ctx)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LOGIN\tROLE\tDISABLED\tCREATED\tBYTES\tOBJECTS")
+			for _, u := range users {
+				fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%d\t%d\n", u.Login, u.Role, u.Disabled,
+					u.CreatedAt.Format(time.DateTime), u.Bytes, u.Objects)
+			}
+
+			return w.Flush()
+		})
+	},
+}
+
+var adminDisableCmd = &cobra.Command{
+	Use:   "disable LOGIN",
+	Short: "Reject login and access tokens of the user until it is enabled",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.DisableUser(ctx, args[0])
+		})
+	},
+}
+
+var adminEnableCmd = &cobra.Command{
+	Use:   "enable LOGIN",
+	Short: "Allow the disabled user to log in again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.EnableUser(ctx, args[0])
+		})
+	},
+}
+
+var adminLogoutCmd = &cobra.Command{
+	Use:   "logout LOGIN",
+	Short: "Revoke all access tokens issued to the user",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.LogoutUser(ctx, args[0])
+		})
+	},
+}
+
+var adminResetPasswordCmd = &cobra.Command{
+	Use:   "reset-password LOGIN",
+	Short: "Issue one-time token for the user to set new password",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			token, expiresAt, err := svc.IssueResetToken(ctx, args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "reset token: %s\nexpires at: %s\n", token, expiresAt.Format(time.RFC3339))
+
+			return nil
+		})
+	},
+}
+
+var adminDeleteDataCmd = &cobra.Command{
+	Use:   "delete-data LOGIN",
+	Short: "Remove all secrets and files of the user, the account is kept",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+			return errors.New("data can not be restored, confirm removal with --yes")
+		}
+
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.DeleteUserData(ctx, args[0])
+		})
+	},
+}
+
+var adminBindCertCmd = &cobra.Command{
+	Use:   "bind-cert LOGIN IDENTITY",
+	Short: "Authenticate the user by client certificates with the identity",
+	Long: "Authenticate the user by client certificates with the identity. Identity is a subject alternative name\n" +
+		"or the common name of the certificate subject: email:NAME, uri:URI, dns:NAME or cn:NAME.",
+	Example: "  goph-keeper-server admin bind-cert user@example.com email:user@example.com",
+	Args:    cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.BindCert(ctx, args[0], args[1])
+		})
+	},
+}
+
+var adminUnbindCertCmd = &cobra.Command{
+	Use:   "unbind-cert IDENTITY",
+	Short: "Stop authenticating users by client certificates with the identity",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			return svc.UnbindCert(ctx, args[0])
+		})
+	},
+}
+
+var adminListCertsCmd = &cobra.Command{
+	Use:   "list-certs [LOGIN]",
+	Short: "List client certificate identities bound to the user or to all users",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var login string
+		if len(args) > 0 {
+			login = args[0]
+		}
+
+		return withAdminService(cmd, func(ctx context.Context, svc *adminService.AdminService) error {
+			bindings, err := svc.ListCerts(ctx, login)
+			if err != nil {
+				return err
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "LOGIN\tIDENTITY\tCREATED")
+			for _, b := range bindings {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", b.Login, b.Identity, b.CreatedAt.Format(time.DateTime))
+			}
+
+			return w.Flush()
+		})
+	},
+}
+
+func init() {
+	adminCreateUserCmd.Flags().String("password", "", "password of the user")
+	adminCreateUserCmd.Flags().String("role", models.RoleUser, "role of the user, user or admin")
+	adminDeleteDataCmd.Flags().Bool("yes", false, "confirm removal of data")
+
+	adminCmd.AddCommand(adminCreateUserCmd, adminSetRoleCmd, adminListUsersCmd, adminDisableCmd, adminEnableCmd,
+		adminLogoutCmd, adminResetPasswordCmd, adminDeleteDataCmd, adminBindCertCmd, adminUnbindCertCmd, adminListCertsCmd)
+}
+
+// withAdminService runs f with admin service using storages configured as for the server.
+func withAdminService(cmd *cobra.Command, f func(ctx context.Context, svc *adminService.AdminService) error) error {
+	cfg, err := config.Load(cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	// only errors are logged, so they do not clutter output of the command
+	logger.Initialize("error")
+
+	ctx := cmd.Context()
+
+	dbClient, err := pg.New(ctx, cfg.PG.DSN)
+	if err != nil {
+		return fmt.Errorf("failed to create db client: %w", err)
+	}
+	defer dbClient.Close()
+
+	svc := adminService.New(userRepository.NewRepository(dbClient), dataRepository.NewRepository(cfg.Minio),
+		certRepository.NewRepository(dbClient), cfg.Auth.ResetTokenTTL)
+
+	return f(ctx, svc)
+}
+
+// readPassword returns password set by --password flag or the first line of standard input.
+func readPassword(cmd *cobra.Command) (string, error) {
+	if password, _ := cmd.Flags().GetString("password"); password != "" {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		if err != nil {
+			return "", fmt.Errorf("failed to read password: %w", err)
+		}
+
+		return "", errors.New("password is empty")
+	}
+
+	return password, nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect configuration of the server",
+}
+
+var configPrintCmd = &cobra.Command{
+	Use:   "print",
+	Short: "Print effective configuration merged from defaults, config file, environment and flags",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		cfg, err := config.Load(cmd.Flags())
+		if err != nil {
+			return err
+		}
+
+		if redacted, _ := cmd.Flags().GetBool("redacted"); redacted {
+			*cfg = cfg.Redacted()
+		}
+
+		out, err := yaml.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+
+		fmt.Fprint(cmd.OutOrStdout(), string(out))
+
+		return nil
+	},
+}
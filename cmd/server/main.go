@@ -1,33 +1,77 @@
 package main
 
 import (
-	"context"
-	"log"
+	"errors"
+	"fmt"
+	"os"
 
 	"github.com/igortoigildin/goph-keeper/internal/server/app"
 	"github.com/igortoigildin/goph-keeper/internal/server/config"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/spf13/cobra"
 )
 
-// var configPath string
+// Exit codes of the server.
+const (
+	exitFailure       = 1 // failed to start or to serve
+	exitInvalidConfig = 2
+	exitUnclean       = 3 // stopped, but in-flight requests were aborted or resources were not released
+)
+
+var rootCmd = &cobra.Command{
+	Use:           "goph-keeper-server",
+	Short:         "Server of goph-keeper password manager",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	RunE:          runServer,
+}
 
-// func init() {
-// 	flag.StringVar(&configPath, "config-path", ".env", "path to config file")
-// }
+func init() {
+	config.RegisterFlags(rootCmd.PersistentFlags())
+
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configPrintCmd)
+	configPrintCmd.Flags().Bool("redacted", false, "hide passwords and keys")
+
+	rootCmd.AddCommand(adminCmd)
+}
 
 func main() {
-	cfg := config.MustLoad()
-	logger.Initialize(cfg.FlagLogLevel)
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(exitCode(err))
+	}
+}
 
-	app, err := app.NewApp(context.Background())
+func runServer(cmd *cobra.Command, _ []string) error {
+	cfg, err := config.Load(cmd.Flags())
 	if err != nil {
-		log.Fatalf("failed to init app: %s", err.Error())
+		return err
 	}
 
-	logger.Info("app initialized successfully")
+	logger.Initialize(cfg.Log.Level)
 
-	err = app.Run()
+	app, err := app.NewApp(cmd.Context(), cfg)
 	if err != nil {
-		log.Fatalf("failed to run app: %s", err.Error())
+		return fmt.Errorf("failed to init app: %w", err)
+	}
+
+	logger.Info("app initialized successfully")
+
+	if err := app.Run(); err != nil {
+		return fmt.Errorf("failed to run app: %w", err)
+	}
+
+	return nil
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, config.ErrInvalid):
+		return exitInvalidConfig
+	case errors.Is(err, app.ErrShutdown):
+		return exitUnclean
+	default:
+		return exitFailure
 	}
 }
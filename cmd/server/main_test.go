@@ -0,0 +1,17 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/app"
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, exitInvalidConfig, exitCode(fmt.Errorf("error loading config: %w", config.ErrInvalid)))
+	assert.Equal(t, exitUnclean, exitCode(fmt.Errorf("%w: %w", app.ErrShutdown, errors.New("grpc server did not stop"))))
+	assert.Equal(t, exitFailure, exitCode(errors.New("error serving grpc")))
+}
@@ -40,6 +40,39 @@ openssl req -newkey rsa:2048 -nodes -keyout "$CERT_DIR/server.key" \
 # Clean up the temporary config file
 rm "$CERT_DIR/openssl.cnf"
 
+echo "Generating CA of client certificates..."
+
+openssl req -newkey rsa:2048 -nodes -keyout "$CERT_DIR/client-ca.key" \
+  -x509 -days 365 -out "$CERT_DIR/client-ca.crt" \
+  -subj "/C=RU/ST=Dev/L=Dev/O=LocalDev/CN=GophKeeper client CA" \
+  -addext "basicConstraints=critical,CA:TRUE" \
+  -addext "keyUsage=critical,keyCertSign,cRLSign"
+
+CLIENT_EMAIL="${CLIENT_EMAIL:-user@example.com}"
+
+echo "Generating client certificate for $CLIENT_EMAIL..."
+
+openssl req -newkey rsa:2048 -nodes -keyout "$CERT_DIR/client.key" \
+  -out "$CERT_DIR/client.csr" \
+  -subj "/C=RU/ST=Dev/L=Dev/O=LocalDev/CN=$CLIENT_EMAIL"
+
+cat > "$CERT_DIR/client.cnf" << EOF
+keyUsage = digitalSignature, keyEncipherment
+extendedKeyUsage = clientAuth
+subjectAltName = email:$CLIENT_EMAIL
+EOF
+
+openssl x509 -req -in "$CERT_DIR/client.csr" -days 365 \
+  -CA "$CERT_DIR/client-ca.crt" -CAkey "$CERT_DIR/client-ca.key" -CAcreateserial \
+  -out "$CERT_DIR/client.crt" \
+  -extfile "$CERT_DIR/client.cnf"
+
+rm "$CERT_DIR/client.csr" "$CERT_DIR/client.cnf" "$CERT_DIR/client-ca.srl"
+
 echo "Certificates created:"
 echo " - $CERT_DIR/server.crt"
 echo " - $CERT_DIR/server.key"
+echo " - $CERT_DIR/client-ca.crt"
+echo " - $CERT_DIR/client-ca.key"
+echo " - $CERT_DIR/client.crt"
+echo " - $CERT_DIR/client.key"
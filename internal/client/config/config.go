@@ -6,6 +6,7 @@ import (
 
 	"github.com/igortoigildin/goph-keeper/buildinfo"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -36,6 +37,22 @@ func initConfig() error {
 
 	viper.AutomaticEnv()
 
+	// cached files are kept in chunks on disk, their total size is limited to 1 GiB by default
+	viper.SetDefault("CACHE_DIR", "client_data/cache")
+	viper.SetDefault("CACHE_SIZE_LIMIT", 1<<30)
+
+	// server certificate is trusted, client certificate is presented only if it is set
+	viper.SetDefault("TLS_CA_FILE", "certs/server.crt")
+	viper.SetDefault("TLS_CLIENT_CERT_FILE", "")
+	viper.SetDefault("TLS_CLIENT_KEY_FILE", "")
+
+	// spans are not recorded unless an exporter is set
+	viper.SetDefault("TRACING_EXPORTER", tracing.ExporterNone)
+	viper.SetDefault("TRACING_ENDPOINT", "localhost:4317")
+	viper.SetDefault("TRACING_INSECURE", true)
+	viper.SetDefault("TRACING_FILE", "client_data/traces.json")
+	viper.SetDefault("TRACING_SAMPLE_RATIO", 1)
+
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	return nil
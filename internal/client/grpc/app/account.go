@@ -0,0 +1,55 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	serviceAccount "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/account"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// account command
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Show information about your account",
+}
+
+func accountUsageCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "usage",
+		Short:   "Show storage used by your data and its limits",
+		Example: "  goph-keeper-app account usage",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			usage, err := serviceAccount.New().GetUsage(cmd.Context(), fmt.Sprintf(":%s", serverAddr))
+			if err != nil {
+				apperror.Exit("failed to get usage", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "\tUSED\tLIMIT")
+			fmt.Fprintf(w, "Storage\t%s\t%s\n", formatBytes(int64(usage.GetBytes())), formatLimit(usage.GetMaxBytes(), true))
+			fmt.Fprintf(w, "Secrets and files\t%d\t%s\n", usage.GetObjects(), formatLimit(usage.GetMaxObjects(), false))
+			fmt.Fprintf(w, "Object size\t\t%s\n", formatLimit(usage.GetMaxObjectSize(), true))
+			w.Flush()
+		},
+	}
+}
+
+// formatLimit formats the limit in bytes or as a number, zero limit is shown as unlimited.
+func formatLimit(limit uint64, bytes bool) string {
+	switch {
+	case limit == 0:
+		return "unlimited"
+	case bytes:
+		return formatBytes(int64(limit))
+	default:
+		return strconv.FormatUint(limit, 10)
+	}
+}
@@ -1,25 +1,32 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"time"
 
 	"github.com/igortoigildin/goph-keeper/internal/client/config"
 	"github.com/igortoigildin/goph-keeper/internal/client/grpc/models"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	syncService "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/sync"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/storage/chunks"
 	storage "github.com/igortoigildin/goph-keeper/internal/client/grpc/storage/sqlite"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	desc "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
 var (
 	loggerLevel string
 	rootCmd     = &cobra.Command{
-		Use:   "goph-keeper-app",
-		Short: "My cli app",
+		Use:                "goph-keeper-app",
+		Short:              "My cli app",
+		PersistentPreRunE:  startTracing,
+		PersistentPostRunE: stopTracing,
 	}
 	sessionDuration = time.Minute * 7
 	batchSize       = 1024 * 1024
@@ -33,7 +40,7 @@ type App struct {
 }
 
 type Syncer interface {
-	ListAllData(addr string) ([]*desc.ObjectInfo, error)
+	ListAllData(ctx context.Context, addr string) ([]*desc.ObjectInfo, error)
 }
 
 type ClientSaver interface {
@@ -44,7 +51,8 @@ type ClientSaver interface {
 	UpdateText(id, text, etag string) error
 	UpdateCredentials(id, service, username, password, etag string) error
 	UpdateBankDetails(id, cardNumber, cvc, expDate, bankName, etag string) error
-	UpdateFile(id, etag string, data []byte) error
+	UpdateFile(id, etag, filePath string) error
+	DeleteFile(id string) error
 }
 
 type ClientReceiver interface {
@@ -55,11 +63,17 @@ type ClientReceiver interface {
 	GetAllBankDetails() ([]models.BankDetails, error)
 	GetBankDetails(id string) (models.BankDetails, error)
 	GetFile(id string) (models.File, error)
+	OpenFile(id string) (io.ReadCloser, error)
 	ListAllFiles() ([]models.File, error)
 }
 
 func NewApp(dbPath string) (*App, error) {
-	storage, err := storage.NewClientRepository(dbPath)
+	store, err := chunks.New(viper.GetString("CACHE_DIR"), []byte(viper.GetString("ENCRYPTION_KEY")))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open file cache: %w", err)
+	}
+
+	storage, err := storage.NewClientRepository(dbPath, store, viper.GetInt64("CACHE_SIZE_LIMIT"))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to connect to DB: %w", err)
 	}
@@ -84,17 +98,23 @@ var downloadCmd = &cobra.Command{
 	Short: "Download data from storage",
 }
 
+// delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Delete data from storage",
+}
+
 var buildVersion string = "N/A"
 var buildDate string = "N/A"
 var buildCommit string = "N/A"
 
 func Execute() {
 
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(context.Background()); err != nil {
 		logger.Error("error executing root cmd", zap.Error(err))
 
 		fmt.Println("Error:", err)
-		os.Exit(1)
+		os.Exit(apperror.ExitCode(err))
 	}
 }
 
@@ -117,6 +137,12 @@ func init() {
 	createUserCmd.Flags().StringP("login", "l", "", "User login")
 	createUserCmd.Flags().StringP("password", "p", "", "User password")
 
+	rootCmd.AddCommand(resetCmd)
+	resetCmd.AddCommand(resetPasswordCmd)
+	resetPasswordCmd.Flags().StringP("login", "l", "", "User login")
+	resetPasswordCmd.Flags().StringP("token", "t", "", "Reset token")
+	resetPasswordCmd.Flags().StringP("password", "p", "", "New password")
+
 	rootCmd.AddCommand(loginCmd)
 	loginCmd.AddCommand(loginUserCmd)
 	loginUserCmd.Flags().StringP("login", "l", "", "User login")
@@ -144,6 +170,11 @@ func init() {
 	// download binary data
 	downloadCmd.AddCommand(downloadBinCmd(app))
 
+	rootCmd.AddCommand(deleteCmd)
+
+	// delete binary data
+	deleteCmd.AddCommand(deleteBinCmd(app))
+
 	// save card details
 	saveCmd.AddCommand(saveCardInfoCmd(app))
 
@@ -159,4 +190,15 @@ func init() {
 
 	// sync data with server
 	rootCmd.AddCommand(syncCmd)
+
+	// show audit log
+	rootCmd.AddCommand(auditCmd())
+
+	// show storage usage
+	accountCmd.AddCommand(accountUsageCmd())
+	rootCmd.AddCommand(accountCmd)
+
+	// manage api tokens
+	tokenCmd.AddCommand(tokenCreateCmd(), tokenListCmd(), tokenRevokeCmd())
+	rootCmd.AddCommand(tokenCmd)
 }
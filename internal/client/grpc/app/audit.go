@@ -0,0 +1,96 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	serviceAudit "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/audit"
+	desc "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// audit command
+func auditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Show log of accesses to your secrets and account events",
+		Example: "  goph-keeper-app audit --since 168h --action download\n" +
+			"  goph-keeper-app audit --object 092049f9-2719-44eb-aa12-25e167dcba13",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			req, limit, err := auditRequest(cmd)
+			if err != nil {
+				apperror.Exit("invalid audit query", status.Error(codes.InvalidArgument, err.Error()))
+			}
+
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			events, err := serviceAudit.New().Query(cmd.Context(), fmt.Sprintf(":%s", serverAddr), req, limit)
+			if err != nil {
+				apperror.Exit("failed to query audit log", err)
+			}
+
+			if len(events) == 0 {
+				fmt.Println("No events found")
+
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "TIME\tACTOR\tACTION\tOBJECT\tRESULT\tPEER\tREQUEST ID")
+			for _, e := range events {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", e.GetTime().AsTime().Local().Format(time.DateTime),
+					e.GetActor(), e.GetAction(), e.GetObjectId(), e.GetResult(), e.GetPeer(), e.GetRequestId())
+			}
+			w.Flush()
+		},
+	}
+
+	cmd.Flags().Duration("since", 0, "show events of the last period, e.g. 24h")
+	cmd.Flags().String("from", "", "show events at or after the time, RFC 3339")
+	cmd.Flags().String("to", "", "show events before the time, RFC 3339")
+	cmd.Flags().String("object", "", "show events of the secret with the id")
+	cmd.Flags().String("action", "", "show events of the action: login, register, upload, download, list, delete or share")
+	cmd.Flags().Int("limit", 100, "maximum number of events to show")
+
+	return cmd
+}
+
+func auditRequest(cmd *cobra.Command) (*desc.QueryRequest, int, error) {
+	req := &desc.QueryRequest{}
+
+	req.ObjectId, _ = cmd.Flags().GetString("object")
+	req.Action, _ = cmd.Flags().GetString("action")
+
+	since, _ := cmd.Flags().GetDuration("since")
+	if since > 0 {
+		req.From = timestamppb.New(time.Now().Add(-since))
+	}
+
+	for name, ts := range map[string]**timestamppb.Timestamp{"from": &req.From, "to": &req.To} {
+		value, _ := cmd.Flags().GetString(name)
+		if value == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid --%s: %w", name, err)
+		}
+		*ts = timestamppb.New(t)
+	}
+
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit <= 0 {
+		return nil, 0, fmt.Errorf("--limit must be positive")
+	}
+
+	return req, limit, nil
+}
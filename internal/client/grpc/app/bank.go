@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
 	serviceUp "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/upload"
 	"github.com/igortoigildin/goph-keeper/pkg/encryption"
@@ -65,8 +66,12 @@ func saveCardInfoCmd(app *App) *cobra.Command {
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
 			// Upload data to remote server
-			etag, err := clientService.SendBankDetails(fmt.Sprintf(":%s", serverAddr), encryptedCardNumber, encryptedCVC, encryptedExpDate, id.String(), meta)
+			etag, err := clientService.SendBankDetails(cmd.Context(), fmt.Sprintf(":%s", serverAddr), encryptedCardNumber, encryptedCVC, encryptedExpDate, id.String(), meta)
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to save bank details: ", err)
+				}
+
 				logger.Error("failed to save bank details: ", zap.Error(err))
 			}
 
@@ -104,8 +109,12 @@ func downloadCardInfoCmd(app *App) *cobra.Command {
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
 			// obtain data from remote server
-			_, err = clientService.DownloadBankDetails(fmt.Sprintf(":%s", serverAddr), idStr)
+			_, err = clientService.DownloadBankDetails(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr)
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to obtain card details from goph-keeper: ", err)
+				}
+
 				logger.Error("failed to obtain card details from goph-keeper: ", zap.Error(err))
 
 				// if remote server not responding, try reach local storage
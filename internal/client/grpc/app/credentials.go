@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
 	serviceUp "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/upload"
 	"github.com/igortoigildin/goph-keeper/pkg/encryption"
@@ -52,8 +53,12 @@ func savePasswordCmd(app *App) *cobra.Command {
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
 			// Sending credentials with created uuid to server.
-			etag, err := clientService.SendPassword(fmt.Sprintf(":%s", serverAddr), encryptedLogin, encryptedPassword, id.String(), meta)
+			etag, err := clientService.SendPassword(cmd.Context(), fmt.Sprintf(":%s", serverAddr), encryptedLogin, encryptedPassword, id.String(), meta)
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to send credentials to server:", err)
+				}
+
 				logger.Error("failed to send credentials to server:", zap.Error(err))
 			}
 
@@ -89,8 +94,12 @@ func downloadPassCmd(app *App) *cobra.Command {
 			clientService := serviceDown.New()
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
-			_, err = clientService.DownloadPassword(fmt.Sprintf(":%s", serverAddr), idStr)
+			_, err = clientService.DownloadPassword(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr)
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to obtain credentials from goph-keeper:", err)
+				}
+
 				// if remote server is not available, try to reach local storage
 				res, err := app.ClientReceiver.GetCredential(idStr)
 				if err != nil {
@@ -3,8 +3,10 @@ package app
 import (
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
 	serviceUp "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/upload"
 	fl "github.com/igortoigildin/goph-keeper/pkg/file"
@@ -19,16 +21,63 @@ func saveBinCmd(app *App) *cobra.Command {
 		Use:   "bin",
 		Short: "Save binary data in storage",
 		Run: func(cmd *cobra.Command, args []string) {
-			pathStr, err := cmd.Flags().GetString("file_path")
+			pathStr, err := cmd.Flags().GetString("path")
 			if err != nil {
 				log.Fatalf("failed to get path: %s\n", err.Error())
 			}
 
+			// file_path is kept for backward compatibility
+			if filePath, _ := cmd.Flags().GetString("file_path"); pathStr == "" {
+				pathStr = filePath
+			}
+
 			info, err := cmd.Flags().GetString("info")
 			if err != nil {
 				logger.Fatal("failed to get metadata", zap.Error(err))
 			}
 
+			direct, err := cmd.Flags().GetBool("direct")
+			if err != nil {
+				logger.Fatal("failed to get direct flag", zap.Error(err))
+			}
+
+			recursive, err := cmd.Flags().GetBool("recursive")
+			if err != nil {
+				logger.Fatal("failed to get recursive flag", zap.Error(err))
+			}
+
+			workers, err := cmd.Flags().GetInt("workers")
+			if err != nil {
+				logger.Fatal("failed to get workers", zap.Error(err))
+			}
+
+			stat, err := os.Stat(pathStr)
+			if err != nil {
+				logger.Fatal("failed to get file info", zap.Error(err))
+			}
+
+			if stat.IsDir() {
+				if !recursive {
+					logger.Fatal("path is a directory, use --recursive to save it", zap.String("path", pathStr))
+				}
+
+				if direct {
+					logger.Fatal("directories can not be saved with --direct")
+				}
+
+				serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+				id, err := app.saveFolder(cmd.Context(), fmt.Sprintf(":%s", serverAddr), pathStr, info, workers)
+				if err != nil {
+					apperror.Exit("failed to save directory: ", err)
+				}
+
+				logger.Info("Your directory saved successfully. Please keep your uuid and use it to retrive your data back from Goph-keeper.",
+					zap.String("uuid:", id))
+
+				return
+			}
+
 			// Creating new uuid for the file to be saved
 			id := uuid.New()
 
@@ -37,9 +86,14 @@ func saveBinCmd(app *App) *cobra.Command {
 
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
-			etag, err := clientService.SendFile(fmt.Sprintf(":%s", serverAddr), pathStr, batchSize, id.String(), info)
+			var etag string
+			if direct {
+				etag, err = clientService.SendFileDirect(cmd.Context(), fmt.Sprintf(":%s", serverAddr), pathStr, id.String(), info)
+			} else {
+				etag, err = clientService.SendFile(cmd.Context(), fmt.Sprintf(":%s", serverAddr), pathStr, batchSize, id.String(), info)
+			}
 			if err != nil {
-				logger.Fatal("failed to save binary file: ", zap.Error(err))
+				apperror.Exit("failed to save binary file: ", err)
 			}
 
 			// save file to local client's storage
@@ -54,8 +108,13 @@ func saveBinCmd(app *App) *cobra.Command {
 	}
 
 	cmd.Flags().StringP("file_name", "n", "", "Name of the file to be saved")
-	cmd.Flags().StringP("file_path", "p", "", "Path to the binary file, which need to be saved")
+	cmd.Flags().StringP("path", "p", "", "Path to the binary file or directory, which need to be saved")
+	cmd.Flags().String("file_path", "", "Path to the binary file, which need to be saved")
 	cmd.Flags().StringP("info", "i", "", "Additional metadata, if necessary")
+	cmd.Flags().BoolP("direct", "d", false, "Upload the file straight to object storage, bypassing the server")
+	cmd.Flags().BoolP("recursive", "r", false, "Save all files of the directory")
+	cmd.Flags().IntP("workers", "w", defaultWorkers, "Number of files of the directory uploaded concurrently")
+	_ = cmd.Flags().MarkDeprecated("file_path", "use --path instead")
 
 	return cmd
 }
@@ -75,24 +134,62 @@ func downloadBinCmd(app *App) *cobra.Command {
 				logger.Fatal("failed to get file_name:", zap.Error(err))
 			}
 
+			direct, err := cmd.Flags().GetBool("direct")
+			if err != nil {
+				logger.Fatal("failed to get direct flag", zap.Error(err))
+			}
+
 			// Initializing Download service
 			clientService := serviceDown.New()
 
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
-			_, err = clientService.DownloadFile(fmt.Sprintf(":%s", serverAddr), idStr, fileNameStr)
+			// saved directory is kept as folder manifest, files of the directory are downloaded one by one
+			folder, err := loadFolder(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr)
+			if err == nil {
+				name := fileNameStr
+				if name == "" {
+					name = folder.Name
+				}
+
+				if err := restoreFolder(cmd.Context(), fmt.Sprintf(":%s", serverAddr), folder, name, direct); err != nil {
+					logger.Error("failed to download directory", zap.Error(err))
+				}
+
+				return
+			}
+
+			if direct {
+				_, err = clientService.DownloadFileDirect(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr, fileNameStr)
+			} else {
+				_, err = clientService.DownloadFile(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr, fileNameStr)
+			}
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to obtain requested binary data from goph-keeper: ", err)
+				}
+
 				logger.Error("failed to obtain requested binary data from goph-keeper: ", zap.Error(err))
 
 				// if remote server not responding, try to reach local storage
 				res, err := app.ClientReceiver.GetFile(idStr)
 				if err != nil {
 					logger.Error("failed to obtain requested binary data from goph-keeper: ", zap.Error(err))
+
+					return
 				}
 
-				err = fl.SaveFileToDisk(res, "client_files")
+				content, err := app.ClientReceiver.OpenFile(idStr)
 				if err != nil {
-					logger.Error("file saved locally")
+					logger.Error("failed to read cached binary data: ", zap.Error(err))
+
+					return
+				}
+				defer content.Close()
+
+				err = fl.SaveFileToDisk(res, content, "client_files")
+				if err != nil {
+					logger.Error("failed to save cached file", zap.Error(err))
 				}
 
 			}
@@ -100,8 +197,48 @@ func downloadBinCmd(app *App) *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringP("id", "i", "", "A Universally Unique Identifier of needed binary")
+	cmd.Flags().StringP("id", "i", "", "A Universally Unique Identifier of needed binary or directory")
 	cmd.Flags().StringP("file_name", "n", "", "Name of the file")
+	cmd.Flags().BoolP("direct", "d", false, "Download the file straight from object storage, bypassing the server")
+
+	return cmd
+}
+
+func deleteBinCmd(app *App) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bin",
+		Short: "Delete binary data from storage",
+		Run: func(cmd *cobra.Command, args []string) {
+			idStr, err := cmd.Flags().GetString("id")
+			if err != nil {
+				logger.Fatal("failed to get file uuid:", zap.Error(err))
+			}
+
+			if idStr == "" {
+				logger.Error("file uuid is required")
+
+				return
+			}
+
+			clientService := serviceUp.New()
+
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			err = clientService.DeleteFile(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr)
+			if err != nil {
+				apperror.Exit("failed to delete binary data from goph-keeper: ", err)
+			}
+
+			err = app.ClientSaver.DeleteFile(idStr)
+			if err != nil {
+				logger.Error("error deleting file locally", zap.Error(err))
+			}
+
+			logger.Info("Your file deleted successfully.", zap.String("uuid:", idStr))
+		},
+	}
+
+	cmd.Flags().StringP("id", "i", "", "A Universally Unique Identifier of binary data to be deleted")
 
 	return cmd
 }
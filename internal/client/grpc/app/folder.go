@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/models"
+	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
+	serviceUp "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/upload"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	"github.com/igortoigildin/goph-keeper/pkg/encryption"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultWorkers is the number of files uploaded concurrently by default.
+	defaultWorkers = 4
+)
+
+// saveFolder uploads regular files of the directory concurrently, followed by the folder manifest
+// listing uploaded files with their relative paths, and returns id of the folder.
+// Files failed to upload are reported and left out of the manifest.
+func (app *App) saveFolder(ctx context.Context, addr, dir, info string, workers int) (string, error) {
+	var (
+		files []serviceUp.BatchFile
+		total int64
+	)
+	sizes := make(map[string]int64)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		fileInfo, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		// relative path is saved as file metadata
+		file := serviceUp.BatchFile{ID: uuid.NewString(), Path: path, Info: filepath.ToSlash(rel)}
+		files = append(files, file)
+		sizes[file.ID] = fileInfo.Size()
+		total += fileInfo.Size()
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("error reading directory: %w", err)
+	}
+
+	if len(files) == 0 {
+		return "", errors.New("directory has no files")
+	}
+
+	bar := newProgressBar(len(files), total)
+
+	clientService := serviceUp.New()
+	results, err := clientService.SendFiles(ctx, addr, files, batchSize, workers, func(res serviceUp.BatchResult) {
+		bar.add(sizes[res.ID], res.Err != nil)
+	})
+	bar.finish()
+	if err != nil {
+		return "", err
+	}
+
+	folder := models.Folder{Name: filepath.Base(filepath.Clean(dir))}
+
+	for _, res := range results {
+		if res.Err != nil {
+			logger.Error("failed to upload file", zap.String("path", res.Info), zap.Error(res.Err))
+
+			continue
+		}
+
+		folder.Files = append(folder.Files, models.FolderEntry{ID: res.ID, Path: res.Info, Size: sizes[res.ID]})
+
+		// save file to local client's storage
+		err = app.ClientSaver.SaveFile(res.ID, res.Path, res.Info, res.Etag)
+		if err != nil {
+			logger.Error("error saving file locally", zap.String("path", res.Info), zap.Error(err))
+		}
+	}
+
+	if len(folder.Files) == 0 {
+		return "", errors.New("no files uploaded")
+	}
+
+	if failed := len(files) - len(folder.Files); failed > 0 {
+		logger.Warn("some files were not uploaded", zap.Int("failed", failed), zap.Int("total", len(files)))
+	}
+
+	manifest, err := json.Marshal(folder)
+	if err != nil {
+		return "", fmt.Errorf("error encoding folder manifest: %w", err)
+	}
+
+	// manifest is compressed and encrypted as texts are
+	payload, algo, err := compression.Compress(manifest)
+	if err != nil {
+		return "", fmt.Errorf("error compressing folder manifest: %w", err)
+	}
+
+	encrypted, err := encryption.Encrypt(string(payload), []byte(viper.Get("ENCRYPTION_KEY").(string)))
+	if err != nil {
+		return "", fmt.Errorf("error encrypting folder manifest: %w", err)
+	}
+
+	id := uuid.NewString()
+
+	_, err = clientService.SendFolder(ctx, addr, encrypted, id, info, algo)
+	if err != nil {
+		return "", fmt.Errorf("error saving folder manifest: %w", err)
+	}
+
+	return id, nil
+}
+
+// loadFolder returns manifest of the folder with id, NotFound is returned if the id is not a folder.
+func loadFolder(ctx context.Context, addr, id string) (*models.Folder, error) {
+	res, err := serviceDown.New().DownloadFolder(ctx, addr, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var folder models.Folder
+	if err := json.Unmarshal([]byte(res.Text), &folder); err != nil {
+		return nil, fmt.Errorf("error decoding folder manifest: %w", err)
+	}
+
+	return &folder, nil
+}
+
+// restoreFolder downloads files of the folder into 'client_files/<name>' preserving their relative paths.
+// Files failed to download are reported without aborting the rest.
+func restoreFolder(ctx context.Context, addr string, folder *models.Folder, name string, direct bool) error {
+	var total int64
+	for _, entry := range folder.Files {
+		total += entry.Size
+	}
+
+	bar := newProgressBar(len(folder.Files), total)
+
+	clientService := serviceDown.New()
+
+	type failure struct {
+		path string
+		err  error
+	}
+
+	var failed []failure
+	for _, entry := range folder.Files {
+		fileName := filepath.Join(name, filepath.FromSlash(entry.Path))
+
+		var err error
+		if direct {
+			_, err = clientService.DownloadFileDirect(ctx, addr, entry.ID, fileName)
+		} else {
+			_, err = clientService.DownloadFile(ctx, addr, entry.ID, fileName)
+		}
+
+		bar.add(entry.Size, err != nil)
+		if err != nil {
+			failed = append(failed, failure{path: entry.Path, err: err})
+		}
+	}
+	bar.finish()
+
+	for _, f := range failed {
+		logger.Error("failed to download file", zap.String("path", f.path), zap.Error(f.err))
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d files not downloaded", len(failed), len(folder.Files))
+	}
+
+	logger.Info("folder downloaded", zap.String("path", serviceDown.LocalPath(name)))
+
+	return nil
+}
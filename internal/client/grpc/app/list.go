@@ -72,6 +72,8 @@ func listAllSavedSecrets(app *App) *cobra.Command {
 
 				logger.Info("File details:", zap.Any("File ID", file.ID),
 					zap.Any("metadata:", file.Info),
+					zap.Int64("size:", file.Size),
+					zap.Bool("cached:", file.Cached),
 				)
 			}
 
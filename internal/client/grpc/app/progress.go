@@ -0,0 +1,86 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+const progressWidth = 30
+
+// progressBar renders aggregate progress of a batch of files.
+type progressBar struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	totalFiles int
+	totalBytes int64
+	doneFiles  int
+	doneBytes  int64
+	failed     int
+}
+
+func newProgressBar(files int, bytes int64) *progressBar {
+	p := &progressBar{out: os.Stderr, totalFiles: files, totalBytes: bytes}
+	p.render()
+
+	return p
+}
+
+// add marks a file of size bytes as processed. It is safe for concurrent use.
+func (p *progressBar) add(size int64, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.doneFiles++
+	p.doneBytes += size
+	if failed {
+		p.failed++
+	}
+
+	p.render()
+}
+
+// finish ends the progress line.
+func (p *progressBar) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintln(p.out)
+}
+
+func (p *progressBar) render() {
+	ratio := 1.0
+	if p.totalBytes > 0 {
+		ratio = float64(p.doneBytes) / float64(p.totalBytes)
+	} else if p.totalFiles > 0 {
+		ratio = float64(p.doneFiles) / float64(p.totalFiles)
+	}
+
+	filled := int(ratio * progressWidth)
+	line := fmt.Sprintf("\r[%s%s] %3.0f%% %d/%d files %s/%s",
+		strings.Repeat("#", filled), strings.Repeat(".", progressWidth-filled), ratio*100,
+		p.doneFiles, p.totalFiles, formatBytes(p.doneBytes), formatBytes(p.totalBytes))
+	if p.failed > 0 {
+		line += fmt.Sprintf(" %d failed", p.failed)
+	}
+
+	fmt.Fprint(p.out, line)
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
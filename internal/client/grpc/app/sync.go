@@ -1,14 +1,15 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"go.uber.org/zap"
 )
 
 const (
@@ -32,10 +33,9 @@ func syncAllData(app *App) *cobra.Command {
 
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
-			err := app.RunSync(fmt.Sprintf(":%s", serverAddr))
+			err := app.RunSync(cmd.Context(), fmt.Sprintf(":%s", serverAddr))
 			if err != nil {
-				logger.Error("failed to sync all data", zap.Error(err))
-				return
+				apperror.Exit("failed to sync all data", err)
 			}
 
 		},
@@ -45,8 +45,8 @@ func syncAllData(app *App) *cobra.Command {
 
 // RunSync obtains list of all objects from server, uses etag to check if object is up to date.
 // if not, it downloads data from remote server via gRPC and updates local storage accordingly.
-func (app *App) RunSync(addr string) error {
-	objects, err := app.Syncer.ListAllData(addr)
+func (app *App) RunSync(ctx context.Context, addr string) error {
+	objects, err := app.Syncer.ListAllData(ctx, addr)
 	if err != nil {
 		return fmt.Errorf("error getting object list: %w", err)
 	}
@@ -64,7 +64,7 @@ func (app *App) RunSync(addr string) error {
 			if res.Etag != object.Etag {
 				// Get updated text from server
 				// Requesting text with provided uuid.
-				serverObj, err := clientService.DownloadText(fmt.Sprintf(":%s", serverAddr), objName)
+				serverObj, err := clientService.DownloadText(ctx, fmt.Sprintf(":%s", serverAddr), objName)
 				if err != nil {
 					return fmt.Errorf("error downloading text data: %w", err)
 				}
@@ -85,7 +85,7 @@ func (app *App) RunSync(addr string) error {
 
 			if res.Etag != object.Etag {
 				// Get updated bank details from server
-				serverObj, err := clientService.DownloadBankDetails(fmt.Sprintf(":%s", serverAddr), objName)
+				serverObj, err := clientService.DownloadBankDetails(ctx, fmt.Sprintf(":%s", serverAddr), objName)
 				if err != nil {
 					return fmt.Errorf("error downloading bank details: %w", err)
 				}
@@ -106,13 +106,13 @@ func (app *App) RunSync(addr string) error {
 
 			if res.Etag != object.Etag {
 				// Get updated file from server
-				serverObj, err := clientService.DownloadFile(fmt.Sprintf(":%s", serverAddr), objName, res.Filename)
+				serverObj, err := clientService.DownloadFile(ctx, fmt.Sprintf(":%s", serverAddr), objName, res.Filename)
 				if err != nil {
 					return fmt.Errorf("error downloading file: %w", err)
 				}
 
 				// Update file in local storage
-				err = app.ClientSaver.UpdateFile(objName, object.Etag, serverObj.Data)
+				err = app.ClientSaver.UpdateFile(objName, object.Etag, serverObj.Filename)
 				if err != nil {
 					return fmt.Errorf("error updating file: %w", err)
 				}
@@ -127,7 +127,7 @@ func (app *App) RunSync(addr string) error {
 
 			if res.Etag != object.Etag {
 				// Get updated login password from server
-				serverObj, err := clientService.DownloadPassword(fmt.Sprintf(":%s", serverAddr), objName)
+				serverObj, err := clientService.DownloadPassword(ctx, fmt.Sprintf(":%s", serverAddr), objName)
 				if err != nil {
 					return fmt.Errorf("error downloading login password: %w", err)
 				}
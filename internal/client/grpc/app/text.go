@@ -7,6 +7,8 @@ import (
 	serviceDown "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/download"
 	serviceUp "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/upload"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
 	"github.com/igortoigildin/goph-keeper/pkg/encryption"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/spf13/cobra"
@@ -48,23 +50,39 @@ func saveTextCmd(app *App) *cobra.Command {
 				zap.String("key_length", fmt.Sprintf("%d", len(encryptionKey))),
 			)
 
-			encryptedText, err := encryption.Encrypt(textData, encryptionKey)
+			// Compressing text before encryption, since encrypted data does not compress
+			payload, algo, err := compression.Compress([]byte(textData))
+			if err != nil {
+				logger.Fatal("failed to compress text data", zap.Error(err))
+			}
+
+			encryptedText, err := encryption.Encrypt(string(payload), encryptionKey)
 			if err != nil {
 				logger.Error("failed to encrypt text data", zap.Error(err))
 			}
 
 			logger.Debug("Text encrypted successfully",
 				zap.String("encrypted_length", fmt.Sprintf("%d", len(encryptedText))),
+				zap.String("compression", algo),
 			)
 
 			// Sending text to remote server
-			etag, err := clientService.SendText(fmt.Sprintf(":%s", serverAddr), encryptedText, id.String(), info)
+			etag, err := clientService.SendText(cmd.Context(), fmt.Sprintf(":%s", serverAddr), encryptedText, id.String(), info, algo)
 			if err != nil {
-				logger.Fatal("failed to save text", zap.Error(err))
+				apperror.Exit("failed to save text", err)
+			}
+
+			// Local storage keeps uncompressed text
+			localText := encryptedText
+			if algo != compression.None {
+				localText, err = encryption.Encrypt(textData, encryptionKey)
+				if err != nil {
+					logger.Error("failed to encrypt text data", zap.Error(err))
+				}
 			}
 
 			// Saving text locally in DB
-			err = app.ClientSaver.SaveText(id.String(), info, encryptedText, etag)
+			err = app.ClientSaver.SaveText(id.String(), info, localText, etag)
 			if err != nil {
 				logger.Error("failed to save text locally", zap.Error(err))
 			}
@@ -95,8 +113,12 @@ func downloadTextCmd(app *App) *cobra.Command {
 			serverAddr, _ := viper.Get("GRPC_PORT").(string)
 
 			// Requesting text with provided uuid.
-			_, err = clientService.DownloadText(fmt.Sprintf(":%s", serverAddr), idStr)
+			_, err = clientService.DownloadText(cmd.Context(), fmt.Sprintf(":%s", serverAddr), idStr)
 			if err != nil {
+				if !apperror.Offline(err) {
+					apperror.Exit("failed to obtain text data from remote server: ", err)
+				}
+
 				logger.Error("failed to obtain text data from remote server: ", zap.Error(err))
 
 				// if remote server not responding, try to reach local client storage
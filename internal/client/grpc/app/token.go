@@ -0,0 +1,140 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	authService "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/auth"
+	desc "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// token command
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Manage API tokens for CI and automation",
+	Long: "API tokens authenticate requests without login. Set " + session.TokenEnv +
+		" to the token to use it instead of the session saved by login.",
+}
+
+func tokenCreateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create API token, the token is shown only once",
+		Example: "  goph-keeper-app token create --name ci --expires 720h --read-only\n" +
+			"  goph-keeper-app token create --name deploy --secret 092049f9-2719-44eb-aa12-25e167dcba13 --type login_password",
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			expires, _ := cmd.Flags().GetDuration("expires")
+			readOnly, _ := cmd.Flags().GetBool("read-only")
+			secretIDs, _ := cmd.Flags().GetStringSlice("secret")
+			dataTypes, _ := cmd.Flags().GetStringSlice("type")
+
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			resp, err := authService.New(fmt.Sprintf(":%s", serverAddr)).CreateToken(cmd.Context(), &desc.CreateTokenRequest{
+				Name:      name,
+				ExpiresAt: timestamppb.New(time.Now().Add(expires)),
+				Scope: &desc.TokenScope{
+					ReadOnly:  readOnly,
+					SecretIds: secretIDs,
+					DataTypes: dataTypes,
+				},
+			})
+			if err != nil {
+				apperror.Exit("failed to create token", err)
+			}
+
+			fmt.Printf("Token %s created, it expires at %s\n", resp.GetInfo().GetId(),
+				resp.GetInfo().GetExpiresAt().AsTime().Local().Format(time.DateTime))
+			fmt.Println(resp.GetToken())
+		},
+	}
+
+	cmd.Flags().String("name", "", "name of the token")
+	cmd.Flags().Duration("expires", 720*time.Hour, "period the token is valid for")
+	cmd.Flags().Bool("read-only", false, "allow only downloading and listing data")
+	cmd.Flags().StringSlice("secret", nil, "allow access only to the secret with the id, may be repeated")
+	cmd.Flags().StringSlice("type", nil, "allow access only to data of the type: login_password, bank_data, text_data, bin_data, custom_data or folder_data")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func tokenListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "Show API tokens, which are not revoked",
+		Example: "  goph-keeper-app token list",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			tokens, err := authService.New(fmt.Sprintf(":%s", serverAddr)).ListTokens(cmd.Context())
+			if err != nil {
+				apperror.Exit("failed to list tokens", err)
+			}
+
+			if len(tokens) == 0 {
+				fmt.Println("No tokens found")
+
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tSCOPE\tCREATED\tEXPIRES")
+			for _, t := range tokens {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", t.GetId(), t.GetName(), formatScope(t.GetScope()),
+					t.GetCreatedAt().AsTime().Local().Format(time.DateTime),
+					t.GetExpiresAt().AsTime().Local().Format(time.DateTime))
+			}
+			w.Flush()
+		},
+	}
+}
+
+func tokenRevokeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "revoke ID",
+		Short:   "Revoke API token",
+		Example: "  goph-keeper-app token revoke 092049f9-2719-44eb-aa12-25e167dcba13",
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			serverAddr, _ := viper.Get("GRPC_PORT").(string)
+
+			if err := authService.New(fmt.Sprintf(":%s", serverAddr)).RevokeToken(cmd.Context(), args[0]); err != nil {
+				apperror.Exit("failed to revoke token", err)
+			}
+
+			fmt.Printf("Token %s revoked\n", args[0])
+		},
+	}
+}
+
+// formatScope describes access granted by the token scope.
+func formatScope(scope *desc.TokenScope) string {
+	var parts []string
+	if scope.GetReadOnly() {
+		parts = append(parts, "read-only")
+	}
+	if len(scope.GetSecretIds()) > 0 {
+		parts = append(parts, "secrets: "+strings.Join(scope.GetSecretIds(), ","))
+	}
+	if len(scope.GetDataTypes()) > 0 {
+		parts = append(parts, "types: "+strings.Join(scope.GetDataTypes(), ","))
+	}
+
+	if len(parts) == 0 {
+		return "full"
+	}
+
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,57 @@
+package app
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const serviceName = "goph-keeper-client"
+
+var (
+	commandSpan     trace.Span
+	shutdownTracing = func(context.Context) error { return nil }
+)
+
+// startTracing sets up export of spans and starts the span of the executed command,
+// so all RPCs made by the command are traced as its children.
+func startTracing(cmd *cobra.Command, _ []string) error {
+	shutdown, err := tracing.Init(cmd.Context(), serviceName, tracing.Config{
+		Exporter:    viper.GetString("TRACING_EXPORTER"),
+		Endpoint:    viper.GetString("TRACING_ENDPOINT"),
+		Insecure:    viper.GetBool("TRACING_INSECURE"),
+		File:        viper.GetString("TRACING_FILE"),
+		SampleRatio: viper.GetFloat64("TRACING_SAMPLE_RATIO"),
+	})
+	if err != nil {
+		// the command works without tracing
+		logger.Error("failed to initialize tracing", zap.Error(err))
+
+		return nil
+	}
+	shutdownTracing = shutdown
+
+	ctx, span := tracing.Tracer("github.com/igortoigildin/goph-keeper/internal/client/grpc/app").Start(cmd.Context(), cmd.CommandPath())
+	commandSpan = span
+	cmd.SetContext(ctx)
+
+	return nil
+}
+
+// stopTracing ends the span of the command and flushes spans.
+func stopTracing(cmd *cobra.Command, _ []string) error {
+	if commandSpan != nil {
+		commandSpan.End()
+	}
+
+	if err := shutdownTracing(context.Background()); err != nil {
+		logger.Error("failed to flush spans", zap.Error(err))
+	}
+
+	return nil
+}
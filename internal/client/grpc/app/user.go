@@ -1,10 +1,10 @@
 package app
 
 import (
-	"context"
 	"fmt"
 	"time"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
 	authService "github.com/igortoigildin/goph-keeper/internal/client/grpc/service/auth"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/igortoigildin/goph-keeper/pkg/session"
@@ -40,10 +40,8 @@ var createUserCmd = &cobra.Command{
 		serverAddr, _ := viper.Get("GRPC_PORT").(string)
 		authService := authService.New(fmt.Sprintf(":%s", serverAddr))
 
-		if err = authService.RegisterNewUser(context.Background(), loginStr, passStr); err != nil {
-			logger.Error("registration failed:", zap.Error(err))
-
-			return
+		if err = authService.RegisterNewUser(cmd.Context(), loginStr, passStr); err != nil {
+			apperror.Exit("registration failed:", err)
 		}
 
 		logger.Info("User created successfully:", zap.String("login", loginStr))
@@ -78,7 +76,7 @@ var loginUserCmd = &cobra.Command{
 
 		authService := authService.New(fmt.Sprintf(":%s", serverAddr))
 
-		token, err := authService.Login(context.Background(), loginStr, passStr)
+		token, err := authService.Login(cmd.Context(), loginStr, passStr)
 		if err != nil {
 			logger.Error("failed to login:", zap.Error(err))
 
@@ -109,3 +107,29 @@ var loginUserCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// password reset
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Reset forgotten password",
+}
+
+var resetPasswordCmd = &cobra.Command{
+	Use:     "password",
+	Short:   "Set new password with one-time token issued by an administrator",
+	Example: "  goph-keeper-app reset password -l user@example.com -t <token> -p <new password>",
+	Run: func(cmd *cobra.Command, args []string) {
+		loginStr, _ := cmd.Flags().GetString("login")
+		tokenStr, _ := cmd.Flags().GetString("token")
+		passStr, _ := cmd.Flags().GetString("password")
+
+		serverAddr, _ := viper.Get("GRPC_PORT").(string)
+		authService := authService.New(fmt.Sprintf(":%s", serverAddr))
+
+		if err := authService.ResetPassword(cmd.Context(), loginStr, tokenStr, passStr); err != nil {
+			apperror.Exit("password reset failed:", err)
+		}
+
+		logger.Info("Password changed, log in with the new password:", zap.String("login", loginStr))
+	},
+}
@@ -6,8 +6,9 @@ import "time"
 type File struct {
 	ID        string
 	Filename  string
-	Data      []byte
+	Size      int64
 	UpdatedAt time.Time
 	Info      string
 	Etag      string
+	Cached    bool // content of the file is kept in the local cache, evicted files keep only metadata
 }
@@ -0,0 +1,15 @@
+package models
+
+// Folder is a manifest of the uploaded directory, saved as data of folder type.
+// Files of the folder are saved separately under their own ids.
+type Folder struct {
+	Name  string        `json:"name"`
+	Files []FolderEntry `json:"files"`
+}
+
+// FolderEntry is a file of the uploaded directory.
+type FolderEntry struct {
+	ID   string `json:"id"`
+	Path string `json:"path"` // slash separated path relative to the folder
+	Size int64  `json:"size"`
+}
@@ -0,0 +1,63 @@
+package account
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	desc "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const login = "login"
+
+type ClientService struct {
+	client desc.AccountV1Client
+}
+
+func New() *ClientService {
+	return &ClientService{}
+}
+
+// GetUsage returns storage used by the user and limits applied to it.
+func (s *ClientService) GetUsage(ctx context.Context, addr string) (*desc.GetUsageResponse, error) {
+	// Load TLS credentials
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	// Create gRPC connection with TLS
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return nil, fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	s.client = desc.NewAccountV1Client(conn)
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return nil, fmt.Errorf("error loading session: %w", err)
+	}
+
+	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
+
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	resp, err := s.client.GetUsage(ctx, &desc.GetUsageRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting usage: %w", err)
+	}
+
+	return resp, nil
+}
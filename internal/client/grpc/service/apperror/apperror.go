@@ -0,0 +1,184 @@
+package apperror
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Exit codes of the client, so that scripts are able to tell failures apart.
+const (
+	ExitFailure          = 1
+	ExitInvalidArgument  = 2
+	ExitNotFound         = 3
+	ExitPermissionDenied = 4
+	ExitConflict         = 5
+	ExitQuotaExceeded    = 6
+	ExitUnauthenticated  = 7
+	ExitUnavailable      = 8
+)
+
+// Error is an error returned by the server, translated into message for the user of the CLI.
+// It keeps grpc status of the error, so status.Code and status.FromError work with it as before.
+type Error struct {
+	Code   codes.Code
+	Reason string
+	Msg    string
+
+	status *status.Status
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) GRPCStatus() *status.Status {
+	return e.status
+}
+
+// FromStatus translates grpc status error into Error, other errors are returned as is.
+func FromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	res := &Error{Code: st.Code(), Msg: message(st), status: st}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			res.Reason = info.GetReason()
+		}
+	}
+
+	return res
+}
+
+// message returns the message of the status for the user, messages of server failures are replaced,
+// since they mean nothing to the user.
+func message(st *status.Status) string {
+	switch st.Code() {
+	case codes.NotFound:
+		return "not found: " + st.Message()
+	case codes.PermissionDenied:
+		return "access denied: " + st.Message()
+	case codes.InvalidArgument, codes.OutOfRange:
+		return "invalid request: " + st.Message()
+	case codes.ResourceExhausted:
+		return "storage quota exceeded: " + st.Message()
+	case codes.Unauthenticated:
+		return "not logged in or session expired, please log in again"
+	case codes.Unavailable:
+		return "server is not available, please try again later"
+	case codes.DeadlineExceeded:
+		return "server did not respond in time, please try again later"
+	case codes.Internal, codes.Unknown:
+		return "server failed to process the request: " + st.Message()
+	default:
+		return st.Message()
+	}
+}
+
+// ExitCode returns exit code of the client for the error.
+func ExitCode(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.OutOfRange:
+		return ExitInvalidArgument
+	case codes.NotFound:
+		return ExitNotFound
+	case codes.PermissionDenied:
+		return ExitPermissionDenied
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return ExitConflict
+	case codes.ResourceExhausted:
+		return ExitQuotaExceeded
+	case codes.Unauthenticated:
+		return ExitUnauthenticated
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return ExitUnavailable
+	default:
+		return ExitFailure
+	}
+}
+
+// Offline reports whether the server was not reached, so that the client may fall back to its local storage.
+// Errors returned by the server itself are final.
+func Offline(err error) bool {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		return true
+	}
+
+	return appErr.Code == codes.Unavailable || appErr.Code == codes.DeadlineExceeded
+}
+
+// Exit logs the error and terminates the client with exit code of the error.
+func Exit(msg string, err error) {
+	logger.Error(msg, zap.Error(err))
+
+	os.Exit(ExitCode(err))
+}
+
+// UnaryClientOption translates errors of unary RPCs.
+func UnaryClientOption() grpc.DialOption {
+	return grpc.WithChainUnaryInterceptor(func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		return FromStatus(invoker(ctx, method, req, reply, cc, opts...))
+	})
+}
+
+// StreamClientOption translates errors of streaming RPCs.
+func StreamClientOption() grpc.DialOption {
+	return grpc.WithChainStreamInterceptor(func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			return nil, FromStatus(err)
+		}
+
+		return &clientStream{ClientStream: stream}, nil
+	})
+}
+
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (s *clientStream) SendMsg(m any) error {
+	return FromStatus(s.ClientStream.SendMsg(m))
+}
+
+func (s *clientStream) RecvMsg(m any) error {
+	return FromStatus(s.ClientStream.RecvMsg(m))
+}
+
+func (s *clientStream) CloseSend() error {
+	return FromStatus(s.ClientStream.CloseSend())
+}
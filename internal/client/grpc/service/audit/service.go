@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	desc "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	login = "login"
+
+	// pageSize is the number of events requested at once
+	pageSize = 100
+)
+
+type ClientService struct {
+	client desc.AuditV1Client
+}
+
+func New() *ClientService {
+	return &ClientService{}
+}
+
+// Query returns up to limit events of the user matching the request, newest first.
+func (s *ClientService) Query(ctx context.Context, addr string, req *desc.QueryRequest, limit int) ([]*desc.Event, error) {
+	// Load TLS credentials
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	// Create gRPC connection with TLS
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return nil, fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	s.client = desc.NewAuditV1Client(conn)
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return nil, fmt.Errorf("error loading session: %w", err)
+	}
+
+	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
+
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	var events []*desc.Event
+	for len(events) < limit {
+		req.PageSize = uint32(min(pageSize, limit-len(events)))
+
+		resp, err := s.client.Query(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("error querying audit events: %w", err)
+		}
+
+		events = append(events, resp.GetEvents()...)
+
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		req.PageToken = resp.GetNextPageToken()
+	}
+
+	return events, nil
+}
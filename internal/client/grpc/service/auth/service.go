@@ -2,19 +2,25 @@ package register
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
 	desc "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/srp"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/status"
 )
 
+var errServerProof = errors.New("server failed to prove knowledge of the password verifier")
+
 type AuthService struct {
 	addr   string
 	client desc.AuthV1Client
@@ -29,14 +35,15 @@ func New(addr string) *AuthService {
 
 func (auth *AuthService) RegisterNewUser(ctx context.Context, login, pass string) error {
 	// Load TLS credentials
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 		return fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
 	// Create gRPC connection with TLS
-	conn, err := grpc.Dial(auth.addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(auth.addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return fmt.Errorf("error dialing client: %w", err)
 	}
@@ -44,7 +51,13 @@ func (auth *AuthService) RegisterNewUser(ctx context.Context, login, pass string
 
 	auth.client = desc.NewAuthV1Client(conn)
 
-	_, err = auth.client.Register(ctx, &desc.RegisterRequest{Login: login, Password: pass})
+	// the password is not sent, the server keeps only salt and verifier of it
+	salt, verifier, err := srp.NewVerifier(login, pass)
+	if err != nil {
+		return fmt.Errorf("failed to compute verifier: %w", err)
+	}
+
+	_, err = auth.client.Register(ctx, &desc.RegisterRequest{Login: login, Salt: salt, Verifier: verifier})
 	if err != nil {
 		if e, ok := status.FromError(err); ok {
 			if e.Code() == codes.AlreadyExists {
@@ -62,6 +75,9 @@ func (auth *AuthService) RegisterNewUser(ctx context.Context, login, pass string
 	return nil
 }
 
+// Login logs in with SRP-6a, the password is not sent to the server. Users registered before SRP
+// are answered as unknown ones, so if SRP login is rejected, the password is sent once to log in,
+// and the server replaces its hash with verifier.
 func (auth *AuthService) Login(ctx context.Context, login, pass string) (string, error) {
 	var opts []grpc.DialOption
 
@@ -70,7 +86,7 @@ func (auth *AuthService) Login(ctx context.Context, login, pass string) (string,
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
 		// Load TLS credentials
-		creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+		creds, err := tlsconf.Credentials()
 		if err != nil {
 			logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 			return "", fmt.Errorf("failed to load TLS certificates: %w", err)
@@ -78,6 +94,8 @@ func (auth *AuthService) Login(ctx context.Context, login, pass string) (string,
 		opts = append(opts, grpc.WithTransportCredentials(creds))
 	}
 
+	opts = append(opts, tracing.ClientOption(), apperror.UnaryClientOption(), apperror.StreamClientOption())
+
 	// Create gRPC connection
 	conn, err := grpc.Dial(auth.addr, opts...)
 	if err != nil {
@@ -87,10 +105,92 @@ func (auth *AuthService) Login(ctx context.Context, login, pass string) (string,
 
 	auth.client = desc.NewAuthV1Client(conn)
 
-	resp, err := auth.client.Login(ctx, &desc.LoginRequest{Login: login, Password: pass})
+	token, err := auth.loginSRP(ctx, login, pass)
+	if status.Code(err) == codes.Unauthenticated {
+		resp, loginErr := auth.client.Login(ctx, &desc.LoginRequest{Login: login, Password: pass})
+		if loginErr == nil {
+			logger.Info("password migrated to verifier", zap.String("login", login))
+
+			return resp.GetToken(), nil
+		}
+
+		// server not accepting passwords rejects users, which are not migrated, as invalid credentials
+		if status.Code(loginErr) != codes.FailedPrecondition {
+			err = loginErr
+		}
+	}
 	if err != nil {
 		return "", fmt.Errorf("authentication error: %w", err)
 	}
 
-	return resp.GetToken(), nil
+	return token, nil
+}
+
+// loginSRP logs in with SRP-6a, proving knowledge of the password without sending it.
+// The token is returned only if the server proves it knows the verifier of the password.
+func (auth *AuthService) loginSRP(ctx context.Context, login, pass string) (string, error) {
+	client, err := srp.NewClient(login, pass)
+	if err != nil {
+		return "", err
+	}
+
+	start, err := auth.client.LoginStart(ctx, &desc.LoginStartRequest{Login: login, ClientKey: client.PublicKey()})
+	if err != nil {
+		return "", err
+	}
+
+	proof, err := client.Proof(start.GetSalt(), start.GetServerKey())
+	if err != nil {
+		return "", err
+	}
+
+	finish, err := auth.client.LoginFinish(ctx, &desc.LoginFinishRequest{
+		Login:       login,
+		SessionId:   start.GetSessionId(),
+		ClientProof: proof,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if !client.VerifyServer(finish.GetServerProof()) {
+		return "", errServerProof
+	}
+
+	return finish.GetToken(), nil
+}
+
+// ResetPassword sets new password of the user with one-time reset token issued by an administrator.
+func (auth *AuthService) ResetPassword(ctx context.Context, login, resetToken, pass string) error {
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+		return fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	conn, err := grpc.Dial(auth.addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	auth.client = desc.NewAuthV1Client(conn)
+
+	salt, verifier, err := srp.NewVerifier(login, pass)
+	if err != nil {
+		return fmt.Errorf("failed to compute verifier: %w", err)
+	}
+
+	_, err = auth.client.ResetPassword(ctx, &desc.ResetPasswordRequest{
+		Login:      login,
+		ResetToken: resetToken,
+		Salt:       salt,
+		Verifier:   verifier,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reset password: %w", err)
+	}
+
+	return nil
 }
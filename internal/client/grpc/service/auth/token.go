@@ -0,0 +1,91 @@
+package register
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	desc "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// CreateToken creates api token of the logged in user and returns it together with its attributes.
+// The token is shown only once, the server keeps only its hash.
+func (auth *AuthService) CreateToken(ctx context.Context, req *desc.CreateTokenRequest) (*desc.CreateTokenResponse, error) {
+	conn, ctx, err := auth.dialWithSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := auth.client.CreateToken(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token: %w", err)
+	}
+
+	return resp, nil
+}
+
+// ListTokens returns api tokens of the logged in user, which are not revoked.
+func (auth *AuthService) ListTokens(ctx context.Context) ([]*desc.ApiToken, error) {
+	conn, ctx, err := auth.dialWithSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	resp, err := auth.client.ListTokens(ctx, &desc.ListTokensRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	return resp.GetTokens(), nil
+}
+
+// RevokeToken revokes api token with id, requests with the token are rejected afterwards.
+func (auth *AuthService) RevokeToken(ctx context.Context, id string) error {
+	conn, ctx, err := auth.dialWithSession(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = auth.client.RevokeToken(ctx, &desc.RevokeTokenRequest{Id: id})
+	if err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	return nil
+}
+
+// dialWithSession connects to the server and returns context authorized by the saved session.
+func (auth *AuthService) dialWithSession(ctx context.Context) (*grpc.ClientConn, context.Context, error) {
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+		return nil, nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error loading session: %w", err)
+	}
+
+	conn, err := grpc.NewClient(auth.addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error dialing client: %w", err)
+	}
+
+	auth.client = desc.NewAuthV1Client(conn)
+
+	md := metadata.Pairs("login", ss.Login, "authorization", "Bearer "+ss.Token)
+
+	return conn, metadata.NewOutgoingContext(ctx, md), nil
+}
@@ -0,0 +1,173 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/models"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	desc "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DownloadFileDirect downloads binary file straight from object storage through presigned url
+// into 'client_files' directory, so file content does not pass through the server.
+// Like DownloadFile, an interrupted download is resumed from the last received byte with range requests.
+func (s *ClientService) DownloadFileDirect(ctx context.Context, addr string, id, fileName string) (models.File, error) {
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return models.File{}, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return models.File{}, fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	s.client = desc.NewDownloadV1Client(conn)
+	ss, err := session.LoadSession()
+	if err != nil {
+		return models.File{}, fmt.Errorf("error loading session: %w", err)
+	}
+
+	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
+
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	path, file, hasher, offset, err := openPart(id, fileName)
+	if err != nil {
+		return models.File{}, err
+	}
+	defer file.Close()
+
+	var remote remoteFile
+	attempts := 0
+	for {
+		// url is requested on every attempt, since it may expire while retrying
+		resp, err := s.client.GetDownloadURL(ctx, &desc.GetDownloadURLRequest{Uuid: id})
+		if err == nil {
+			remote = remoteFile{
+				size:        resp.GetSize(),
+				info:        resp.GetMetadata(),
+				checksum:    resp.GetSha256(),
+				compression: resp.GetCompression(),
+			}
+
+			if offset > remote.size {
+				// the file on the server is smaller than the local part, start over
+				logger.Warn("local part does not match remote file, restarting download")
+
+				if err := file.Truncate(0); err != nil {
+					return models.File{}, fmt.Errorf("error truncating file: %w", err)
+				}
+				hasher.Reset()
+				offset = 0
+			}
+
+			if offset == remote.size {
+				break
+			}
+
+			var received uint64
+			received, err = fetchRange(ctx, resp.GetUrl(), file, hasher, offset)
+			if received > offset {
+				attempts = 0
+			}
+			offset = received
+
+			if err == nil {
+				if offset == remote.size {
+					break
+				}
+				err = fmt.Errorf("%w: received %d of %d bytes", errInterrupted, offset, remote.size)
+			}
+		}
+
+		attempts++
+		if attempts >= maxDownloadAttempts || !isRetryable(err) {
+			return models.File{}, fmt.Errorf("error downloading file: %w", err)
+		}
+
+		logger.Warn("download interrupted, resuming", zap.Error(err), zap.Uint64("offset", offset))
+		time.Sleep(time.Duration(attempts) * retryDelay)
+	}
+
+	if err := completeDownload(file, hasher, path, remote); err != nil {
+		return models.File{}, err
+	}
+
+	logger.Info("file downloaded", zap.String("path", path), zap.Uint64("size", offset))
+
+	resObj := models.File{
+		ID:       id,
+		Filename: path,
+		Info:     remote.info,
+	}
+
+	return resObj, nil
+}
+
+// fetchRange downloads the object from url starting at offset, appends it to file and hasher
+// and returns offset reached. If storage ignores the range, the file is downloaded from the beginning.
+func fetchRange(ctx context.Context, url string, file *os.File, hasher hash.Hash, offset uint64) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return offset, fmt.Errorf("error creating request: %w", err)
+	}
+
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return offset, fmt.Errorf("error requesting file: %w", err)
+		}
+
+		return offset, fmt.Errorf("%w: error requesting file: %w", errInterrupted, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			if err := file.Truncate(0); err != nil {
+				return offset, fmt.Errorf("error truncating file: %w", err)
+			}
+			hasher.Reset()
+			offset = 0
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return offset, fmt.Errorf("%w: unexpected response status: %s", errInterrupted, resp.Status)
+	default:
+		return offset, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	n, err := io.Copy(io.MultiWriter(file, hasher), resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return offset + uint64(n), fmt.Errorf("error receiving file: %w", err)
+		}
+
+		return offset + uint64(n), fmt.Errorf("%w: error receiving file: %w", errInterrupted, err)
+	}
+
+	return offset + uint64(n), nil
+}
@@ -2,45 +2,79 @@ package download
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/igortoigildin/goph-keeper/internal/client/grpc/models"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
 	desc "github.com/igortoigildin/goph-keeper/pkg/download_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/encryption"
-	fl "github.com/igortoigildin/goph-keeper/pkg/file"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"github.com/spf13/viper"
 
 	"github.com/igortoigildin/goph-keeper/pkg/session"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	login    = "login"
 	password = "password"
+
+	// texts are saved as text_data, folder manifests as folder_data
+	textData   = "text_data"
+	folderData = "folder_data"
+
+	filesDir            = "client_files"
+	maxDownloadAttempts = 5
+	retryDelay          = time.Second
 )
 
+// ErrChecksumMismatch is returned when downloaded file does not match checksum stored on the server.
+var ErrChecksumMismatch = errors.New("downloaded file is corrupted: checksum mismatch")
+
+// errInterrupted is wrapped by errors of downloads, which stopped before the whole file was received, e.g. on network failure.
+var errInterrupted = errors.New("download interrupted")
+
 type ClientService struct {
 	client desc.DownloadV1Client
 }
 
+// remoteFile holds attributes of the downloaded file sent by the server.
+type remoteFile struct {
+	size        uint64
+	info        string
+	checksum    string
+	compression string
+}
+
 func New() *ClientService {
 	return &ClientService{}
 }
 
-func (s *ClientService) DownloadPassword(addr, id string) (models.Credential, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+func (s *ClientService) DownloadPassword(ctx context.Context, addr, id string) (models.Credential, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 		return models.Credential{}, fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return models.Credential{}, fmt.Errorf("error dialing client: %w", err)
 	}
@@ -55,7 +89,7 @@ func (s *ClientService) DownloadPassword(addr, id string) (models.Credential, er
 
 	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	resp, err := s.client.DownloadPassword(ctx, &desc.DownloadPasswordRequest{Uuid: id})
 	if err != nil {
@@ -89,15 +123,26 @@ func (s *ClientService) DownloadPassword(addr, id string) (models.Credential, er
 	return resObj, nil
 }
 
-func (s *ClientService) DownloadText(addr, id string) (models.Text, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+func (s *ClientService) DownloadText(ctx context.Context, addr, id string) (models.Text, error) {
+	return s.downloadText(ctx, addr, id, textData)
+}
+
+// DownloadFolder returns decrypted manifest of the folder, NotFound is returned
+// if there is no folder with the id.
+func (s *ClientService) DownloadFolder(ctx context.Context, addr, id string) (models.Text, error) {
+	return s.downloadText(ctx, addr, id, folderData)
+}
+
+func (s *ClientService) downloadText(ctx context.Context, addr, id, dataType string) (models.Text, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return models.Text{}, fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return models.Text{}, fmt.Errorf("error dialing client: %w", err)
 	}
@@ -111,9 +156,9 @@ func (s *ClientService) DownloadText(addr, id string) (models.Text, error) {
 
 	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
-	resp, err := s.client.DownloadText(ctx, &desc.DownloadTextRequest{Uuid: id})
+	resp, err := s.client.DownloadText(ctx, &desc.DownloadTextRequest{Uuid: id, DataType: dataType})
 	if err != nil {
 		return models.Text{}, fmt.Errorf("error downloading text: %w", err)
 	}
@@ -126,6 +171,13 @@ func (s *ClientService) DownloadText(addr, id string) (models.Text, error) {
 		logger.Error("failed to decrypt text data", zap.Error(err))
 	}
 
+	// text is compressed before encryption, so it is decompressed after decryption
+	text, err := compression.Decompress([]byte(decryptedText), resp.GetCompression())
+	if err != nil {
+		return models.Text{}, fmt.Errorf("error decompressing text: %w", err)
+	}
+	decryptedText = string(text)
+
 	logger.Info("Your data: ", zap.Any("text", decryptedText), zap.Any("metadata", metadata))
 
 	resObj := models.Text{
@@ -137,15 +189,20 @@ func (s *ClientService) DownloadText(addr, id string) (models.Text, error) {
 	return resObj, nil
 }
 
-func (s *ClientService) DownloadFile(addr string, id, fileName string) (models.File, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+// DownloadFile downloads binary file into 'client_files' directory. Data is written to a temporary
+// '.part' file first, so a broken download is resumed from the last received byte,
+// even by the next run of the client. Once the whole file is received and its sha256 checksum
+// matches the one stored on the server, it is renamed to fileName. Corrupted file is removed.
+func (s *ClientService) DownloadFile(ctx context.Context, addr string, id, fileName string) (models.File, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return models.File{}, fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return models.File{}, fmt.Errorf("error dialing client: %w", err)
 	}
@@ -159,67 +216,242 @@ func (s *ClientService) DownloadFile(addr string, id, fileName string) (models.F
 
 	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
-	stream, err := s.client.DownloadFile(ctx, &desc.DownloadFileRequest{Uuid: id})
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	return s.downloadFile(ctx, id, fileName)
+}
+
+// downloadFile downloads the file with connected client, resuming it from the part left by the previous run.
+// Part of the full size is only verified, the server sends attributes of the file without content then.
+func (s *ClientService) downloadFile(ctx context.Context, id, fileName string) (models.File, error) {
+	path, file, hasher, offset, err := openPart(id, fileName)
 	if err != nil {
-		return models.File{}, fmt.Errorf("error downloading file: %w", err)
+		return models.File{}, err
 	}
+	defer file.Close()
 
-	file := fl.NewFile()
-	var fileSize uint32
-	fileSize = 0
-	defer func() {
-		if err := file.OutputFile.Close(); err != nil {
-			logger.Error("error", zap.Error(err))
+	var remote remoteFile
+	attempts := 0
+	for {
+		received, err := s.receiveChunks(ctx, file, hasher, id, offset, &remote)
+		if received > offset {
+			attempts = 0
+			offset = received
 		}
-	}()
 
-	for {
-		req, err := stream.Recv()
-		if file.FilePath == "" {
-			file.SetFile(fileName, "client_files")
+		if err == nil {
+			if offset == remote.size {
+				break
+			}
+			err = fmt.Errorf("%w: received %d of %d bytes", errInterrupted, offset, remote.size)
+		}
+
+		if status.Code(err) == codes.OutOfRange {
+			// the file on the server is smaller than the local part, start over
+			logger.Warn("local part does not match remote file, restarting download", zap.Error(err))
+
+			if err := file.Truncate(0); err != nil {
+				return models.File{}, fmt.Errorf("error truncating file: %w", err)
+			}
+			hasher.Reset()
+			offset = 0
+			err = fmt.Errorf("%w: %w", errInterrupted, err)
+		}
+
+		attempts++
+		if attempts >= maxDownloadAttempts || !isRetryable(err) {
+			return models.File{}, fmt.Errorf("error downloading file: %w", err)
+		}
+
+		logger.Warn("download interrupted, resuming", zap.Error(err), zap.Uint64("offset", offset))
+		time.Sleep(time.Duration(attempts) * retryDelay)
+	}
+
+	if err := completeDownload(file, hasher, path, remote); err != nil {
+		return models.File{}, err
+	}
+
+	logger.Info("file downloaded", zap.String("path", path), zap.Uint64("size", offset))
+
+	resObj := models.File{
+		ID:       id,
+		Filename: path,
+		Info:     remote.info,
+	}
+
+	return resObj, nil
+}
+
+// openPart opens temporary '.part' file the file is downloaded into and returns path of the
+// downloaded file, the part file, hasher with bytes received by the previous run and offset to resume from.
+func openPart(id, fileName string) (string, *os.File, hash.Hash, uint64, error) {
+	if fileName == "" {
+		fileName = id
+	}
+
+	path := LocalPath(fileName)
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return "", nil, nil, 0, fmt.Errorf("error creating directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path+".part", os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return "", nil, nil, 0, fmt.Errorf("error opening file: %w", err)
+	}
+
+	// bytes received by the previous run are hashed before the download is resumed
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, file)
+	if err != nil {
+		file.Close()
+
+		return "", nil, nil, 0, fmt.Errorf("error reading file: %w", err)
+	}
+
+	if n > 0 {
+		logger.Info("resuming download", zap.String("id", id), zap.Int64("offset", n))
+	}
+
+	return path, file, hasher, uint64(n), nil
+}
+
+// LocalPath returns path of the downloaded file inside 'client_files' directory.
+// Directories of relative fileName are preserved, unless they lead outside of 'client_files'.
+func LocalPath(fileName string) string {
+	clean := filepath.Clean(filepath.FromSlash(fileName))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		clean = filepath.Base(clean)
+	}
+
+	return filepath.Join(filesDir, clean)
+}
+
+// completeDownload verifies checksum of the downloaded part file and moves it to path,
+// decompressing it if necessary. Corrupted file is removed.
+func completeDownload(file *os.File, hasher hash.Hash, path string, remote remoteFile) error {
+	partPath := file.Name()
+
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("error flushing file: %w", err)
+	}
+
+	if remote.checksum == "" {
+		logger.Warn("checksum is not stored on the server, file can not be verified", zap.String("path", path))
+	} else if sum := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(sum, remote.checksum) {
+		logger.Error("checksum mismatch", zap.String("expected", remote.checksum), zap.String("actual", sum))
+
+		file.Close()
+		if err := os.Remove(partPath); err != nil {
+			logger.Error("error removing corrupted file", zap.Error(err))
+		}
+
+		return ErrChecksumMismatch
+	}
+
+	if remote.compression == compression.None {
+		if err := os.Rename(partPath, path); err != nil {
+			return fmt.Errorf("error renaming file: %w", err)
 		}
+
+		return nil
+	}
+
+	if err := decompressFile(file, path, remote.compression); err != nil {
+		return err
+	}
+
+	file.Close()
+	if err := os.Remove(partPath); err != nil {
+		logger.Error("error removing compressed file", zap.Error(err))
+	}
+
+	return nil
+}
+
+// receiveChunks downloads the file starting from offset, appends received chunks to file and hasher.
+// It returns offset reached and fills remote with attributes of the file sent in the first message.
+func (s *ClientService) receiveChunks(
+	ctx context.Context,
+	file *os.File,
+	hasher hash.Hash,
+	id string,
+	offset uint64,
+	remote *remoteFile,
+) (uint64, error) {
+	stream, err := s.client.DownloadFile(ctx, &desc.DownloadFileRequest{Uuid: id, Offset: offset})
+	if err != nil {
+		return offset, fmt.Errorf("error downloading file: %w", err)
+	}
+
+	for first := true; ; first = false {
+		resp, err := stream.Recv()
 		if err == io.EOF {
-			break
+			return offset, nil
 		}
 
 		if err != nil {
-			return models.File{}, fmt.Errorf("error receiving byte chunk: %w", err)
+			return offset, fmt.Errorf("error receiving byte chunk: %w", err)
 		}
 
-		chunk := req.GetChunk()
-		fileSize += uint32(len(chunk))
-		logger.Info("received a chunk with size:", zap.Uint32("size", fileSize))
+		if resp.GetOffset() != offset {
+			return offset, fmt.Errorf("unexpected chunk offset %d, expected %d", resp.GetOffset(), offset)
+		}
+
+		remote.size = resp.GetSize()
+		if first {
+			remote.info = resp.GetMetadata()
+			remote.checksum = resp.GetSha256()
+			remote.compression = resp.GetCompression()
+		}
 
-		if err := file.Write(chunk); err != nil {
-			return models.File{}, fmt.Errorf("error adding byte chunk to file: %w", err)
+		chunk := resp.GetChunk()
+		if _, err := file.Write(chunk); err != nil {
+			return offset, fmt.Errorf("error adding byte chunk to file: %w", err)
 		}
+		hasher.Write(chunk)
+
+		offset += uint64(len(chunk))
+		logger.Debug("received a chunk", zap.Uint64("offset", offset), zap.Uint64("size", remote.size))
+	}
+}
+
+// decompressFile decompresses downloaded file with algo into the file at path.
+func decompressFile(file *os.File, path string, algo string) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error seeking file: %w", err)
 	}
 
-	fileData, err := os.ReadFile(file.FilePath)
+	reader, err := compression.NewReader(file, algo)
 	if err != nil {
-		logger.Error("error while reading file", zap.Error(err))
-		return models.File{}, fmt.Errorf("error while reading file: %w", err)
+		return fmt.Errorf("error decompressing file: %w", err)
 	}
+	defer reader.Close()
 
-	resObj := models.File{
-		ID:       id,
-		Data:     fileData,
-		Filename: file.FilePath,
+	dst, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating file: %w", err)
 	}
+	defer dst.Close()
 
-	return resObj, nil
+	if _, err := io.Copy(dst, reader); err != nil {
+		return fmt.Errorf("error decompressing file: %w", err)
+	}
+
+	return dst.Sync()
 }
 
-func (s *ClientService) DownloadBankDetails(addr, id string) (models.BankDetails, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+func (s *ClientService) DownloadBankDetails(ctx context.Context, addr, id string) (models.BankDetails, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return models.BankDetails{}, fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return models.BankDetails{}, fmt.Errorf("error dialing client: %w", err)
 	}
@@ -232,7 +464,7 @@ func (s *ClientService) DownloadBankDetails(addr, id string) (models.BankDetails
 	}
 
 	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	resp, err := s.client.DownloadBankData(ctx, &desc.DownloadBankDataRequest{Uuid: id})
 	if err != nil {
@@ -273,3 +505,18 @@ func (s *ClientService) DownloadBankDetails(addr, id string) (models.BankDetails
 
 	return resObj, nil
 }
+
+// isRetryable reports whether download may be resumed after err.
+// Only interrupted downloads and transient errors of the server are retried.
+func isRetryable(err error) bool {
+	if errors.Is(err, errInterrupted) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,211 @@
+package download
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	desc "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("error")
+
+	os.Exit(m.Run())
+}
+
+func TestDownloadFile_FullPartIsVerified(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	data := randomBytes(t, 3*1024)
+	server := newFakeServer(data)
+	writePart(t, "file.bin", data)
+
+	res, err := (&ClientService{client: server}).downloadFile(context.Background(), "id", "file.bin")
+	require.NoError(t, err)
+	assert.Equal(t, LocalPath("file.bin"), res.Filename)
+
+	// the part is verified without downloading it again or retrying
+	assert.Equal(t, []uint64{uint64(len(data))}, server.offsets)
+	assertDownloaded(t, "file.bin", data)
+}
+
+func TestDownloadFile_ResumesPart(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	data := randomBytes(t, 3*1024)
+	server := newFakeServer(data)
+	writePart(t, "file.bin", data[:1000])
+
+	_, err := (&ClientService{client: server}).downloadFile(context.Background(), "id", "file.bin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{1000}, server.offsets)
+	assertDownloaded(t, "file.bin", data)
+}
+
+func TestDownloadFile_CorruptedFullPart(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	data := randomBytes(t, 3*1024)
+	server := newFakeServer(data)
+
+	corrupted := append([]byte(nil), data...)
+	corrupted[0] ^= 0xff
+	writePart(t, "file.bin", corrupted)
+
+	_, err := (&ClientService{client: server}).downloadFile(context.Background(), "id", "file.bin")
+	require.ErrorIs(t, err, ErrChecksumMismatch)
+	assert.Equal(t, []uint64{uint64(len(data))}, server.offsets)
+
+	// corrupted part is removed, so the next run starts over
+	for _, path := range []string{LocalPath("file.bin"), LocalPath("file.bin") + ".part"} {
+		_, err = os.Stat(path)
+		assert.ErrorIs(t, err, fs.ErrNotExist)
+	}
+}
+
+func TestDownloadFile_PartLargerThanRemoteFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	data := randomBytes(t, 1024)
+	server := newFakeServer(data)
+	writePart(t, "file.bin", randomBytes(t, 2048))
+
+	_, err := (&ClientService{client: server}).downloadFile(context.Background(), "id", "file.bin")
+	require.NoError(t, err)
+
+	assert.Equal(t, []uint64{2048, 0}, server.offsets)
+	assertDownloaded(t, "file.bin", data)
+}
+
+func TestDownloadFile_PermanentErrorIsNotRetried(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	server := newFakeServer(randomBytes(t, 1024))
+	server.err = status.Error(codes.Internal, "internal error")
+
+	_, err := (&ClientService{client: server}).downloadFile(context.Background(), "id", "file.bin")
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, []uint64{0}, server.offsets)
+}
+
+func TestLocalPath(t *testing.T) {
+	for fileName, want := range map[string]string{
+		"file.bin":           filepath.Join(filesDir, "file.bin"),
+		"photos/2024/a.jpg":  filepath.Join(filesDir, "photos", "2024", "a.jpg"),
+		"photos/../a.jpg":    filepath.Join(filesDir, "a.jpg"),
+		"../../etc/passwd":   filepath.Join(filesDir, "passwd"),
+		"/etc/passwd":        filepath.Join(filesDir, "passwd"),
+		"photos/../../a.jpg": filepath.Join(filesDir, "a.jpg"),
+	} {
+		assert.Equal(t, want, LocalPath(fileName), fileName)
+	}
+}
+
+// fakeServer serves data in chunks, as the download service does, and records requested offsets.
+type fakeServer struct {
+	desc.DownloadV1Client
+
+	data    []byte
+	offsets []uint64
+	err     error // returned by every stream instead of data
+}
+
+func newFakeServer(data []byte) *fakeServer {
+	return &fakeServer{data: data}
+}
+
+func (f *fakeServer) DownloadFile(
+	_ context.Context, req *desc.DownloadFileRequest, _ ...grpc.CallOption,
+) (grpc.ServerStreamingClient[desc.DownloadFileResponse], error) {
+	f.offsets = append(f.offsets, req.GetOffset())
+
+	if f.err != nil {
+		return &fakeStream{err: f.err}, nil
+	}
+
+	if req.GetOffset() > uint64(len(f.data)) {
+		return &fakeStream{err: status.Error(codes.OutOfRange, "requested range is not satisfiable")}, nil
+	}
+
+	sum := sha256.Sum256(f.data)
+
+	// the first message is sent even for empty range
+	var msgs []*desc.DownloadFileResponse
+	for offset := req.GetOffset(); offset < uint64(len(f.data)) || len(msgs) == 0; offset += 1024 {
+		end := min(offset+1024, uint64(len(f.data)))
+		msgs = append(msgs, &desc.DownloadFileResponse{
+			Uuid:   req.GetUuid(),
+			Chunk:  f.data[offset:end],
+			Offset: offset,
+			Size:   uint64(len(f.data)),
+		})
+	}
+	msgs[0].Sha256 = hex.EncodeToString(sum[:])
+
+	return &fakeStream{msgs: msgs}, nil
+}
+
+type fakeStream struct {
+	grpc.ClientStream
+
+	msgs []*desc.DownloadFileResponse
+	err  error
+}
+
+func (s *fakeStream) Recv() (*desc.DownloadFileResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if len(s.msgs) == 0 {
+		return nil, io.EOF
+	}
+
+	msg := s.msgs[0]
+	s.msgs = s.msgs[1:]
+
+	return msg, nil
+}
+
+func writePart(t *testing.T, fileName string, data []byte) {
+	t.Helper()
+
+	path := LocalPath(fileName) + ".part"
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+}
+
+func assertDownloaded(t *testing.T, fileName string, data []byte) {
+	t.Helper()
+
+	got, err := os.ReadFile(LocalPath(fileName))
+	require.NoError(t, err)
+	assert.Equal(t, data, got)
+
+	_, err = os.Stat(LocalPath(fileName) + ".part")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/igortoigildin/goph-keeper/pkg/session"
 	desc "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
 )
 
@@ -34,9 +36,9 @@ func New() *ClientService {
 	return &ClientService{}
 }
 
-func (s *ClientService) ListAllData(addr string) ([]*desc.ObjectInfo, error) {
+func (s *ClientService) ListAllData(ctx context.Context, addr string) ([]*desc.ObjectInfo, error) {
 	// Load TLS credentials
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
@@ -44,7 +46,8 @@ func (s *ClientService) ListAllData(addr string) ([]*desc.ObjectInfo, error) {
 	}
 
 	// Create gRPC connection with TLS
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return nil, fmt.Errorf("error dialing client: %w", err)
 	}
@@ -59,7 +62,7 @@ func (s *ClientService) ListAllData(addr string) ([]*desc.ObjectInfo, error) {
 
 	md := metadata.Pairs(login, ss.Login, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	resp, err := s.client.GetObjectList(ctx, &desc.SyncRequest{Login: ss.Login})
 	if err != nil {
@@ -0,0 +1,44 @@
+// Package tlsconf builds TLS credentials client services connect to the server with.
+package tlsconf
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/spf13/viper"
+	"google.golang.org/grpc/credentials"
+)
+
+// Credentials returns TLS credentials trusting the server certificate from TLS_CA_FILE.
+// If TLS_CLIENT_CERT_FILE and TLS_CLIENT_KEY_FILE are set, the client certificate is presented
+// to the server, which authenticates the user it is bound to.
+func Credentials() (credentials.TransportCredentials, error) {
+	caFile := viper.GetString("TLS_CA_FILE")
+	certFile, keyFile := viper.GetString("TLS_CLIENT_CERT_FILE"), viper.GetString("TLS_CLIENT_KEY_FILE")
+
+	if certFile == "" && keyFile == "" {
+		return credentials.NewClientTLSFromFile(caFile, "")
+	}
+
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read server certificate: %w", err)
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		RootCAs:      rootCAs,
+		Certificates: []tls.Certificate{cert},
+	}), nil
+}
@@ -0,0 +1,106 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// BatchFile is a file uploaded as a part of the batch.
+type BatchFile struct {
+	ID   string
+	Path string // path of the file on disk
+	Info string
+}
+
+// BatchResult is the outcome of uploading a single file of the batch.
+type BatchResult struct {
+	BatchFile
+	Etag string
+	Err  error
+}
+
+// SendFiles uploads files concurrently by at most workers goroutines sharing a single connection.
+// A failed upload is reported in its result and does not abort the batch. If done is not nil,
+// it is called by the workers after every file, so it must be safe for concurrent use.
+// Results are returned in the order of files.
+func (s *ClientService) SendFiles(ctx context.Context, addr string, files []BatchFile, batchSize, workers int, done func(BatchResult)) ([]BatchResult, error) {
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return nil, fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return nil, fmt.Errorf("error loading session: %w", err)
+	}
+
+	client := desc.NewUploadV1Client(conn)
+
+	send := func(file BatchFile) (string, error) {
+		// every upload has its own service, since the service keeps the client it uses
+		worker := &ClientService{client: client}
+
+		md := metadata.Pairs(login, ss.Login, "id", file.ID, "authorization", "Bearer "+ss.Token)
+		ctx := metadata.NewOutgoingContext(ctx, md)
+
+		return worker.sendFile(ctx, file.Path, batchSize, file.Info)
+	}
+
+	return runBatch(files, workers, send, done), nil
+}
+
+// runBatch sends files by at most workers goroutines and returns results in the order of files.
+func runBatch(files []BatchFile, workers int, send func(BatchFile) (string, error), done func(BatchResult)) []BatchResult {
+	results := make([]BatchResult, len(files))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range jobs {
+				file := files[i]
+
+				etag, err := send(file)
+				if err != nil {
+					logger.Debug("failed to upload file", zap.String("path", file.Path), zap.Error(err))
+				}
+
+				results[i] = BatchResult{BatchFile: file, Etag: etag, Err: err}
+				if done != nil {
+					done(results[i])
+				}
+			}
+		}()
+	}
+
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,105 @@
+package upload
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("error")
+
+	os.Exit(m.Run())
+}
+
+func TestRunBatch_BoundsWorkers(t *testing.T) {
+	const workers = 3
+
+	var running, peak atomic.Int32
+	send := func(file BatchFile) (string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		return "etag-" + file.ID, nil
+	}
+
+	results := runBatch(batchFiles(20), workers, send, nil)
+	require.Len(t, results, 20)
+
+	assert.Equal(t, int32(workers), peak.Load())
+}
+
+func TestRunBatch_FailuresDoNotAbortBatch(t *testing.T) {
+	errUpload := errors.New("upload failed")
+
+	send := func(file BatchFile) (string, error) {
+		if file.ID == "file-1" || file.ID == "file-3" {
+			return "", errUpload
+		}
+
+		return "etag-" + file.ID, nil
+	}
+
+	var (
+		mu   sync.Mutex
+		done []string
+	)
+	files := batchFiles(5)
+	results := runBatch(files, 2, send, func(res BatchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		done = append(done, res.ID)
+	})
+
+	// results are in the order of files, whichever finished first
+	require.Len(t, results, len(files))
+	for i, res := range results {
+		assert.Equal(t, files[i], res.BatchFile)
+
+		if res.ID == "file-1" || res.ID == "file-3" {
+			assert.ErrorIs(t, res.Err, errUpload)
+			assert.Empty(t, res.Etag)
+		} else {
+			assert.NoError(t, res.Err)
+			assert.Equal(t, "etag-"+res.ID, res.Etag)
+		}
+	}
+
+	assert.ElementsMatch(t, []string{"file-0", "file-1", "file-2", "file-3", "file-4"}, done)
+}
+
+func TestRunBatch_AtLeastOneWorker(t *testing.T) {
+	results := runBatch(batchFiles(2), 0, func(file BatchFile) (string, error) {
+		return file.ID, nil
+	}, nil)
+
+	require.Len(t, results, 2)
+	assert.Equal(t, "file-1", results[1].Etag)
+}
+
+func batchFiles(n int) []BatchFile {
+	files := make([]BatchFile, n)
+	for i := range files {
+		id := fmt.Sprintf("file-%d", i)
+		files[i] = BatchFile{ID: id, Path: "/tmp/" + id, Info: "dir/" + id}
+	}
+
+	return files
+}
@@ -0,0 +1,55 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// DeleteFile deletes binary file with provided id from the server.
+func (s *ClientService) DeleteFile(ctx context.Context, addr string, id string) error {
+	// Load TLS credentials
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	// Create gRPC connection with TLS
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	s.client = desc.NewUploadV1Client(conn)
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return fmt.Errorf("error loading session: %w", err)
+	}
+
+	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
+
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	_, err = s.client.DeleteFile(ctx, &desc.DeleteFileRequest{})
+	if err != nil {
+		logger.Error("failed to delete file: ", zap.Error(err))
+
+		return fmt.Errorf("error deleting file: %w", err)
+	}
+
+	return nil
+}
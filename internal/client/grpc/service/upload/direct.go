@@ -0,0 +1,161 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// SendFileDirect uploads the file straight to object storage through presigned url,
+// so file content does not pass through the server. Object storage does not accept
+// partial uploads through presigned url, so failed upload is retried from the beginning.
+func (s *ClientService) SendFileDirect(ctx context.Context, addr string, filePath string, id, info string) (string, error) {
+	creds, err := tlsconf.Credentials()
+	if err != nil {
+		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
+
+		return "", fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
+	if err != nil {
+		return "", fmt.Errorf("error dialing client: %w", err)
+	}
+	defer conn.Close()
+
+	s.client = desc.NewUploadV1Client(conn)
+
+	ss, err := session.LoadSession()
+	if err != nil {
+		return "", fmt.Errorf("error loading session: %w", err)
+	}
+
+	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	uploadPath, algo, err := compressFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if uploadPath != filePath {
+		defer os.Remove(uploadPath)
+	}
+
+	file, err := os.Open(uploadPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening file: %w", err)
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("error getting file info: %w", err)
+	}
+
+	hasher := sha256.New()
+	for attempts := 1; ; attempts++ {
+		err = s.putFile(ctx, file, stat.Size(), hasher)
+		if err == nil {
+			break
+		}
+
+		if attempts >= maxUploadAttempts || !isRetryable(err) {
+			return "", err
+		}
+
+		logger.Warn("upload interrupted, retrying", zap.Error(err), zap.Int("attempt", attempts))
+		time.Sleep(time.Duration(attempts) * retryDelay)
+	}
+
+	resp, err := s.client.CompleteUpload(ctx, &desc.CompleteUploadRequest{
+		FileName:    filepath.Base(filePath),
+		Metadata:    info,
+		Sha256:      hex.EncodeToString(hasher.Sum(nil)),
+		Compression: algo,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error completing upload: %w", err)
+	}
+
+	return resp.GetEtag(), nil
+}
+
+// putFile requests presigned url and posts the whole file to it, adding uploaded bytes to hasher.
+func (s *ClientService) putFile(ctx context.Context, file *os.File, size int64, hasher hash.Hash) error {
+	resp, err := s.client.GetUploadURL(ctx, &desc.GetUploadURLRequest{})
+	if err != nil {
+		return fmt.Errorf("error getting upload url: %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error seeking file: %w", err)
+	}
+	hasher.Reset()
+
+	// Fields of the policy precede the file, which must be the last field of the form.
+	// The form is assembled around the file, so its length is known and it is not sent chunked.
+	var head bytes.Buffer
+	form := multipart.NewWriter(&head)
+	for k, v := range resp.GetFormData() {
+		if err := form.WriteField(k, v); err != nil {
+			return fmt.Errorf("error writing form field: %w", err)
+		}
+	}
+	if _, err := form.CreateFormFile("file", filepath.Base(file.Name())); err != nil {
+		return fmt.Errorf("error writing form file: %w", err)
+	}
+	n := head.Len()
+
+	if err := form.Close(); err != nil {
+		return fmt.Errorf("error closing form: %w", err)
+	}
+	tail := bytes.NewReader(head.Bytes()[n:])
+
+	body := io.MultiReader(bytes.NewReader(head.Bytes()[:n]), io.TeeReader(file, hasher), tail)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, resp.GetUrl(), body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.ContentLength = int64(head.Len()) + size
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("error uploading file: %w", err)
+		}
+
+		return fmt.Errorf("%w: error uploading file: %w", errInterrupted, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("%w: unexpected response status: %s", errInterrupted, res.Status)
+	case res.StatusCode != http.StatusNoContent:
+		return fmt.Errorf("error uploading file: unexpected response status: %s", res.Status)
+	}
+
+	return nil
+}
@@ -2,30 +2,44 @@ package upload
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/apperror"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/service/tlsconf"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/igortoigildin/goph-keeper/pkg/session"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 const (
 	login    = "login"
 	password = "password"
+
+	// texts are saved as text_data, folder manifests as folder_data
+	textData   = "text_data"
+	folderData = "folder_data"
+
+	maxUploadAttempts = 5
+	retryDelay        = time.Second
 )
 
-type Sender interface {
-	SendPassword(addr, loginStr, passStr string, id string, meta string) error
-	SendText(addr, text string, id string, meta string) error
-	SendFile(addr string, filePath string, batchSize int, id, meta string) error
-	SendBankDetails(addr, cardNumber, cvc, expDate string, id, meta string) error
-}
+// errInterrupted is wrapped by errors of uploads, which stopped before the whole file was stored, e.g. on network failure.
+var errInterrupted = errors.New("upload interrupted")
 
 type ClientService struct {
 	client desc.UploadV1Client
@@ -35,9 +49,9 @@ func New() *ClientService {
 	return &ClientService{}
 }
 
-func (s *ClientService) SendPassword(addr, loginStr, passStr string, id string, meta string) (string, error) {
+func (s *ClientService) SendPassword(ctx context.Context, addr, loginStr, passStr string, id string, meta string) (string, error) {
 	// Load TLS credentials
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
@@ -45,7 +59,8 @@ func (s *ClientService) SendPassword(addr, loginStr, passStr string, id string,
 	}
 
 	// Create gRPC connection with TLS
-	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return "", fmt.Errorf("error dialing client: %w", err)
 	}
@@ -60,7 +75,7 @@ func (s *ClientService) SendPassword(addr, loginStr, passStr string, id string,
 
 	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	etag, err := s.uploadPassword(ctx, loginStr, passStr, meta)
 	if err != nil {
@@ -84,15 +99,16 @@ func (s *ClientService) uploadPassword(ctx context.Context, loginStr, passStr, m
 	return resp.Etag, nil
 }
 
-func (s *ClientService) SendBankDetails(addr, cardNumber, cvc, expDate string, id, meta string) (string, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+func (s *ClientService) SendBankDetails(ctx context.Context, addr, cardNumber, cvc, expDate string, id, meta string) (string, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return "", fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return "", fmt.Errorf("error dialing client: %w", err)
 	}
@@ -107,7 +123,7 @@ func (s *ClientService) SendBankDetails(addr, cardNumber, cvc, expDate string, i
 
 	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
 	etag, err := s.uploadBankDetails(ctx, cardNumber, cvc, expDate, meta)
 	if err != nil {
@@ -132,15 +148,27 @@ func (s *ClientService) uploadBankDetails(ctx context.Context, cardNumber, cvc,
 	return resp.Etag, nil
 }
 
-func (s *ClientService) SendText(addr, text string, id string, info string) (string, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+// SendText uploads encrypted text. If the text was compressed before encryption,
+// algo is sent along, so the server records it for clients downloading the text.
+func (s *ClientService) SendText(ctx context.Context, addr, text string, id string, info string, algo string) (string, error) {
+	return s.sendText(ctx, addr, text, id, info, textData, algo)
+}
+
+// SendFolder uploads encrypted manifest of the folder, which is saved as text of its own data type.
+func (s *ClientService) SendFolder(ctx context.Context, addr, manifest string, id string, info string, algo string) (string, error) {
+	return s.sendText(ctx, addr, manifest, id, info, folderData, algo)
+}
+
+func (s *ClientService) sendText(ctx context.Context, addr, text, id, info, dataType, algo string) (string, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return "", fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return "", fmt.Errorf("error dialing client: %w", err)
 	}
@@ -155,9 +183,9 @@ func (s *ClientService) SendText(addr, text string, id string, info string) (str
 
 	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
 
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
 
-	etag, err := s.uploadText(ctx, text, info)
+	etag, err := s.uploadText(ctx, text, info, dataType, algo)
 	if err != nil {
 		return "", err
 	}
@@ -165,8 +193,8 @@ func (s *ClientService) SendText(addr, text string, id string, info string) (str
 	return etag, nil
 }
 
-func (s *ClientService) uploadText(ctx context.Context, text, info string) (string, error) {
-	resp, err := s.client.UploadText(ctx, &desc.UploadTextRequest{Text: text, Metadata: info})
+func (s *ClientService) uploadText(ctx context.Context, text, info, dataType, algo string) (string, error) {
+	resp, err := s.client.UploadText(ctx, &desc.UploadTextRequest{Text: text, Metadata: info, DataType: dataType, Compression: algo})
 	if err != nil {
 		return "", fmt.Errorf("error uploading text: %w", err)
 	}
@@ -174,15 +202,18 @@ func (s *ClientService) uploadText(ctx context.Context, text, info string) (stri
 	return resp.Etag, nil
 }
 
-func (s *ClientService) SendFile(addr string, filePath string, batchSize int, id, info string) (string, error) {
-	creds, err := credentials.NewClientTLSFromFile("certs/server.crt", "")
+// SendFile uploads the file. Files not smaller than compression.MinSize are compressed
+// with zstd into a temporary file first, unless compression does not reduce their size.
+func (s *ClientService) SendFile(ctx context.Context, addr string, filePath string, batchSize int, id, info string) (string, error) {
+	creds, err := tlsconf.Credentials()
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return "", fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
-	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds), tracing.ClientOption(),
+		apperror.UnaryClientOption(), apperror.StreamClientOption())
 	if err != nil {
 		return "", fmt.Errorf("error dialing client: %w", err)
 	}
@@ -196,9 +227,22 @@ func (s *ClientService) SendFile(addr string, filePath string, batchSize int, id
 	}
 
 	md := metadata.Pairs(login, ss.Login, "id", id, "authorization", "Bearer "+ss.Token)
-	ctx := metadata.NewOutgoingContext(context.Background(), md)
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	return s.sendFile(ctx, filePath, batchSize, info)
+}
 
-	etag, err := s.uploadFile(ctx, filePath, batchSize, info)
+// sendFile compresses the file if worthwhile and uploads it with id from outgoing metadata.
+func (s *ClientService) sendFile(ctx context.Context, filePath string, batchSize int, info string) (string, error) {
+	uploadPath, algo, err := compressFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	if uploadPath != filePath {
+		defer os.Remove(uploadPath)
+	}
+
+	etag, err := s.uploadFile(ctx, uploadPath, filepath.Base(filePath), algo, batchSize, info)
 	if err != nil {
 		return "", err
 	}
@@ -206,38 +250,211 @@ func (s *ClientService) SendFile(addr string, filePath string, batchSize int, id
 	return etag, nil
 }
 
-func (s *ClientService) uploadFile(ctx context.Context, filepath string, batchSize int, info string) (string, error) {
-	stream, err := s.client.UploadFile(ctx)
+// uploadFile uploads the file via resumable upload session. If the stream breaks,
+// the committed offset is requested from the server and the upload continues from it.
+func (s *ClientService) uploadFile(ctx context.Context, filePath, fileName, algo string, batchSize int, info string) (string, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return "", fmt.Errorf("error uploading file: %w", err)
+		return "", fmt.Errorf("error opening file: %w", err)
 	}
+	defer file.Close()
 
-	file, err := os.Open(filepath)
+	stat, err := file.Stat()
 	if err != nil {
-		return "", fmt.Errorf("error opening file: %w", err)
+		return "", fmt.Errorf("error getting file info: %w", err)
+	}
+	size := uint64(stat.Size())
+
+	initResp, err := s.client.InitUpload(ctx, &desc.InitUploadRequest{
+		FileName:    fileName,
+		Size:        size,
+		Metadata:    info,
+		Compression: algo,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error starting upload: %w", err)
+	}
+
+	uploadID := initResp.GetUploadId()
+	offset := initResp.GetCommittedOffset()
+
+	hasher := sha256.New()
+	var hashed uint64
+
+	attempts := 0
+	for offset < size {
+		committed, err := s.sendChunks(ctx, file, uploadID, offset, batchSize, hasher, &hashed)
+		if err == nil {
+			if committed == size {
+				break
+			}
+			err = fmt.Errorf("%w: server committed %d of %d bytes", errInterrupted, committed, size)
+		}
+
+		if committed > offset {
+			attempts = 0
+			offset = committed
+		}
+
+		attempts++
+		if attempts >= maxUploadAttempts || !isRetryable(err) {
+			return "", err
+		}
+
+		logger.Warn("upload interrupted, resuming", zap.Error(err), zap.Int("attempt", attempts))
+		time.Sleep(time.Duration(attempts) * retryDelay)
+
+		resp, err := s.client.GetUploadStatus(ctx, &desc.GetUploadStatusRequest{UploadId: uploadID})
+		if err != nil {
+			logger.Warn("failed to get upload status", zap.Error(err))
+
+			continue
+		}
+
+		offset = resp.GetCommittedOffset()
+		logger.Info("resuming upload", zap.Uint64("offset", offset), zap.Uint64("size", size))
+	}
+
+	resp, err := s.client.FinalizeUpload(ctx, &desc.FinalizeUploadRequest{
+		UploadId: uploadID,
+		Size:     size,
+		Sha256:   hex.EncodeToString(hasher.Sum(nil)),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error finalizing upload: %w", err)
+	}
+
+	return resp.GetEtag(), nil
+}
+
+// sendChunks streams the file starting from offset and returns offset committed by the server.
+// Every byte of the file is added to hasher only once, even if it is sent again after resume.
+func (s *ClientService) sendChunks(
+	ctx context.Context,
+	file *os.File,
+	uploadID string,
+	offset uint64,
+	batchSize int,
+	hasher hash.Hash,
+	hashed *uint64,
+) (uint64, error) {
+	if offset > *hashed {
+		return 0, fmt.Errorf("can not resume from offset %d, only %d bytes were read", offset, *hashed)
+	}
+
+	if _, err := file.Seek(int64(offset), io.SeekStart); err != nil {
+		return 0, fmt.Errorf("error seeking file: %w", err)
 	}
+
+	stream, err := s.client.UploadChunk(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error uploading file: %w", err)
+	}
+
 	buf := make([]byte, batchSize)
-	batchNumber := 1
 	for {
 		num, err := file.Read(buf)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
-			return "", fmt.Errorf("error reading buf: %w", err)
+			return 0, fmt.Errorf("error reading buf: %w", err)
 		}
 		chunk := buf[:num]
 
-		if err := stream.Send(&desc.UploadFileRequest{FileName: filepath, Chunk: chunk, Metadata: info}); err != nil {
-			return "", fmt.Errorf("error uploading bytes: %w", err)
+		if end := offset + uint64(num); end > *hashed {
+			hasher.Write(chunk[*hashed-offset:])
+			*hashed = end
 		}
 
-		batchNumber += 1
+		if err := stream.Send(&desc.UploadChunkRequest{UploadId: uploadID, Offset: offset, Chunk: chunk}); err != nil {
+			// the actual error is returned by CloseAndRecv
+			if err == io.EOF {
+				_, err = stream.CloseAndRecv()
+			}
+
+			return 0, fmt.Errorf("error uploading bytes: %w", err)
+		}
+
+		offset += uint64(num)
 	}
+
 	resp, err := stream.CloseAndRecv()
 	if err != nil {
-		return "", fmt.Errorf("error closing stream: %w", err)
+		return 0, fmt.Errorf("error closing stream: %w", err)
 	}
 
-	return resp.Etag, nil
+	return resp.GetCommittedOffset(), nil
+}
+
+// compressFile compresses the file with zstd into a temporary file and returns its path,
+// so the upload can be resumed from any offset. The original path is returned with compression.None
+// if the file is smaller than compression.MinSize or compression does not reduce its size.
+func compressFile(filePath string) (string, string, error) {
+	src, err := os.Open(filePath)
+	if err != nil {
+		return "", "", fmt.Errorf("error opening file: %w", err)
+	}
+	defer src.Close()
+
+	stat, err := src.Stat()
+	if err != nil {
+		return "", "", fmt.Errorf("error getting file info: %w", err)
+	}
+
+	if stat.Size() < compression.MinSize {
+		return filePath, compression.None, nil
+	}
+
+	tmp, err := os.CreateTemp("", "goph-keeper-*.zst")
+	if err != nil {
+		return "", "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer tmp.Close()
+
+	writer, err := compression.NewWriter(tmp)
+	if err != nil {
+		os.Remove(tmp.Name())
+
+		return "", "", err
+	}
+
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		os.Remove(tmp.Name())
+
+		return "", "", fmt.Errorf("error compressing file: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		os.Remove(tmp.Name())
+
+		return "", "", fmt.Errorf("error compressing file: %w", err)
+	}
+
+	compressed, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil || compressed >= stat.Size() {
+		os.Remove(tmp.Name())
+
+		return filePath, compression.None, nil
+	}
+
+	logger.Debug("file compressed", zap.Int64("size", stat.Size()), zap.Int64("compressed", compressed))
+
+	return tmp.Name(), compression.Zstd, nil
+}
+
+// isRetryable reports whether upload may be resumed after err.
+// Only interrupted uploads and transient failures of the server are retried, other errors would fail again.
+func isRetryable(err error) bool {
+	if errors.Is(err, errInterrupted) {
+		return true
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+		return true
+	default:
+		return false
+	}
 }
@@ -0,0 +1,181 @@
+package chunks
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/igortoigildin/goph-keeper/pkg/encryption"
+)
+
+// ChunkSize is the size of plain content stored in a single chunk.
+const ChunkSize = 1024 * 1024
+
+var ErrCorrupted = errors.New("cached chunk is corrupted")
+
+// Store keeps cached files on disk as chunks encrypted with AES-GCM, one directory per file,
+// so files are written and read in a streaming way with memory bounded by ChunkSize.
+type Store struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+// New creates store in dir, key is the base64 encoded encryption key.
+func New(dir string, key []byte) (*Store, error) {
+	aead, err := encryption.NewAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating cache directory: %w", err)
+	}
+
+	return &Store{dir: dir, aead: aead}, nil
+}
+
+// Write stores content read from r as chunks of the file with id, replacing chunks stored before.
+// Returns size of the content and number of chunks written.
+func (s *Store) Write(id string, r io.Reader) (int64, int, error) {
+	// chunks are written aside and swapped in once complete, so a failed write keeps the previous content
+	tmp, err := os.MkdirTemp(s.dir, "."+id+"-")
+	if err != nil {
+		return 0, 0, fmt.Errorf("error creating chunk directory: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	buf := make([]byte, ChunkSize)
+	var (
+		size  int64
+		count int
+	)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			if err := s.writeChunk(tmp, id, count, buf[:n]); err != nil {
+				return 0, 0, err
+			}
+			size += int64(n)
+			count++
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("error reading file: %w", err)
+		}
+	}
+
+	if err := s.Remove(id); err != nil {
+		return 0, 0, err
+	}
+
+	if err := os.Rename(tmp, s.path(id)); err != nil {
+		return 0, 0, fmt.Errorf("error saving chunks: %w", err)
+	}
+
+	return size, count, nil
+}
+
+// Open returns reader of the content stored in count chunks of the file with id.
+func (s *Store) Open(id string, count int) io.ReadCloser {
+	return &reader{store: s, id: id, count: count}
+}
+
+// Remove removes all chunks of the file with id.
+func (s *Store) Remove(id string) error {
+	if err := os.RemoveAll(s.path(id)); err != nil {
+		return fmt.Errorf("error removing chunks: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) writeChunk(dir, id string, idx int, data []byte) error {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	sealed := s.aead.Seal(nonce, nonce, data, additionalData(id, idx))
+
+	if err := os.WriteFile(filepath.Join(dir, strconv.Itoa(idx)), sealed, 0o600); err != nil {
+		return fmt.Errorf("error writing chunk: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) readChunk(id string, idx int) ([]byte, error) {
+	sealed, err := os.ReadFile(filepath.Join(s.path(id), strconv.Itoa(idx)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading chunk: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return nil, ErrCorrupted
+	}
+
+	data, err := s.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData(id, idx))
+	if err != nil {
+		return nil, ErrCorrupted
+	}
+
+	return data, nil
+}
+
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id))
+}
+
+// additionalData binds chunk to the file and its position, so chunks can not be swapped.
+func additionalData(id string, idx int) []byte {
+	ad := make([]byte, 0, len(id)+8)
+	ad = append(ad, id...)
+
+	return binary.BigEndian.AppendUint64(ad, uint64(idx))
+}
+
+// reader decrypts chunks one by one as the content is read.
+type reader struct {
+	store *Store
+	id    string
+	count int
+
+	next int
+	buf  []byte
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.next == r.count {
+			return 0, io.EOF
+		}
+
+		data, err := r.store.readChunk(r.id, r.next)
+		if err != nil {
+			return 0, err
+		}
+		r.buf = data
+		r.next++
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+func (r *reader) Close() error {
+	r.buf = nil
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package chunks
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_WriteAndOpen(t *testing.T) {
+	store := newStore(t)
+
+	data := randomBytes(t, 2*ChunkSize+100)
+	size, count, err := store.Write("file", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), size)
+	assert.Equal(t, 3, count)
+
+	assert.Equal(t, data, readAll(t, store.Open("file", count)))
+
+	// content is stored encrypted
+	chunk, err := os.ReadFile(filepath.Join(store.path("file"), "0"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(chunk), string(data[:64]))
+}
+
+func TestStore_WriteReplacesContent(t *testing.T) {
+	store := newStore(t)
+
+	_, _, err := store.Write("file", bytes.NewReader(randomBytes(t, 2*ChunkSize)))
+	require.NoError(t, err)
+
+	data := randomBytes(t, 10)
+	_, count, err := store.Write("file", bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, data, readAll(t, store.Open("file", count)))
+
+	// chunks of the previous content are removed
+	_, err = os.Stat(filepath.Join(store.path("file"), "1"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestStore_Corrupted(t *testing.T) {
+	store := newStore(t)
+
+	_, count, err := store.Write("file", bytes.NewReader(randomBytes(t, 100)))
+	require.NoError(t, err)
+
+	path := filepath.Join(store.path("file"), "0")
+	chunk, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	chunk[len(chunk)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, chunk, 0o600))
+
+	_, err = io.ReadAll(store.Open("file", count))
+	assert.ErrorIs(t, err, ErrCorrupted)
+
+	// truncated chunk
+	require.NoError(t, os.WriteFile(path, chunk[:4], 0o600))
+
+	_, err = io.ReadAll(store.Open("file", count))
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestStore_ChunksCanNotBeSwapped(t *testing.T) {
+	store := newStore(t)
+
+	_, count, err := store.Write("file", bytes.NewReader(randomBytes(t, ChunkSize+1)))
+	require.NoError(t, err)
+
+	dir := store.path("file")
+	require.NoError(t, os.Rename(filepath.Join(dir, "0"), filepath.Join(dir, "tmp")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "1"), filepath.Join(dir, "0")))
+	require.NoError(t, os.Rename(filepath.Join(dir, "tmp"), filepath.Join(dir, "1")))
+
+	_, err = io.ReadAll(store.Open("file", count))
+	assert.ErrorIs(t, err, ErrCorrupted)
+}
+
+func TestStore_Missing(t *testing.T) {
+	store := newStore(t)
+
+	_, err := io.ReadAll(store.Open("missing", 1))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	_, count, err := store.Write("file", bytes.NewReader(randomBytes(t, 100)))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Remove("file"))
+
+	_, err = io.ReadAll(store.Open("file", count))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	// removing missing file is not an error
+	require.NoError(t, store.Remove("file"))
+}
+
+func TestStore_FailedWriteKeepsContent(t *testing.T) {
+	store := newStore(t)
+
+	data := randomBytes(t, 100)
+	_, count, err := store.Write("file", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	_, _, err = store.Write("file", io.MultiReader(bytes.NewReader(randomBytes(t, ChunkSize+1)), errReader{}))
+	require.Error(t, err)
+
+	assert.Equal(t, data, readAll(t, store.Open("file", count)))
+}
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+
+	key := base64.StdEncoding.EncodeToString(randomBytes(t, 32))
+
+	store, err := New(t.TempDir(), []byte(key))
+	require.NoError(t, err)
+
+	return store
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
+
+func readAll(t *testing.T, r io.ReadCloser) []byte {
+	t.Helper()
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return data
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
@@ -0,0 +1,189 @@
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrNotCached is returned when content of the file was evicted from the local cache.
+var ErrNotCached = errors.New("file content is not cached")
+
+// OpenFile returns reader of the cached content of the file and marks the file as recently used.
+// ErrNotCached is returned if the content was evicted. The caller is responsible for closing the reader.
+func (rep *ClientRepository) OpenFile(id string) (io.ReadCloser, error) {
+	var (
+		count  int
+		cached bool
+	)
+
+	err := rep.db.QueryRow("SELECT chunks, cached FROM files WHERE id = ?", id).Scan(&count, &cached)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("file with id '%s' not found", id)
+		}
+		return nil, fmt.Errorf("error requesting file: %w", err)
+	}
+
+	if !cached {
+		return nil, ErrNotCached
+	}
+
+	_, err = rep.db.Exec("UPDATE files SET accessed_at = ? WHERE id = ?", time.Now(), id)
+	if err != nil {
+		return nil, fmt.Errorf("error updating file access time: %w", err)
+	}
+
+	return rep.chunks.Open(id, count), nil
+}
+
+// cacheFile stores content of the file located at filePath as chunks of the file with id.
+func (rep *ClientRepository) cacheFile(id, filePath string) (int64, int, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		logger.Error("error while reading file", zap.Error(err))
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	return rep.chunks.Write(id, f)
+}
+
+// evict drops content of the least recently used files until total size of the cache fits the limit.
+// Metadata of evicted files is kept, so they are still listed and synced.
+func (rep *ClientRepository) evict() error {
+	if rep.cacheLimit <= 0 {
+		return nil
+	}
+
+	var total int64
+	err := rep.db.QueryRow("SELECT COALESCE(SUM(size), 0) FROM files WHERE cached = 1").Scan(&total)
+	if err != nil {
+		return fmt.Errorf("error calculating cache size: %w", err)
+	}
+
+	for total > rep.cacheLimit {
+		var (
+			id   string
+			size int64
+		)
+
+		err := rep.db.QueryRow("SELECT id, size FROM files WHERE cached = 1 ORDER BY accessed_at ASC LIMIT 1").Scan(&id, &size)
+		if err != nil {
+			return fmt.Errorf("error selecting file to evict: %w", err)
+		}
+
+		if err := rep.chunks.Remove(id); err != nil {
+			return err
+		}
+
+		_, err = rep.db.Exec("UPDATE files SET cached = 0, chunks = 0 WHERE id = ?", id)
+		if err != nil {
+			return fmt.Errorf("error evicting file: %w", err)
+		}
+
+		logger.Debug("file evicted from cache", zap.String("id", id), zap.Int64("size", size))
+
+		total -= size
+	}
+
+	return nil
+}
+
+// migrateFiles moves content of files cached by previous versions as database blobs into the chunk store.
+// Files are migrated one at a time, so only a single file is held in memory.
+func (rep *ClientRepository) migrateFiles() error {
+	exists, err := hasColumn(rep.db, "files", "data")
+	if err != nil || !exists {
+		return err
+	}
+
+	rows, err := rep.db.Query("SELECT id FROM files WHERE data IS NOT NULL")
+	if err != nil {
+		return err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		var data []byte
+		if err := rep.db.QueryRow("SELECT data FROM files WHERE id = ?", id).Scan(&data); err != nil {
+			return err
+		}
+
+		size, count, err := rep.chunks.Write(id, bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		_, err = rep.db.Exec("UPDATE files SET data = NULL, size = ?, chunks = ?, cached = 1, accessed_at = updated_at WHERE id = ?",
+			size, count, id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return rep.evict()
+}
+
+// addColumn adds column described by definition to the table, unless the table already has it.
+func addColumn(db *sql.DB, table, definition string) error {
+	name := strings.Fields(definition)[0]
+
+	exists, err := hasColumn(db, table, name)
+	if err != nil || exists {
+		return err
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", table, definition))
+
+	return err
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			ctype     string
+			notNull   bool
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &ctype, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+
+		if name == column {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
@@ -0,0 +1,204 @@
+package sqlite
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/storage/chunks"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("error")
+
+	os.Exit(m.Run())
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	rep, _ := newRepository(t, 250)
+
+	a, b, c := randomBytes(t, 100), randomBytes(t, 100), randomBytes(t, 100)
+	require.NoError(t, rep.SaveFile("a", writeFile(t, a), "", "etag-a"))
+	require.NoError(t, rep.SaveFile("b", writeFile(t, b), "", "etag-b"))
+
+	// reading a makes b the least recently used file
+	assert.Equal(t, a, readFile(t, rep, "a"))
+
+	require.NoError(t, rep.SaveFile("c", writeFile(t, c), "", "etag-c"))
+
+	_, err := rep.OpenFile("b")
+	assert.ErrorIs(t, err, ErrNotCached)
+
+	// metadata of evicted file is kept
+	file, err := rep.GetFile("b")
+	require.NoError(t, err)
+	assert.False(t, file.Cached)
+	assert.Equal(t, "etag-b", file.Etag)
+
+	assert.Equal(t, a, readFile(t, rep, "a"))
+	assert.Equal(t, c, readFile(t, rep, "c"))
+	assert.LessOrEqual(t, cachedSize(t, rep), int64(250))
+}
+
+func TestCache_SizeLimit(t *testing.T) {
+	rep, _ := newRepository(t, 250)
+
+	require.NoError(t, rep.SaveFile("a", writeFile(t, randomBytes(t, 100)), "", ""))
+
+	// file larger than the limit evicts everything, including itself
+	require.NoError(t, rep.SaveFile("big", writeFile(t, randomBytes(t, 300)), "", ""))
+	assert.Zero(t, cachedSize(t, rep))
+
+	for _, id := range []string{"a", "big"} {
+		_, err := rep.OpenFile(id)
+		assert.ErrorIs(t, err, ErrNotCached)
+	}
+
+	// updated content is cached again
+	data := randomBytes(t, 200)
+	require.NoError(t, rep.UpdateFile("big", "etag", writeFile(t, data)))
+	assert.Equal(t, data, readFile(t, rep, "big"))
+	assert.Equal(t, int64(200), cachedSize(t, rep))
+}
+
+func TestCache_NoLimit(t *testing.T) {
+	rep, _ := newRepository(t, 0)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, rep.SaveFile(id, writeFile(t, randomBytes(t, 100)), "", ""))
+	}
+
+	assert.Equal(t, int64(300), cachedSize(t, rep))
+}
+
+func TestCache_MissingEntry(t *testing.T) {
+	rep, _ := newRepository(t, 0)
+
+	_, err := rep.OpenFile("unknown")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotCached)
+
+	// content removed from disk behind the index is reported on read
+	require.NoError(t, rep.SaveFile("a", writeFile(t, randomBytes(t, 100)), "", ""))
+	require.NoError(t, rep.chunks.Remove("a"))
+
+	r, err := rep.OpenFile("a")
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestCache_CorruptedEntry(t *testing.T) {
+	rep, dir := newRepository(t, 0)
+	require.NoError(t, rep.SaveFile("a", writeFile(t, randomBytes(t, 100)), "", ""))
+
+	// the same file written with another key can not be decrypted
+	other, err := chunks.New(dir, newKey(t))
+	require.NoError(t, err)
+	_, _, err = other.Write("a", io.LimitReader(rand.Reader, 100))
+	require.NoError(t, err)
+
+	r, err := rep.OpenFile("a")
+	require.NoError(t, err)
+	defer r.Close()
+
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, chunks.ErrCorrupted)
+}
+
+func TestCache_DeleteFile(t *testing.T) {
+	rep, dir := newRepository(t, 0)
+	require.NoError(t, rep.SaveFile("a", writeFile(t, randomBytes(t, 100)), "", ""))
+
+	require.NoError(t, rep.DeleteFile("a"))
+
+	_, err := rep.GetFile("a")
+	require.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "a"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+// newRepository returns repository caching at most cacheLimit bytes and directory of its chunks.
+func newRepository(t *testing.T, cacheLimit int64) (*ClientRepository, string) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	store, err := chunks.New(filepath.Join(dir, "chunks"), newKey(t))
+	require.NoError(t, err)
+
+	rep, err := NewClientRepository(filepath.Join(dir, "client.db"), store, cacheLimit)
+	require.NoError(t, err)
+	t.Cleanup(func() { rep.db.Close() })
+
+	return rep, filepath.Join(dir, "chunks")
+}
+
+func newKey(t *testing.T) []byte {
+	t.Helper()
+
+	return []byte(base64.StdEncoding.EncodeToString(randomBytes(t, 32)))
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+
+	return b
+}
+
+// writeFile writes data to a temporary file and returns its path.
+func writeFile(t *testing.T, data []byte) string {
+	t.Helper()
+
+	f, err := os.CreateTemp(t.TempDir(), "file")
+	require.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write(data)
+	require.NoError(t, err)
+
+	return f.Name()
+}
+
+func readFile(t *testing.T, rep *ClientRepository, id string) []byte {
+	t.Helper()
+
+	r, err := rep.OpenFile(id)
+	require.NoError(t, err)
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	return data
+}
+
+func cachedSize(t *testing.T, rep *ClientRepository) int64 {
+	t.Helper()
+
+	files, err := rep.ListAllFiles()
+	require.NoError(t, err)
+
+	var size int64
+	for _, f := range files {
+		if f.Cached {
+			size += f.Size
+		}
+	}
+
+	return size
+}
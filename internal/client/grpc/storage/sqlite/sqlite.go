@@ -4,28 +4,36 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	models "github.com/igortoigildin/goph-keeper/internal/client/grpc/models"
-	"github.com/igortoigildin/goph-keeper/pkg/logger"
-	"go.uber.org/zap"
+	"github.com/igortoigildin/goph-keeper/internal/client/grpc/storage/chunks"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 type ClientRepository struct {
 	db *sql.DB
+
+	// content of cached files, the database keeps only their index
+	chunks     *chunks.Store
+	cacheLimit int64 // total size of cached files in bytes, zero means no limit
 }
 
-func NewClientRepository(path string) (*ClientRepository, error) {
+func NewClientRepository(path string, store *chunks.Store, cacheLimit int64) (*ClientRepository, error) {
 	db, err := InitDB(path)
 	if err != nil {
 		return nil, err
 	}
 
 	c := ClientRepository{
-		db: db,
+		db:         db,
+		chunks:     store,
+		cacheLimit: cacheLimit,
+	}
+
+	if err := c.migrateFiles(); err != nil {
+		return nil, fmt.Errorf("error migrating cached files: %w", err)
 	}
 
 	return &c, nil
@@ -68,10 +76,13 @@ func InitDB(path string) (*sql.DB, error) {
 	CREATE TABLE IF NOT EXISTS files (
 		id TEXT PRIMARY KEY,
 		filename TEXT,
-		data BLOB,
 		info TEXT,
 		updated_at DATETIME,
-		etag TEXT
+		etag TEXT,
+		size INTEGER NOT NULL DEFAULT 0,
+		chunks INTEGER NOT NULL DEFAULT 0,
+		cached INTEGER NOT NULL DEFAULT 0,
+		accessed_at DATETIME
 	);
 	`
 	_, err = db.Exec(sqlStmt)
@@ -79,6 +90,18 @@ func InitDB(path string) (*sql.DB, error) {
 		return nil, err
 	}
 
+	// databases created before files were cached in chunks lack the index columns
+	for _, column := range []string{
+		"size INTEGER NOT NULL DEFAULT 0",
+		"chunks INTEGER NOT NULL DEFAULT 0",
+		"cached INTEGER NOT NULL DEFAULT 0",
+		"accessed_at DATETIME",
+	} {
+		if err := addColumn(db, "files", column); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
@@ -231,29 +254,26 @@ func (rep *ClientRepository) GetBankDetails(id string) (models.BankDetails, erro
 	return b, nil
 }
 
+// SaveFile saves metadata of the file and caches its content read from filePath.
 func (rep *ClientRepository) SaveFile(id, filePath, info, etag string) error {
-	fileData, err := os.ReadFile(filePath)
+	size, count, err := rep.cacheFile(id, filePath)
 	if err != nil {
-		logger.Error("error while reading file", zap.Error(err))
 		return err
 	}
 
-	f := models.File{
-		ID:        id,
-		Filename:  filePath,
-		Data:      fileData,
-		UpdatedAt: time.Now(),
-		Info:      info,
-		Etag:      etag,
+	now := time.Now()
+	_, err = rep.db.Exec(`INSERT OR REPLACE INTO files (id, filename, info, updated_at, etag, size, chunks, cached, accessed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?)`,
+		id, filePath, info, now, etag, size, count, now)
+	if err != nil {
+		return err
 	}
 
-	_, err = rep.db.Exec("INSERT OR REPLACE INTO files (id, filename, data, updated_at, info, etag) VALUES (?, ?, ?, ?, ?, ?)",
-		f.ID, f.Filename, f.Data, f.UpdatedAt, f.Info, f.Etag)
-	return err
+	return rep.evict()
 }
 
 func (rep *ClientRepository) ListAllFiles() ([]models.File, error) {
-	rows, err := rep.db.Query("SELECT id, filename, data, info, updated_at, etag FROM files")
+	rows, err := rep.db.Query("SELECT id, filename, size, info, updated_at, etag, cached FROM files")
 	if err != nil {
 		return nil, err
 	}
@@ -262,24 +282,24 @@ func (rep *ClientRepository) ListAllFiles() ([]models.File, error) {
 	var files []models.File
 	for rows.Next() {
 		var f models.File
-		var ignored []byte
-		err = rows.Scan(&f.ID, &f.Filename, &ignored, &f.Info, &f.UpdatedAt, &f.Etag)
+		err = rows.Scan(&f.ID, &f.Filename, &f.Size, &f.Info, &f.UpdatedAt, &f.Etag, &f.Cached)
 		if err != nil {
 			return nil, err
 		}
 		files = append(files, f)
 	}
-	return files, nil
+	return files, rows.Err()
 }
 
+// GetFile returns metadata of the file, its content is read with OpenFile.
 func (rep *ClientRepository) GetFile(id string) (models.File, error) {
 	var f models.File
 
 	err := rep.db.QueryRow(`
-		SELECT id, filename, data, updated_at, info, etag
+		SELECT id, filename, size, updated_at, info, etag, cached
 		FROM files
 		WHERE id = ?
-	`, id).Scan(&f.ID, &f.Filename, &f.Data, &f.UpdatedAt, &f.Info, &f.Etag)
+	`, id).Scan(&f.ID, &f.Filename, &f.Size, &f.UpdatedAt, &f.Info, &f.Etag, &f.Cached)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -311,15 +331,28 @@ func (rep *ClientRepository) UpdateBankDetails(id, cardNumber, cvc, expDate, ban
 	return err
 }
 
-func (rep *ClientRepository) UpdateFile(id, etag string, data []byte) error {
-	f := models.File{
-		ID:        id,
-		Data:      data,
-		UpdatedAt: time.Now(),
-		Etag:      etag,
+// UpdateFile replaces cached content of the file with the content of file located at filePath.
+func (rep *ClientRepository) UpdateFile(id, etag, filePath string) error {
+	size, count, err := rep.cacheFile(id, filePath)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = rep.db.Exec("UPDATE files SET updated_at = ?, etag = ?, size = ?, chunks = ?, cached = 1, accessed_at = ? WHERE id = ?",
+		now, etag, size, count, now, id)
+	if err != nil {
+		return err
+	}
+
+	return rep.evict()
+}
+
+func (rep *ClientRepository) DeleteFile(id string) error {
+	_, err := rep.db.Exec("DELETE FROM files WHERE id = ?", id)
+	if err != nil {
+		return err
 	}
 
-	_, err := rep.db.Exec("INSERT OR REPLACE INTO files (id, data, updated_at, etag) VALUES (?, ?, ?, ?, ?, ?)",
-		f.ID, f.Data, f.UpdatedAt, f.Etag)
-	return err
+	return rep.chunks.Remove(id)
 }
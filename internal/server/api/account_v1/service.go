@@ -0,0 +1,17 @@
+package account
+
+import (
+	"github.com/igortoigildin/goph-keeper/internal/server/service"
+	desc "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+)
+
+type Implementation struct {
+	desc.UnimplementedAccountV1Server
+	accountService service.AccountService
+}
+
+func NewImplementation(accountService service.AccountService) *Implementation {
+	return &Implementation{
+		accountService: accountService,
+	}
+}
@@ -0,0 +1,23 @@
+package account
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	desc "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+)
+
+func (i *Implementation) GetUsage(ctx context.Context, req *desc.GetUsageRequest) (*desc.GetUsageResponse, error) {
+	usage, quota, err := i.accountService.GetUsage(ctx)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to get usage")
+	}
+
+	return &desc.GetUsageResponse{
+		Bytes:         uint64(max(usage.Bytes, 0)),
+		Objects:       uint64(max(usage.Objects, 0)),
+		MaxBytes:      uint64(quota.MaxBytes),
+		MaxObjects:    uint64(quota.MaxObjects),
+		MaxObjectSize: uint64(quota.MaxObjectSize),
+	}, nil
+}
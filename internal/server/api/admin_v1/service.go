@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"github.com/igortoigildin/goph-keeper/internal/server/service"
+	desc "github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+)
+
+type Implementation struct {
+	desc.UnimplementedAdminV1Server
+	adminService service.AdminService
+}
+
+func NewImplementation(adminService service.AdminService) *Implementation {
+	return &Implementation{
+		adminService: adminService,
+	}
+}
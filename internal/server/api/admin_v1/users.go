@@ -0,0 +1,99 @@
+package admin
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	desc "github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *Implementation) ListUsers(ctx context.Context, req *desc.ListUsersRequest) (*desc.ListUsersResponse, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	users, err := i.adminService.ListUsers(ctx)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to list users")
+	}
+
+	res := &desc.ListUsersResponse{Users: make([]*desc.User, 0, len(users))}
+	for _, u := range users {
+		res.Users = append(res.Users, &desc.User{
+			Login:     u.Login,
+			Role:      u.Role,
+			Disabled:  u.Disabled,
+			CreatedAt: timestamppb.New(u.CreatedAt),
+			Bytes:     uint64(max(u.Bytes, 0)),
+			Objects:   uint64(max(u.Objects, 0)),
+		})
+	}
+
+	return res, nil
+}
+
+func (i *Implementation) DisableUser(ctx context.Context, req *desc.UserRequest) (*emptypb.Empty, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	if err := i.adminService.DisableUser(ctx, req.GetLogin()); err != nil {
+		return nil, apierror.Status(err, "failed to disable user")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (i *Implementation) EnableUser(ctx context.Context, req *desc.UserRequest) (*emptypb.Empty, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	if err := i.adminService.EnableUser(ctx, req.GetLogin()); err != nil {
+		return nil, apierror.Status(err, "failed to enable user")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (i *Implementation) LogoutUser(ctx context.Context, req *desc.UserRequest) (*emptypb.Empty, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	if err := i.adminService.LogoutUser(ctx, req.GetLogin()); err != nil {
+		return nil, apierror.Status(err, "failed to log user out")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (i *Implementation) IssueResetToken(ctx context.Context, req *desc.UserRequest) (*desc.IssueResetTokenResponse, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	token, expiresAt, err := i.adminService.IssueResetToken(ctx, req.GetLogin())
+	if err != nil {
+		return nil, apierror.Status(err, "failed to issue reset token")
+	}
+
+	return &desc.IssueResetTokenResponse{
+		ResetToken: token,
+		ExpiresAt:  timestamppb.New(expiresAt),
+	}, nil
+}
+
+func (i *Implementation) DeleteUserData(ctx context.Context, req *desc.UserRequest) (*emptypb.Empty, error) {
+	if err := i.adminService.Authorize(ctx); err != nil {
+		return nil, apierror.Status(err, "failed to authorize")
+	}
+
+	if err := i.adminService.DeleteUserData(ctx, req.GetLogin()); err != nil {
+		return nil, apierror.Status(err, "failed to delete user data")
+	}
+
+	return &emptypb.Empty{}, nil
+}
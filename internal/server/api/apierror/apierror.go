@@ -0,0 +1,72 @@
+package apierror
+
+import (
+	"errors"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// Domain is the domain of error reasons passed to clients in google.rpc.ErrorInfo details.
+const Domain = "goph-keeper"
+
+// Status converts error returned by services into grpc status error.
+// Status errors are returned as is, domain errors keep their message, get the code of their kind and carry their reason in error details.
+// Other errors are reported as Internal with msg, so that details of the failure are not leaked to clients.
+func Status(err error, msg string) error {
+	// errors of the stream, e.g. message rejected by validation, already carry their status
+	var statusErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &statusErr) {
+		return statusErr.GRPCStatus().Err()
+	}
+
+	var domainErr *storage.Error
+	if !errors.As(err, &domainErr) {
+		return status.Error(codes.Internal, msg)
+	}
+
+	st := status.New(Code(domainErr.Kind), domainErr.Msg)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: domainErr.Reason, Domain: Domain}}
+	if errors.Is(domainErr, storage.ErrQuotaExceeded) {
+		details = append(details, &errdetails.QuotaFailure{
+			Violations: []*errdetails.QuotaFailure_Violation{{Subject: domainErr.Reason, Description: domainErr.Msg}},
+		})
+	}
+
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
+
+// Code returns grpc status code of the domain error kind.
+func Code(kind error) codes.Code {
+	switch {
+	case errors.Is(kind, storage.ErrNotFound):
+		return codes.NotFound
+	case errors.Is(kind, storage.ErrPermissionDenied):
+		return codes.PermissionDenied
+	case errors.Is(kind, storage.ErrConflict):
+		return codes.AlreadyExists
+	case errors.Is(kind, storage.ErrInvalidArgument):
+		return codes.InvalidArgument
+	case errors.Is(kind, storage.ErrQuotaExceeded):
+		return codes.ResourceExhausted
+	case errors.Is(kind, storage.ErrUnauthenticated):
+		return codes.Unauthenticated
+	case errors.Is(kind, storage.ErrFailedPrecondition):
+		return codes.FailedPrecondition
+	case errors.Is(kind, storage.ErrAborted):
+		return codes.Aborted
+	case errors.Is(kind, storage.ErrOutOfRange):
+		return codes.OutOfRange
+	default:
+		return codes.Internal
+	}
+}
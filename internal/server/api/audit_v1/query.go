@@ -0,0 +1,65 @@
+package audit
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	desc "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *Implementation) Query(ctx context.Context, req *desc.QueryRequest) (*desc.QueryResponse, error) {
+	filter := models.AuditFilter{
+		Actor:    req.GetActor(),
+		ObjectID: req.GetObjectId(),
+		Action:   models.AuditAction(req.GetAction()),
+		Limit:    uint64(req.GetPageSize()),
+	}
+
+	if req.GetFrom() != nil {
+		filter.From = req.GetFrom().AsTime()
+	}
+
+	if req.GetTo() != nil {
+		filter.To = req.GetTo().AsTime()
+	}
+
+	if token := req.GetPageToken(); token != "" {
+		beforeID, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || beforeID <= 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page token")
+		}
+
+		filter.BeforeID = beforeID
+	}
+
+	events, next, err := i.auditService.Query(ctx, filter)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to query audit events")
+	}
+
+	res := &desc.QueryResponse{Events: make([]*desc.Event, 0, len(events))}
+	for _, e := range events {
+		res.Events = append(res.Events, &desc.Event{
+			Id:        e.ID,
+			Time:      timestamppb.New(e.Time),
+			Actor:     e.Actor,
+			Action:    string(e.Action),
+			ObjectId:  e.ObjectID,
+			Method:    e.Method,
+			Peer:      e.Peer,
+			RequestId: e.RequestID,
+			Result:    e.Result,
+		})
+	}
+
+	if next != 0 {
+		res.NextPageToken = strconv.FormatInt(next, 10)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,17 @@
+package audit
+
+import (
+	"github.com/igortoigildin/goph-keeper/internal/server/service"
+	desc "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+)
+
+type Implementation struct {
+	desc.UnimplementedAuditV1Server
+	auditService service.AuditService
+}
+
+func NewImplementation(auditService service.AuditService) *Implementation {
+	return &Implementation{
+		auditService: auditService,
+	}
+}
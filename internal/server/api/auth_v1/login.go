@@ -2,9 +2,8 @@ package auth
 
 import (
 	"context"
-	"errors"
 
-	auth "github.com/igortoigildin/goph-keeper/internal/server/service/auth"
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
 	descAuth "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -21,14 +20,35 @@ func (i *Implementation) Login(ctx context.Context, req *descAuth.LoginRequest)
 
 	tkn, err := i.authService.Login(ctx, req.GetLogin(), req.GetPassword())
 	if err != nil {
-		if errors.Is(err, auth.ErrInvalidCredentials) {
-			return nil, status.Error(codes.Unauthenticated, "invalid credentials")
-		} else {
-			return nil, status.Error(codes.Unknown, "failed to login")
-		}
+		return nil, apierror.Status(err, "failed to login")
 	}
 
 	return &descAuth.LoginResponse{
 		Token: tkn,
 	}, nil
 }
+
+func (i *Implementation) LoginStart(ctx context.Context, req *descAuth.LoginStartRequest) (*descAuth.LoginStartResponse, error) {
+	challenge, err := i.authService.LoginStart(ctx, req.GetLogin(), req.GetClientKey())
+	if err != nil {
+		return nil, apierror.Status(err, "failed to start login")
+	}
+
+	return &descAuth.LoginStartResponse{
+		SessionId: challenge.SessionID,
+		Salt:      challenge.Salt,
+		ServerKey: challenge.ServerKey,
+	}, nil
+}
+
+func (i *Implementation) LoginFinish(ctx context.Context, req *descAuth.LoginFinishRequest) (*descAuth.LoginFinishResponse, error) {
+	tkn, serverProof, err := i.authService.LoginFinish(ctx, req.GetLogin(), req.GetSessionId(), req.GetClientProof())
+	if err != nil {
+		return nil, apierror.Status(err, "failed to login")
+	}
+
+	return &descAuth.LoginFinishResponse{
+		Token:       tkn,
+		ServerProof: serverProof,
+	}, nil
+}
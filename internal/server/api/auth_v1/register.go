@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
 	descAuth "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
-	pgx "github.com/jackc/pgx/v4"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -17,21 +19,23 @@ func (i *Implementation) Register(ctx context.Context, req *descAuth.RegisterReq
 		return nil, status.Error(codes.InvalidArgument, "login is required")
 	}
 
-	if req.GetPassword() == "" {
-		return nil, status.Error(codes.InvalidArgument, "password is required")
+	if req.GetPassword() == "" && len(req.GetVerifier()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "password or verifier is required")
 	}
 
-	id, err := i.authService.RegisterNewUser(ctx, req.Login, req.Password)
+	id, err := i.authService.RegisterNewUser(ctx, req.GetLogin(), models.Credentials{
+		Password: req.GetPassword(),
+		Salt:     req.GetSalt(),
+		Verifier: req.GetVerifier(),
+	})
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
+		if errors.Is(err, storage.ErrUserExists) {
 			logger.Warn("User with such login already exists", zap.Error(err))
-
-			return nil, status.Errorf(codes.AlreadyExists, `User with email %s already exists`, req.Login)
 		} else {
-			logger.Error("login error", zap.Error(err))
-
-			return nil, status.Error(codes.Unknown, "failed to login")
+			logger.Error("register error", zap.Error(err))
 		}
+
+		return nil, apierror.Status(err, "failed to register")
 	}
 
 	return &descAuth.RegisterResponse{
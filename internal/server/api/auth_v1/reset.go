@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	descAuth "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+)
+
+func (i *Implementation) ResetPassword(ctx context.Context, req *descAuth.ResetPasswordRequest) (*descAuth.ResetPasswordResponse, error) {
+	err := i.authService.ResetPassword(ctx, req.GetLogin(), req.GetResetToken(), models.Credentials{
+		Password: req.GetNewPassword(),
+		Salt:     req.GetSalt(),
+		Verifier: req.GetVerifier(),
+	})
+	if err != nil {
+		return nil, apierror.Status(err, "failed to reset password")
+	}
+
+	return &descAuth.ResetPasswordResponse{}, nil
+}
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	descAuth "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *Implementation) CreateToken(ctx context.Context, req *descAuth.CreateTokenRequest) (*descAuth.CreateTokenResponse, error) {
+	scope := models.TokenScope{
+		ReadOnly:  req.GetScope().GetReadOnly(),
+		SecretIDs: req.GetScope().GetSecretIds(),
+		DataTypes: req.GetScope().GetDataTypes(),
+	}
+
+	token, info, err := i.authService.CreateToken(ctx, req.GetName(), req.GetExpiresAt().AsTime(), scope)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to create token")
+	}
+
+	return &descAuth.CreateTokenResponse{
+		Token: token,
+		Info:  toDesc(info),
+	}, nil
+}
+
+func (i *Implementation) ListTokens(ctx context.Context, req *descAuth.ListTokensRequest) (*descAuth.ListTokensResponse, error) {
+	tokens, err := i.authService.ListTokens(ctx)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to list tokens")
+	}
+
+	res := &descAuth.ListTokensResponse{Tokens: make([]*descAuth.ApiToken, 0, len(tokens))}
+	for _, t := range tokens {
+		res.Tokens = append(res.Tokens, toDesc(&t))
+	}
+
+	return res, nil
+}
+
+func (i *Implementation) RevokeToken(ctx context.Context, req *descAuth.RevokeTokenRequest) (*descAuth.RevokeTokenResponse, error) {
+	if err := i.authService.RevokeToken(ctx, req.GetId()); err != nil {
+		return nil, apierror.Status(err, "failed to revoke token")
+	}
+
+	return &descAuth.RevokeTokenResponse{}, nil
+}
+
+// toDesc converts the token to its description, the token itself is not stored.
+func toDesc(t *models.APIToken) *descAuth.ApiToken {
+	return &descAuth.ApiToken{
+		Id:   t.ID,
+		Name: t.Name,
+		Scope: &descAuth.TokenScope{
+			ReadOnly:  t.ReadOnly,
+			SecretIds: t.SecretIDs,
+			DataTypes: t.DataTypes,
+		},
+		CreatedAt: timestamppb.New(t.CreatedAt),
+		ExpiresAt: timestamppb.New(t.ExpiresAt),
+	}
+}
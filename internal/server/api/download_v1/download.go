@@ -2,13 +2,17 @@ package download
 
 import (
 	"context"
+	"io"
+	"math"
 
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
 	desc "github.com/igortoigildin/goph-keeper/pkg/download_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (i *Implementation) DownloadBankData(ctx context.Context, req *desc.DownloadBankDataRequest) (*desc.DownloadBankDataResponse, error) {
@@ -16,7 +20,7 @@ func (i *Implementation) DownloadBankData(ctx context.Context, req *desc.Downloa
 	if err != nil {
 		logger.Error("error downloading card details:", zap.Error(err))
 
-		return nil, status.Error(codes.Unknown, "failed to download bank data")
+		return nil, apierror.Status(err, "failed to download bank data")
 	}
 
 	return &desc.DownloadBankDataResponse{
@@ -30,7 +34,7 @@ func (i *Implementation) DownloadPassword(ctx context.Context, req *desc.Downloa
 	if err != nil {
 		logger.Error("error downloading pass details:", zap.Error(err))
 
-		return nil, status.Error(codes.Unknown, "failed to download credentials")
+		return nil, apierror.Status(err, "failed to download credentials")
 	}
 
 	return &desc.DownloadPasswordResponse{
@@ -40,26 +44,90 @@ func (i *Implementation) DownloadPassword(ctx context.Context, req *desc.Downloa
 }
 
 func (i *Implementation) DownloadText(ctx context.Context, req *desc.DownloadTextRequest) (*desc.DownloadTextResponse, error) {
-	res, metadata, err := i.downloadService.DownloadText(ctx, req.GetUuid())
+	download := i.downloadService.DownloadText
+	if req.GetDataType() == folderData {
+		download = i.downloadService.DownloadFolder
+	}
+
+	res, err := download(ctx, req.GetUuid())
 	if err != nil {
 		logger.Error("error downloading text:", zap.Error(err))
 
-		return nil, status.Error(codes.Unknown, "failed to download text")
+		return nil, apierror.Status(err, "failed to download text")
 	}
 
 	return &desc.DownloadTextResponse{
-		Text:     res,
-		Metadata: metadata,
+		Text:        string(res.Data),
+		Metadata:    res.Metadata,
+		Compression: res.Compression,
 	}, nil
 }
 
 func (i *Implementation) DownloadFile(req *desc.DownloadFileRequest, stream grpc.ServerStreamingServer[desc.DownloadFileResponse]) error {
-	res, metadata, err := i.downloadService.DownloadFile(stream.Context(), req.GetUuid())
+	if req.GetOffset() > math.MaxInt64 || req.GetLength() > math.MaxInt64 {
+		return status.Error(codes.InvalidArgument, "invalid range")
+	}
+
+	file, err := i.downloadService.DownloadFile(stream.Context(), req.GetUuid(), int64(req.GetOffset()), int64(req.GetLength()))
 	if err != nil {
 		logger.Error("error downloading file:", zap.Error(err))
 
-		return status.Error(codes.Unknown, "failed to download bin file")
+		return apierror.Status(err, "failed to download bin file")
 	}
+	defer file.Reader.Close()
+
+	offset := req.GetOffset()
+	buf := make([]byte, chunkSize)
+
+	// The first message is sent even for empty range, so the client receives size and metadata.
+	for first := true; ; first = false {
+		num, err := io.ReadFull(file.Reader, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			logger.Error("error reading file from storage:", zap.Error(err))
+
+			return apierror.Status(err, "failed to download bin file")
+		}
+
+		if num > 0 || first {
+			resp := &desc.DownloadFileResponse{
+				Uuid:   req.GetUuid(),
+				Chunk:  buf[:num],
+				Offset: offset,
+				Size:   uint64(file.Size),
+			}
+			if first {
+				resp.Metadata = file.Metadata
+				resp.Sha256 = file.Checksum
+				resp.Compression = file.Compression
+			}
+
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
 
-	return stream.Send(&desc.DownloadFileResponse{Uuid: req.GetUuid(), Chunk: res, Metadata: metadata})
+			offset += uint64(num)
+		}
+
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func (i *Implementation) GetDownloadURL(ctx context.Context, req *desc.GetDownloadURLRequest) (*desc.GetDownloadURLResponse, error) {
+	url, err := i.downloadService.PresignDownload(ctx, req.GetUuid())
+	if err != nil {
+		logger.Error("error presigning download url:", zap.Error(err))
+
+		return nil, apierror.Status(err, "failed to get download url")
+	}
+
+	return &desc.GetDownloadURLResponse{
+		Url:         url.URL,
+		ExpiresAt:   timestamppb.New(url.ExpiresAt),
+		Size:        uint64(url.Size),
+		Metadata:    url.Metadata,
+		Sha256:      url.Checksum,
+		Compression: url.Compression,
+	}, nil
 }
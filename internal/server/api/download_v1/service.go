@@ -7,6 +7,12 @@ import (
 
 const (
 	filePath = "./"
+
+	// chunkSize is the size of file chunks streamed to the client.
+	chunkSize = 1024 * 1024
+
+	// folderData is the data type of folder manifests, which are downloaded as texts.
+	folderData = "folder_data"
 )
 
 type Implementation struct {
@@ -4,15 +4,14 @@ import (
 	"context"
 	"time"
 
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
 	desc "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
 )
 
 func (i *Implementation) GetObjectList(ctx context.Context, req *desc.SyncRequest) (*desc.SyncResponse, error) {
 	objects, err := i.listService.List(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to list objects")
+		return nil, apierror.Status(err, "failed to list objects")
 	}
 
 	objs := make([]*desc.ObjectInfo, len(objects))
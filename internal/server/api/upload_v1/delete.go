@@ -0,0 +1,18 @@
+package upload
+
+import (
+	"context"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (i *Implementation) DeleteFile(ctx context.Context, req *desc.DeleteFileRequest) (*emptypb.Empty, error) {
+	err := i.uploadService.DeleteFile(ctx)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to delete file")
+	}
+
+	return &emptypb.Empty{}, nil
+}
@@ -0,0 +1,48 @@
+package upload
+
+import (
+	"context"
+	"errors"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (i *Implementation) GetUploadURL(ctx context.Context, req *desc.GetUploadURLRequest) (*desc.GetUploadURLResponse, error) {
+	url, err := i.uploadService.PresignUpload(ctx)
+	if err != nil {
+		return nil, apierror.Status(err, "failed to get upload url")
+	}
+
+	return &desc.GetUploadURLResponse{
+		Url:       url.URL,
+		ExpiresAt: timestamppb.New(url.ExpiresAt),
+		FormData:  url.FormData,
+	}, nil
+}
+
+func (i *Implementation) CompleteUpload(ctx context.Context, req *desc.CompleteUploadRequest) (*desc.CompleteUploadResponse, error) {
+	res, err := i.uploadService.CompleteUpload(ctx, req.GetFileName(), req.GetMetadata(), req.GetSha256(), req.GetCompression())
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrObjectNotFound):
+			return nil, status.Error(codes.FailedPrecondition, "file is not uploaded")
+		case errors.Is(err, compression.ErrUnsupported):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		default:
+			return nil, apierror.Status(err, "failed to complete upload")
+		}
+	}
+
+	return &desc.CompleteUploadResponse{
+		FileName: res.FileName,
+		Size:     uint64(res.Size),
+		Etag:     res.ETag,
+		Sha256:   res.Checksum,
+	}, nil
+}
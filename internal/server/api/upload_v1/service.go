@@ -5,6 +5,9 @@ import (
 	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
 )
 
+// folderData is the data type of folder manifests, which are uploaded as texts.
+const folderData = "folder_data"
+
 type Implementation struct {
 	desc.UnimplementedUploadV1Server
 	uploadService service.UploadService
@@ -0,0 +1,85 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	upload "github.com/igortoigildin/goph-keeper/internal/server/service/upload"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (i *Implementation) InitUpload(ctx context.Context, req *desc.InitUploadRequest) (*desc.InitUploadResponse, error) {
+	if req.GetSize() > math.MaxInt64 {
+		return nil, status.Error(codes.InvalidArgument, "file is too large")
+	}
+
+	session, err := i.uploadService.InitUpload(ctx, req.GetFileName(), int64(req.GetSize()), req.GetMetadata(), req.GetCompression())
+	if err != nil {
+		if errors.Is(err, compression.ErrUnsupported) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, apierror.Status(err, "failed to init upload")
+	}
+
+	return &desc.InitUploadResponse{
+		UploadId:        session.UploadID,
+		CommittedOffset: uint64(session.CommittedOffset),
+		PartSize:        upload.PartSize,
+	}, nil
+}
+
+func (i *Implementation) UploadChunk(stream desc.UploadV1_UploadChunkServer) error {
+	err := i.uploadService.UploadChunk(stream)
+	if err != nil {
+		return uploadSessionError(err, "failed to upload chunk")
+	}
+
+	return nil
+}
+
+func (i *Implementation) GetUploadStatus(ctx context.Context, req *desc.GetUploadStatusRequest) (*desc.GetUploadStatusResponse, error) {
+	session, err := i.uploadService.UploadStatus(ctx, req.GetUploadId())
+	if err != nil {
+		return nil, uploadSessionError(err, "failed to get upload status")
+	}
+
+	return &desc.GetUploadStatusResponse{
+		UploadId:        session.UploadID,
+		CommittedOffset: uint64(session.CommittedOffset),
+		Size:            uint64(session.Size),
+	}, nil
+}
+
+func (i *Implementation) FinalizeUpload(ctx context.Context, req *desc.FinalizeUploadRequest) (*desc.FinalizeUploadResponse, error) {
+	if req.GetSize() > math.MaxInt64 {
+		return nil, status.Error(codes.InvalidArgument, "file is too large")
+	}
+
+	res, err := i.uploadService.FinalizeUpload(ctx, req.GetUploadId(), int64(req.GetSize()), req.GetSha256())
+	if err != nil {
+		return nil, uploadSessionError(err, "failed to finalize upload")
+	}
+
+	return &desc.FinalizeUploadResponse{
+		FileName: res.FileName,
+		Size:     uint64(res.Size),
+		Etag:     res.ETag,
+		Sha256:   res.Checksum,
+	}, nil
+}
+
+// uploadSessionError converts upload session errors into grpc status errors.
+func uploadSessionError(err error, msg string) error {
+	switch {
+	case errors.Is(err, upload.ErrChecksumMismatch):
+		return status.Error(codes.DataLoss, err.Error())
+	default:
+		return apierror.Status(err, msg)
+	}
+}
@@ -2,7 +2,11 @@ package upload
 
 import (
 	"context"
+	"errors"
 
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	upload "github.com/igortoigildin/goph-keeper/internal/server/service/upload"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
 	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -11,7 +15,15 @@ import (
 func (i *Implementation) UploadFile(stream desc.UploadV1_UploadFileServer) error {
 	err := i.uploadService.SaveFile(stream)
 	if err != nil {
-		return status.Error(codes.Unknown, "failed to upload file")
+		if errors.Is(err, upload.ErrChecksumMismatch) {
+			return status.Error(codes.DataLoss, err.Error())
+		}
+
+		if errors.Is(err, compression.ErrUnsupported) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return apierror.Status(err, "failed to upload file")
 	}
 
 	return nil
@@ -23,7 +35,7 @@ func (i *Implementation) UploadBankData(
 ) (*desc.UploadBankDataResponse, error) {
 	etag, err := i.uploadService.SaveBankData(ctx, req.GetData(), req.Metadata)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to upload bank data")
+		return nil, apierror.Status(err, "failed to upload bank data")
 	}
 
 	return &desc.UploadBankDataResponse{Etag: etag}, nil
@@ -35,7 +47,7 @@ func (i *Implementation) UploadPassword(
 ) (*desc.UploadPasswordResponse, error) {
 	etag, err := i.uploadService.SaveLoginPassword(ctx, req.GetData(), req.Metadata)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to upload credentials")
+		return nil, apierror.Status(err, "failed to upload credentials")
 	}
 
 	return &desc.UploadPasswordResponse{Etag: etag}, nil
@@ -45,9 +57,18 @@ func (i *Implementation) UploadText(
 	ctx context.Context,
 	req *desc.UploadTextRequest,
 ) (*desc.UploadTextResponse, error) {
-	etag, err := i.uploadService.SaveText(ctx, req.GetText(), req.GetMetadata())
+	save := i.uploadService.SaveText
+	if req.GetDataType() == folderData {
+		save = i.uploadService.SaveFolder
+	}
+
+	etag, err := save(ctx, req.GetText(), req.GetMetadata(), req.GetCompression())
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to upload text")
+		if errors.Is(err, compression.ErrUnsupported) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, apierror.Status(err, "failed to upload text")
 	}
 
 	return &desc.UploadTextResponse{Etag: etag}, nil
@@ -0,0 +1,174 @@
+package vault
+
+import (
+	"fmt"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	secret "github.com/igortoigildin/goph-keeper/internal/server/service/secret"
+	desc "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var secretTypes = map[desc.SecretType]models.SecretType{
+	desc.SecretType_SECRET_TYPE_CREDENTIAL: models.SecretTypeCredential,
+	desc.SecretType_SECRET_TYPE_CARD:       models.SecretTypeCard,
+	desc.SecretType_SECRET_TYPE_TEXT:       models.SecretTypeText,
+	desc.SecretType_SECRET_TYPE_FILE:       models.SecretTypeFile,
+	desc.SecretType_SECRET_TYPE_CUSTOM:     models.SecretTypeCustom,
+}
+
+// payloadFields are the fields of the payload oneof, each of them in a mask stands for the whole payload.
+var payloadFields = map[string]struct{}{
+	"credential": {},
+	"card":       {},
+	"text":       {},
+	"file":       {},
+	"custom":     {},
+}
+
+func toSecretType(t desc.SecretType) models.SecretType {
+	return secretTypes[t]
+}
+
+func fromSecretType(t models.SecretType) desc.SecretType {
+	for k, v := range secretTypes {
+		if v == t {
+			return k
+		}
+	}
+
+	return desc.SecretType_SECRET_TYPE_UNSPECIFIED
+}
+
+// toSecret converts the secret received from the client, its type is derived from the payload.
+func toSecret(s *desc.Secret) *models.Secret {
+	res := &models.Secret{SecretInfo: models.SecretInfo{
+		ID:   s.GetId(),
+		Name: s.GetName(),
+		Tags: s.GetTags(),
+	}}
+
+	switch p := s.GetPayload().(type) {
+	case *desc.Secret_Credential:
+		res.Type = models.SecretTypeCredential
+		res.Credential = &models.Credential{Login: p.Credential.GetLogin(), Password: p.Credential.GetPassword()}
+	case *desc.Secret_Card:
+		res.Type = models.SecretTypeCard
+		res.Card = &models.Card{Number: p.Card.GetCardNumber(), CVC: p.Card.GetCvc(), ExpDate: p.Card.GetExpirationDate()}
+	case *desc.Secret_Text:
+		res.Type = models.SecretTypeText
+		res.Text = &models.SecretText{Text: p.Text.GetText(), Compression: p.Text.GetCompression()}
+	case *desc.Secret_File:
+		res.Type = models.SecretTypeFile
+	case *desc.Secret_Custom:
+		res.Type = models.SecretTypeCustom
+		res.Custom = p.Custom.GetFields()
+		if res.Custom == nil {
+			res.Custom = map[string]string{}
+		}
+	}
+
+	return res
+}
+
+func fromSecret(s *models.Secret) *desc.Secret {
+	res := &desc.Secret{
+		Id:         s.ID,
+		Type:       fromSecretType(s.Type),
+		Name:       s.Name,
+		Tags:       s.Tags,
+		CreateTime: timestamppb.New(s.CreatedAt),
+		UpdateTime: timestamppb.New(s.UpdatedAt),
+		Version:    s.Version,
+		Etag:       s.ETag,
+	}
+
+	switch {
+	case s.Credential != nil:
+		res.Payload = &desc.Secret_Credential{Credential: &desc.Credential{
+			Login:    s.Credential.Login,
+			Password: s.Credential.Password,
+		}}
+	case s.Card != nil:
+		res.Payload = &desc.Secret_Card{Card: &desc.Card{
+			CardNumber:     s.Card.Number,
+			Cvc:            s.Card.CVC,
+			ExpirationDate: s.Card.ExpDate,
+		}}
+	case s.Text != nil:
+		res.Payload = &desc.Secret_Text{Text: &desc.Text{Text: s.Text.Text, Compression: s.Text.Compression}}
+	case s.File != nil:
+		res.Payload = &desc.Secret_File{File: &desc.FileRef{
+			Size:        uint64(s.File.Size),
+			Sha256:      s.File.Checksum,
+			Compression: s.File.Compression,
+		}}
+	case s.Custom != nil:
+		res.Payload = &desc.Secret_Custom{Custom: &desc.Custom{Fields: s.Custom}}
+	}
+
+	return res
+}
+
+// readPaths validates the read mask and returns its paths, payload fields are reported as payload.
+func readPaths(mask *fieldmaskpb.FieldMask) (map[string]bool, error) {
+	paths := make(map[string]bool, len(mask.GetPaths()))
+	fields := (&desc.Secret{}).ProtoReflect().Descriptor().Fields()
+
+	for _, path := range mask.GetPaths() {
+		if _, ok := payloadFields[path]; ok || path == secret.PathPayload {
+			paths[secret.PathPayload] = true
+
+			continue
+		}
+
+		if fields.ByName(protoreflect.Name(path)) == nil {
+			return nil, fmt.Errorf("unknown field in read mask: %s", path)
+		}
+		paths[path] = true
+	}
+
+	return paths, nil
+}
+
+// updatePaths validates the update mask and returns paths in terms of the secret service.
+func updatePaths(mask *fieldmaskpb.FieldMask) ([]string, error) {
+	paths := make([]string, 0, len(mask.GetPaths()))
+
+	for _, path := range mask.GetPaths() {
+		if _, ok := payloadFields[path]; ok {
+			path = secret.PathPayload
+		}
+
+		switch path {
+		case secret.PathName, secret.PathTags, secret.PathPayload:
+			paths = append(paths, path)
+		default:
+			return nil, fmt.Errorf("%w: %s", secret.ErrInvalidPath, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// applyReadMask clears fields of the secret, which are not in paths. Empty paths keep all fields.
+func applyReadMask(s *desc.Secret, paths map[string]bool) {
+	if len(paths) == 0 {
+		return
+	}
+
+	m := s.ProtoReflect()
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if oneof := fd.ContainingOneof(); oneof != nil && paths[string(oneof.Name())] {
+			continue
+		}
+
+		if !paths[string(fd.Name())] {
+			m.Clear(fd)
+		}
+	}
+}
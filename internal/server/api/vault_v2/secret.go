@@ -0,0 +1,114 @@
+package vault
+
+import (
+	"context"
+	"errors"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	secret "github.com/igortoigildin/goph-keeper/internal/server/service/secret"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	desc "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func (i *Implementation) CreateSecret(ctx context.Context, req *desc.CreateSecretRequest) (*desc.Secret, error) {
+	if req.GetSecret() == nil {
+		return nil, status.Error(codes.InvalidArgument, "secret is required")
+	}
+
+	res, err := i.secretService.Create(ctx, toSecret(req.GetSecret()))
+	if err != nil {
+		return nil, toStatus(err, "failed to create secret")
+	}
+
+	return fromSecret(res), nil
+}
+
+func (i *Implementation) GetSecret(ctx context.Context, req *desc.GetSecretRequest) (*desc.Secret, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	paths, err := readPaths(req.GetReadMask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := i.secretService.Get(ctx, req.GetId(), len(paths) == 0 || paths[secret.PathPayload])
+	if err != nil {
+		return nil, toStatus(err, "failed to get secret")
+	}
+
+	s := fromSecret(res)
+	applyReadMask(s, paths)
+
+	return s, nil
+}
+
+func (i *Implementation) UpdateSecret(ctx context.Context, req *desc.UpdateSecretRequest) (*desc.Secret, error) {
+	if req.GetSecret().GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "secret id is required")
+	}
+
+	paths, err := updatePaths(req.GetUpdateMask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res, err := i.secretService.Update(ctx, toSecret(req.GetSecret()), paths, req.GetVersion())
+	if err != nil {
+		return nil, toStatus(err, "failed to update secret")
+	}
+
+	return fromSecret(res), nil
+}
+
+func (i *Implementation) DeleteSecret(ctx context.Context, req *desc.DeleteSecretRequest) (*emptypb.Empty, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+
+	if err := i.secretService.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(err, "failed to delete secret")
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (i *Implementation) ListSecrets(ctx context.Context, req *desc.ListSecretsRequest) (*desc.ListSecretsResponse, error) {
+	paths, err := readPaths(req.GetReadMask())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	secrets, err := i.secretService.List(ctx, toFilter(req), paths[secret.PathPayload])
+	if err != nil {
+		return nil, toStatus(err, "failed to list secrets")
+	}
+
+	res := &desc.ListSecretsResponse{Secrets: make([]*desc.Secret, 0, len(secrets))}
+	for _, s := range secrets {
+		item := fromSecret(s)
+		applyReadMask(item, paths)
+		res.Secrets = append(res.Secrets, item)
+	}
+
+	return res, nil
+}
+
+func toFilter(req *desc.ListSecretsRequest) models.SecretFilter {
+	return models.SecretFilter{Type: toSecretType(req.GetType()), Tag: req.GetTag()}
+}
+
+// toStatus maps errors of the secret service to grpc status, msg is returned for unexpected errors.
+func toStatus(err error, msg string) error {
+	switch {
+	case errors.Is(err, compression.ErrUnsupported):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return apierror.Status(err, msg)
+	}
+}
@@ -0,0 +1,17 @@
+package vault
+
+import (
+	"github.com/igortoigildin/goph-keeper/internal/server/service"
+	desc "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+)
+
+type Implementation struct {
+	desc.UnimplementedVaultV2Server
+	secretService service.SecretService
+}
+
+func NewImplementation(secretService service.SecretService) *Implementation {
+	return &Implementation{
+		secretService: secretService,
+	}
+}
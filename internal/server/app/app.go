@@ -2,37 +2,60 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
-	"os"
+	"net/http"
+	"syscall"
+	"time"
 
+	"github.com/igortoigildin/goph-keeper/internal/server/audit"
 	"github.com/igortoigildin/goph-keeper/internal/server/closer"
 	config "github.com/igortoigildin/goph-keeper/internal/server/config"
+	"github.com/igortoigildin/goph-keeper/internal/server/gateway"
+	"github.com/igortoigildin/goph-keeper/internal/server/metrics"
+	accountpb "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	adminpb "github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+	auditpb "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
 	authpb "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	listpb "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	vaultpb "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
 	"go.uber.org/zap"
 
 	interceptors "github.com/igortoigildin/goph-keeper/pkg/interceptors"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
 const (
-	cfgFileName = ".env"
+	serviceName = "goph-keeper-server"
+
+	// readHeaderTimeout limits time to read request headers of the http gateway
+	readHeaderTimeout = 10 * time.Second
 )
 
+// ErrShutdown is returned by Run, when resources were not released cleanly on stop,
+// e.g. in-flight requests were aborted after shutdown timeout.
+var ErrShutdown = errors.New("unclean shutdown")
+
 type App struct {
+	config          *config.Config
 	serviceProvider *serviceProvider
 	grpcServer      *grpc.Server
+	httpServer      *http.Server
+	metricsServer   *http.Server
 }
 
-func NewApp(ctx context.Context) (*App, error) {
-	a := &App{}
+// NewApp initializes the server configured by cfg.
+func NewApp(ctx context.Context, cfg *config.Config) (*App, error) {
+	a := &App{config: cfg}
 
 	err := a.initDeps(ctx)
 	if err != nil {
@@ -42,20 +65,55 @@ func NewApp(ctx context.Context) (*App, error) {
 	return a, nil
 }
 
+// Run serves requests until SIGINT or SIGTERM is received or one of servers fails.
+// On stop health checks report NOT_SERVING, servers stop accepting new requests and wait for in-flight ones
+// up to shutdown timeout, then storage clients are closed. Resources are released in reverse order of creation.
 func (a *App) Run() error {
-	defer func() {
-		closer.CloseAll()
-		closer.Wait()
+	// added last, so load balancers are told first to stop sending requests
+	closer.Add(a.serviceProvider.HealthChecker(context.Background()).Shutdown)
+	closer.CloseOn(syscall.SIGINT, syscall.SIGTERM)
+
+	errCh := make(chan error, 3)
+
+	go func() {
+		errCh <- a.runGRPCServer()
+	}()
+
+	go func() {
+		errCh <- a.runHTTPServer()
 	}()
 
-	return a.runGRPCServer()
+	if a.metricsServer != nil {
+		go func() {
+			errCh <- a.runMetricsServer()
+		}()
+	}
+
+	// servers return once they are stopped by closer, or when they fail
+	err := <-errCh
+
+	closer.CloseAll()
+
+	if err != nil {
+		return err
+	}
+
+	if err := closer.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrShutdown, err)
+	}
+
+	logger.Info("server stopped")
+
+	return nil
 }
 
 func (a *App) initDeps(ctx context.Context) error {
 	inits := []func(context.Context) error{
-		a.initConfig,
 		a.initServiceProvider,
+		a.initTracing,
 		a.initGRPCServer,
+		a.initHTTPServer,
+		a.initMetricsServer,
 	}
 
 	for _, f := range inits {
@@ -68,32 +126,34 @@ func (a *App) initDeps(ctx context.Context) error {
 	return nil
 }
 
-func (a *App) initConfig(_ context.Context) error {
-	// Skip loading .env file in test mode
-	if os.Getenv("TEST_ENV") == "true" {
-		return nil
-	}
-
-	err := config.LoadFromFile(cfgFileName)
-	if err != nil {
-		return fmt.Errorf("error loading config from local file: %w", err)
-	}
-
-	return nil
-}
-
 func (a *App) initGRPCServer(ctx context.Context) error {
-	creds, err := credentials.NewServerTLSFromFile("certs/server.crt", "certs/server.key")
+	tlsCfg := a.config.GRPC.TLS
+
+	creds, err := serverCredentials(tlsCfg)
 	if err != nil {
 		logger.Error("failed to load TLS certificates: %w", zap.Error(err))
 
 		return fmt.Errorf("failed to load TLS certificates: %w", err)
 	}
 
+	auditWriter := a.serviceProvider.AuditWriter(ctx)
+	auth := interceptors.Auth{
+		JWTSecret:   a.config.Auth.JWTSecret,
+		Sessions:    a.serviceProvider.SessionCheck(ctx),
+		Tokens:      a.serviceProvider.TokenCheck(ctx),
+		RequireCert: tlsCfg.RequireClientCert,
+	}
+	if tlsCfg.ClientCAFile != "" {
+		auth.Certs = a.serviceProvider.CertCheck(ctx)
+	}
+
 	a.grpcServer = grpc.NewServer(
 		grpc.Creds(creds),
-		grpc.UnaryInterceptor(interceptors.JwtUnaryInterceptor()),
-		grpc.StreamInterceptor(interceptors.JwtStreamInterceptor()),
+		tracing.ServerOption(),
+		interceptors.UnaryChain(auth, metrics.UnaryServerInterceptor(), audit.UnaryServerInterceptor(auditWriter)),
+		interceptors.StreamChain(auth, metrics.StreamServerInterceptor(), audit.StreamServerInterceptor(auditWriter)),
+		// forced stop waits for handlers to return, so aborted uploads are cleaned up before storages are closed
+		grpc.WaitForHandlers(true),
 	)
 	reflection.Register(a.grpcServer)
 
@@ -101,6 +161,17 @@ func (a *App) initGRPCServer(ctx context.Context) error {
 	authpb.RegisterAuthV1Server(a.grpcServer, a.serviceProvider.AuthImpl(ctx))
 	downloadpb.RegisterDownloadV1Server(a.grpcServer, a.serviceProvider.DownloadImpl(ctx))
 	listpb.RegisterSyncV1Server(a.grpcServer, a.serviceProvider.ListImpl(ctx))
+	vaultpb.RegisterVaultV2Server(a.grpcServer, a.serviceProvider.VaultImpl(ctx))
+	auditpb.RegisterAuditV1Server(a.grpcServer, a.serviceProvider.AuditImpl(ctx))
+	accountpb.RegisterAccountV1Server(a.grpcServer, a.serviceProvider.AccountImpl(ctx))
+	adminpb.RegisterAdminV1Server(a.grpcServer, a.serviceProvider.AdminImpl(ctx))
+
+	// added after storage clients are created, so the server stops before they are closed
+	closer.Add(a.stopGRPCServer)
+
+	checker := a.serviceProvider.HealthChecker(ctx)
+	healthpb.RegisterHealthServer(a.grpcServer, checker.Server())
+	checker.Start(ctx)
 
 	return nil
 }
@@ -121,8 +192,141 @@ func (a *App) runGRPCServer() error {
 	return nil
 }
 
+// stopGRPCServer stops accepting new RPCs and waits for in-flight ones, including file streams, up to shutdown timeout.
+// RPCs still running then are canceled, which aborts partial uploads.
+func (a *App) stopGRPCServer() error {
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(a.config.ShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-timer.C:
+		logger.Warn("in-flight RPCs did not finish in time, aborting them", zap.Duration("timeout", a.config.ShutdownTimeout))
+
+		a.grpcServer.Stop()
+		<-stopped
+
+		return fmt.Errorf("grpc server did not stop in %s, in-flight RPCs were aborted", a.config.ShutdownTimeout)
+	}
+}
+
+// initHTTPServer initializes REST/JSON gateway, which forwards requests to the grpc server
+// over TLS connection, so they are authorized the same way as requests of grpc clients.
+func (a *App) initHTTPServer(ctx context.Context) error {
+	creds, err := credentials.NewClientTLSFromFile(a.config.GRPC.TLS.CertFile, "")
+	if err != nil {
+		logger.Error("failed to load TLS certificates: ", zap.Error(err))
+
+		return fmt.Errorf("failed to load TLS certificates: %w", err)
+	}
+
+	conn, err := grpc.NewClient(a.serviceProvider.GRPCConfig().Address(), grpc.WithTransportCredentials(creds), tracing.ClientOption())
+	if err != nil {
+		return fmt.Errorf("error creating gateway grpc client: %w", err)
+	}
+	closer.Add(conn.Close)
+
+	checker := a.serviceProvider.HealthChecker(ctx)
+
+	mux := http.NewServeMux()
+	mux.Handle("/", gateway.New(conn, a.config.Auth.JWTSecret).Handler())
+	mux.HandleFunc("GET /healthz", checker.Healthz)
+	mux.HandleFunc("GET /readyz", checker.Readyz)
+
+	a.httpServer = &http.Server{
+		Addr:              a.serviceProvider.HTTPConfig().Address(),
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	// the gateway stops before its connection to the grpc server is closed
+	closer.Add(func() error {
+		return a.shutdownHTTPServer(a.httpServer)
+	})
+
+	return nil
+}
+
+func (a *App) runHTTPServer() error {
+	logger.Info("HTTP gateway is running on:", zap.Any("address:", a.httpServer.Addr))
+
+	err := a.httpServer.ListenAndServeTLS(a.config.GRPC.TLS.CertFile, a.config.GRPC.TLS.KeyFile)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving http gateway: %w", err)
+	}
+
+	return nil
+}
+
+// initMetricsServer initializes http server exposing Prometheus metrics, if metrics are enabled.
+func (a *App) initMetricsServer(_ context.Context) error {
+	cfg := a.serviceProvider.MetricsConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET "+cfg.Path, metrics.Handler())
+
+	a.metricsServer = &http.Server{
+		Addr:              cfg.Address,
+		Handler:           mux,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	closer.Add(func() error {
+		return a.shutdownHTTPServer(a.metricsServer)
+	})
+
+	return nil
+}
+
+// shutdownHTTPServer stops accepting new requests and waits for in-flight ones up to shutdown timeout.
+func (a *App) shutdownHTTPServer(srv *http.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		srv.Close()
+
+		return fmt.Errorf("error stopping http server %s: %w", srv.Addr, err)
+	}
+
+	return nil
+}
+
+func (a *App) runMetricsServer() error {
+	logger.Info("Metrics are served on:", zap.String("address:", a.metricsServer.Addr), zap.String("path:", a.serviceProvider.MetricsConfig().Path))
+
+	err := a.metricsServer.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving metrics: %w", err)
+	}
+
+	return nil
+}
+
+// initTracing sets up export of spans, remaining spans are flushed on close.
+func (a *App) initTracing(ctx context.Context) error {
+	shutdown, err := tracing.Init(ctx, serviceName, a.serviceProvider.TracingConfig())
+	if err != nil {
+		return fmt.Errorf("error initializing tracing: %w", err)
+	}
+
+	closer.Add(func() error {
+		return shutdown(context.Background())
+	})
+
+	return nil
+}
+
 func (a *App) initServiceProvider(_ context.Context) error {
-	a.serviceProvider = newServiceProvider()
+	a.serviceProvider = newServiceProvider(a.config)
 
 	return nil
 }
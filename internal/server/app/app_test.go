@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("fatal")
+
+	os.Exit(m.Run())
+}
+
+func TestStopGRPCServer_Graceful(t *testing.T) {
+	a, client := startGRPCServer(t, time.Second)
+
+	_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, a.stopGRPCServer())
+
+	// new RPCs are not accepted once the server is stopped
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestStopGRPCServer_AbortsInFlightStreams(t *testing.T) {
+	a, client := startGRPCServer(t, 50*time.Millisecond)
+
+	// watch stream runs until it is canceled, as a long file stream would
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	start := time.Now()
+	err = a.stopGRPCServer()
+	require.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	_, err = stream.Recv()
+	assert.Error(t, err)
+}
+
+// startGRPCServer starts app grpc server with health service and returns the app with client of the service.
+func startGRPCServer(t *testing.T, shutdownTimeout time.Duration) (*App, healthpb.HealthClient) {
+	t.Helper()
+
+	a := &App{
+		config:     &config.Config{ShutdownTimeout: shutdownTimeout},
+		grpcServer: grpc.NewServer(grpc.WaitForHandlers(true)),
+	}
+	healthpb.RegisterHealthServer(a.grpcServer, health.NewServer())
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go a.grpcServer.Serve(lis)
+	t.Cleanup(a.grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return a, healthpb.NewHealthClient(conn)
+}
@@ -2,38 +2,79 @@ package app
 
 import (
 	"context"
-	"log"
+	"crypto/x509"
+	"time"
 
 	"github.com/igortoigildin/goph-keeper/internal/client/db"
 	"github.com/igortoigildin/goph-keeper/internal/client/db/pg"
+	accountApi "github.com/igortoigildin/goph-keeper/internal/server/api/account_v1"
+	adminApi "github.com/igortoigildin/goph-keeper/internal/server/api/admin_v1"
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	auditApi "github.com/igortoigildin/goph-keeper/internal/server/api/audit_v1"
 	auth "github.com/igortoigildin/goph-keeper/internal/server/api/auth_v1"
 	download "github.com/igortoigildin/goph-keeper/internal/server/api/download_v1"
 	api "github.com/igortoigildin/goph-keeper/internal/server/api/upload_v1"
+	"github.com/igortoigildin/goph-keeper/internal/server/audit"
 	"github.com/igortoigildin/goph-keeper/internal/server/closer"
+	"github.com/igortoigildin/goph-keeper/internal/server/health"
+	"github.com/igortoigildin/goph-keeper/internal/server/metrics"
 	service "github.com/igortoigildin/goph-keeper/internal/server/service"
+	"github.com/igortoigildin/goph-keeper/internal/server/tracing"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	tracingpkg "github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"go.uber.org/zap"
 
 	downloadApi "github.com/igortoigildin/goph-keeper/internal/server/api/download_v1"
 	listApi "github.com/igortoigildin/goph-keeper/internal/server/api/list_v1"
+	vaultApi "github.com/igortoigildin/goph-keeper/internal/server/api/vault_v2"
 	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	accountService "github.com/igortoigildin/goph-keeper/internal/server/service/account"
+	adminService "github.com/igortoigildin/goph-keeper/internal/server/service/admin"
+	auditService "github.com/igortoigildin/goph-keeper/internal/server/service/audit"
 	authService "github.com/igortoigildin/goph-keeper/internal/server/service/auth"
 	downloadService "github.com/igortoigildin/goph-keeper/internal/server/service/download"
 	listService "github.com/igortoigildin/goph-keeper/internal/server/service/list"
+	secretService "github.com/igortoigildin/goph-keeper/internal/server/service/secret"
 	uploadService "github.com/igortoigildin/goph-keeper/internal/server/service/upload"
 	repository "github.com/igortoigildin/goph-keeper/internal/server/storage"
 	dataRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/minio"
 	accessRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/access"
+	auditRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/audit"
+	blobRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/blob"
+	certRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/cert"
+	secretRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/secret"
+	tokenRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/token"
+	uploadRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/upload"
+	usageRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/usage"
 	userRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/user"
+	accountpb "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	adminpb "github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+	auditpb "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	authpb "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	listpb "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	vaultpb "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+)
+
+const (
+	// healthCheckInterval is how often availability of storages is checked
+	healthCheckInterval = 10 * time.Second
+
+	// names of checked dependencies
+	postgres = "postgres"
+	minio    = "minio"
 )
 
 type serviceProvider struct {
-	grpcConfig config.GRPCConfig
-	pgConfig   config.PGConfig
-	mainConfig *config.Config
+	config *config.Config
 
 	dbClient db.Client
 
+	healthChecker *health.Checker
+
 	uploadService service.UploadService
 	uploadImpl    *api.Implementation
 
@@ -46,41 +87,64 @@ type serviceProvider struct {
 	listService service.ListService
 	listImpl    *listApi.Implementation
 
+	secretService service.SecretService
+	vaultImpl     *vaultApi.Implementation
+
+	auditWriter  *audit.Writer
+	auditService service.AuditService
+	auditImpl    *auditApi.Implementation
+
+	accountService service.AccountService
+	accountImpl    *accountApi.Implementation
+
+	adminService service.AdminService
+	adminImpl    *adminApi.Implementation
+
 	userRepository   repository.UserRepository
+	tokenRepository  repository.TokenRepository
+	certRepository   repository.CertRepository
 	dataRepository   repository.DataRepository
-	accessRepository downloadService.AccessRepository
+	accessRepository repository.AccessRepository
+	uploadRepository uploadService.SessionRepository
+	blobRepository   repository.BlobRepository
+	secretRepository repository.SecretRepository
+	auditRepository  repository.AuditRepository
+	usageRepository  repository.UsageRepository
 }
 
-func newServiceProvider() *serviceProvider {
+func newServiceProvider(cfg *config.Config) *serviceProvider {
 	return &serviceProvider{
-		mainConfig: config.MustLoad(),
+		config: cfg,
 	}
 }
 
 func (s *serviceProvider) GRPCConfig() config.GRPCConfig {
-	if s.grpcConfig == nil {
-		cfg, err := config.NewGRPCConfig()
-		if err != nil {
-			log.Fatalf("failed to get grpc config: %s", err.Error())
-		}
+	return s.config.GRPC
+}
 
-		s.grpcConfig = cfg
-	}
+func (s *serviceProvider) HTTPConfig() config.HTTPConfig {
+	return s.config.HTTP
+}
 
-	return s.grpcConfig
+func (s *serviceProvider) MetricsConfig() config.MetricsConfig {
+	return s.config.Metrics
 }
 
-func (s *serviceProvider) PGConfig() config.PGConfig {
-	if s.pgConfig == nil {
-		cfg, err := config.NewPGConfig(s.mainConfig)
-		if err != nil {
-			logger.Fatal("failed to get pg config:", zap.Error(err))
-		}
+func (s *serviceProvider) TracingConfig() tracingpkg.Config {
+	return s.config.Tracing
+}
 
-		s.pgConfig = cfg
-	}
+func (s *serviceProvider) AuthConfig() config.AuthConfig {
+	return s.config.Auth
+}
 
-	return s.pgConfig
+// Quota returns limits of storage used by every user.
+func (s *serviceProvider) Quota() models.Quota {
+	return models.Quota{
+		MaxBytes:      s.config.Quota.MaxBytes,
+		MaxObjects:    s.config.Quota.MaxObjects,
+		MaxObjectSize: s.config.Quota.MaxObjectSize,
+	}
 }
 
 func (s *serviceProvider) UploadImpl(ctx context.Context) *api.Implementation {
@@ -93,7 +157,8 @@ func (s *serviceProvider) UploadImpl(ctx context.Context) *api.Implementation {
 
 func (s *serviceProvider) UploadService(ctx context.Context) service.UploadService {
 	if s.uploadService == nil {
-		s.uploadService = uploadService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx))
+		s.uploadService = uploadService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx), s.UploadRepository(ctx),
+			s.BlobRepository(ctx), s.UsageRepository(ctx), s.Quota())
 	}
 
 	return s.uploadService
@@ -101,11 +166,54 @@ func (s *serviceProvider) UploadService(ctx context.Context) service.UploadServi
 
 func (s *serviceProvider) AuthService(ctx context.Context) service.AuthService {
 	if s.authService == nil {
-		s.authService = authService.New(s.UserRepository(ctx))
+		cfg := s.AuthConfig()
+		s.authService = authService.New(s.UserRepository(ctx), s.TokenRepository(ctx), s.CertRepository(ctx),
+			cfg.JWTSecret, cfg.TokenTTL, cfg.APITokenMaxTTL, cfg.PasswordLogin, cfg.LoginAttempts)
 	}
 	return s.authService
 }
 
+// SessionCheck rejects access tokens of disabled users and tokens revoked by logging the user out.
+func (s *serviceProvider) SessionCheck(ctx context.Context) interceptors.SessionCheck {
+	authService := s.AuthService(ctx)
+
+	return func(ctx context.Context, login string, issuedAt time.Time) error {
+		if err := authService.CheckSession(ctx, login, issuedAt); err != nil {
+			return apierror.Status(err, "failed to check session")
+		}
+
+		return nil
+	}
+}
+
+// TokenCheck authenticates requests made with API tokens.
+func (s *serviceProvider) TokenCheck(ctx context.Context) interceptors.TokenCheck {
+	authService := s.AuthService(ctx)
+
+	return func(ctx context.Context, token string) (*models.UserClaims, error) {
+		claims, err := authService.AuthenticateToken(ctx, token)
+		if err != nil {
+			return nil, apierror.Status(err, "failed to check API token")
+		}
+
+		return claims, nil
+	}
+}
+
+// CertCheck authenticates requests by client certificates bound to users.
+func (s *serviceProvider) CertCheck(ctx context.Context) interceptors.CertCheck {
+	authService := s.AuthService(ctx)
+
+	return func(ctx context.Context, cert *x509.Certificate) (*models.UserClaims, error) {
+		claims, err := authService.AuthenticateCert(ctx, cert)
+		if err != nil {
+			return nil, apierror.Status(err, "failed to check client certificate")
+		}
+
+		return claims, nil
+	}
+}
+
 func (s *serviceProvider) AuthImpl(ctx context.Context) *auth.Implementation {
 	if s.authImpl == nil {
 		s.authImpl = auth.NewImplementation(s.AuthService(ctx))
@@ -116,7 +224,7 @@ func (s *serviceProvider) AuthImpl(ctx context.Context) *auth.Implementation {
 
 func (s *serviceProvider) DownloadService(ctx context.Context) service.DownloadService {
 	if s.downloadService == nil {
-		s.downloadService = downloadService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx))
+		s.downloadService = downloadService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx), s.BlobRepository(ctx))
 	}
 
 	return s.downloadService
@@ -132,7 +240,7 @@ func (s *serviceProvider) DownloadImpl(ctx context.Context) *download.Implementa
 
 func (s *serviceProvider) DBClient(ctx context.Context) db.Client {
 	if s.dbClient == nil {
-		cl, err := pg.New(ctx, s.PGConfig().DSN())
+		cl, err := pg.New(ctx, s.config.PG.DSN)
 		if err != nil {
 			logger.Fatal("failed to create db client:", zap.Error(err))
 		}
@@ -144,12 +252,40 @@ func (s *serviceProvider) DBClient(ctx context.Context) db.Client {
 
 		closer.Add(cl.Close)
 
-		s.dbClient = cl
+		s.dbClient = metrics.DBClient(tracing.DBClient(cl))
 	}
 
 	return s.dbClient
 }
 
+// HealthChecker returns checker of Postgres and Minio, grpc services are reported as serving
+// only while storages they use are available.
+func (s *serviceProvider) HealthChecker(ctx context.Context) *health.Checker {
+	if s.healthChecker == nil {
+		checker := health.New(healthCheckInterval)
+
+		checker.AddCheck(postgres, s.DBClient(ctx).DB().Ping)
+		checker.AddCheck(minio, s.DataRepository(ctx).Ping)
+
+		checker.AddService(authpb.AuthV1_ServiceDesc.ServiceName, postgres)
+		checker.AddService(auditpb.AuditV1_ServiceDesc.ServiceName, postgres)
+		checker.AddService(accountpb.AccountV1_ServiceDesc.ServiceName, postgres)
+		for _, name := range []string{
+			uploadpb.UploadV1_ServiceDesc.ServiceName,
+			downloadpb.DownloadV1_ServiceDesc.ServiceName,
+			listpb.SyncV1_ServiceDesc.ServiceName,
+			vaultpb.VaultV2_ServiceDesc.ServiceName,
+			adminpb.AdminV1_ServiceDesc.ServiceName,
+		} {
+			checker.AddService(name, postgres, minio)
+		}
+
+		s.healthChecker = checker
+	}
+
+	return s.healthChecker
+}
+
 func (s *serviceProvider) UserRepository(ctx context.Context) repository.UserRepository {
 	if s.userRepository == nil {
 		s.userRepository = userRepository.NewRepository(s.DBClient(ctx))
@@ -158,15 +294,31 @@ func (s *serviceProvider) UserRepository(ctx context.Context) repository.UserRep
 	return s.userRepository
 }
 
+func (s *serviceProvider) TokenRepository(ctx context.Context) repository.TokenRepository {
+	if s.tokenRepository == nil {
+		s.tokenRepository = tokenRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.tokenRepository
+}
+
+func (s *serviceProvider) CertRepository(ctx context.Context) repository.CertRepository {
+	if s.certRepository == nil {
+		s.certRepository = certRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.certRepository
+}
+
 func (s *serviceProvider) DataRepository(ctx context.Context) repository.DataRepository {
 	if s.dataRepository == nil {
-		s.dataRepository = dataRepository.NewRepository()
+		s.dataRepository = metrics.DataRepository(tracing.DataRepository(dataRepository.NewRepository(s.config.Minio)))
 	}
 
 	return s.dataRepository
 }
 
-func (s *serviceProvider) AccessRepository(ctx context.Context) downloadService.AccessRepository {
+func (s *serviceProvider) AccessRepository(ctx context.Context) repository.AccessRepository {
 	if s.accessRepository == nil {
 		s.accessRepository = accessRepository.NewRepository(s.DBClient(ctx))
 	}
@@ -174,6 +326,22 @@ func (s *serviceProvider) AccessRepository(ctx context.Context) downloadService.
 	return s.accessRepository
 }
 
+func (s *serviceProvider) BlobRepository(ctx context.Context) repository.BlobRepository {
+	if s.blobRepository == nil {
+		s.blobRepository = blobRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.blobRepository
+}
+
+func (s *serviceProvider) UploadRepository(ctx context.Context) uploadService.SessionRepository {
+	if s.uploadRepository == nil {
+		s.uploadRepository = uploadRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.uploadRepository
+}
+
 func (s *serviceProvider) ListImpl(ctx context.Context) *listApi.Implementation {
 	if s.listImpl == nil {
 		s.listImpl = listApi.NewImplementation(s.ListService(ctx))
@@ -184,8 +352,110 @@ func (s *serviceProvider) ListImpl(ctx context.Context) *listApi.Implementation
 
 func (s *serviceProvider) ListService(ctx context.Context) service.ListService {
 	if s.listService == nil {
-		s.listService = listService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx))
+		s.listService = listService.New(ctx, s.DataRepository(ctx), s.AccessRepository(ctx), s.BlobRepository(ctx))
 	}
 
 	return s.listService
 }
+
+func (s *serviceProvider) VaultImpl(ctx context.Context) *vaultApi.Implementation {
+	if s.vaultImpl == nil {
+		s.vaultImpl = vaultApi.NewImplementation(s.SecretService(ctx))
+	}
+
+	return s.vaultImpl
+}
+
+func (s *serviceProvider) SecretService(ctx context.Context) service.SecretService {
+	if s.secretService == nil {
+		s.secretService = secretService.New(ctx, s.UploadService(ctx), s.DownloadService(ctx), s.ListService(ctx),
+			s.AccessRepository(ctx), s.SecretRepository(ctx))
+	}
+
+	return s.secretService
+}
+
+func (s *serviceProvider) SecretRepository(ctx context.Context) repository.SecretRepository {
+	if s.secretRepository == nil {
+		s.secretRepository = secretRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.secretRepository
+}
+
+func (s *serviceProvider) AuditRepository(ctx context.Context) repository.AuditRepository {
+	if s.auditRepository == nil {
+		s.auditRepository = auditRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.auditRepository
+}
+
+// AuditWriter returns writer of the audit log, buffered events are saved on close before Postgres client is closed.
+func (s *serviceProvider) AuditWriter(ctx context.Context) *audit.Writer {
+	if s.auditWriter == nil {
+		cfg := s.config.Audit
+		s.auditWriter = audit.NewWriter(s.AuditRepository(ctx), cfg.BufferSize, cfg.FlushInterval)
+
+		closer.Add(s.auditWriter.Close)
+	}
+
+	return s.auditWriter
+}
+
+func (s *serviceProvider) AuditService(ctx context.Context) service.AuditService {
+	if s.auditService == nil {
+		s.auditService = auditService.New(s.AuditRepository(ctx), s.AdminService(ctx))
+	}
+
+	return s.auditService
+}
+
+func (s *serviceProvider) AuditImpl(ctx context.Context) *auditApi.Implementation {
+	if s.auditImpl == nil {
+		s.auditImpl = auditApi.NewImplementation(s.AuditService(ctx))
+	}
+
+	return s.auditImpl
+}
+
+func (s *serviceProvider) UsageRepository(ctx context.Context) repository.UsageRepository {
+	if s.usageRepository == nil {
+		s.usageRepository = usageRepository.NewRepository(s.DBClient(ctx))
+	}
+
+	return s.usageRepository
+}
+
+func (s *serviceProvider) AccountService(ctx context.Context) service.AccountService {
+	if s.accountService == nil {
+		s.accountService = accountService.New(s.UsageRepository(ctx), s.Quota())
+	}
+
+	return s.accountService
+}
+
+func (s *serviceProvider) AccountImpl(ctx context.Context) *accountApi.Implementation {
+	if s.accountImpl == nil {
+		s.accountImpl = accountApi.NewImplementation(s.AccountService(ctx))
+	}
+
+	return s.accountImpl
+}
+
+func (s *serviceProvider) AdminService(ctx context.Context) service.AdminService {
+	if s.adminService == nil {
+		s.adminService = adminService.New(s.UserRepository(ctx), s.DataRepository(ctx), s.CertRepository(ctx),
+			s.AuthConfig().ResetTokenTTL)
+	}
+
+	return s.adminService
+}
+
+func (s *serviceProvider) AdminImpl(ctx context.Context) *adminApi.Implementation {
+	if s.adminImpl == nil {
+		s.adminImpl = adminApi.NewImplementation(s.AdminService(ctx))
+	}
+
+	return s.adminImpl
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	"google.golang.org/grpc/credentials"
+)
+
+// serverCredentials returns TLS credentials of the grpc server. If client CA is configured, clients may present
+// certificates verified with it. Connections without certificate are accepted, so the http gateway keeps working,
+// and requests are rejected by JWT interceptors instead, when certificates are required.
+func serverCredentials(cfg config.TLSConfig) (credentials.TransportCredentials, error) {
+	if cfg.ClientCAFile == "" {
+		return credentials.NewServerTLSFromFile(cfg.CertFile, cfg.KeyFile)
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load server certificate: %w", err)
+	}
+
+	caPEM, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", cfg.ClientCAFile)
+	}
+
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+	}), nil
+}
@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	vaultpb "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// actions are audited RPCs by method name, RPCs of other methods are not recorded.
+var actions = map[string]models.AuditAction{
+	"/auth_v1.AuthV1/Login":       models.AuditActionLogin,
+	"/auth_v1.AuthV1/LoginFinish": models.AuditActionLogin,
+	"/auth_v1.AuthV1/Register":    models.AuditActionRegister,
+
+	"/upload_v1.UploadV1/UploadPassword": models.AuditActionUpload,
+	"/upload_v1.UploadV1/UploadText":     models.AuditActionUpload,
+	"/upload_v1.UploadV1/UploadFile":     models.AuditActionUpload,
+	"/upload_v1.UploadV1/UploadBankData": models.AuditActionUpload,
+	"/upload_v1.UploadV1/FinalizeUpload": models.AuditActionUpload,
+	"/upload_v1.UploadV1/CompleteUpload": models.AuditActionUpload,
+	"/upload_v1.UploadV1/DeleteFile":     models.AuditActionDelete,
+
+	"/download_v1.DownloadV1/DownloadPassword": models.AuditActionDownload,
+	"/download_v1.DownloadV1/DownloadText":     models.AuditActionDownload,
+	"/download_v1.DownloadV1/DownloadFile":     models.AuditActionDownload,
+	"/download_v1.DownloadV1/DownloadBankData": models.AuditActionDownload,
+	"/download_v1.DownloadV1/GetDownloadURL":   models.AuditActionDownload,
+
+	"/sync_v1.SyncV1/GetObjectList": models.AuditActionList,
+
+	"/vault_v2.VaultV2/CreateSecret": models.AuditActionUpload,
+	"/vault_v2.VaultV2/UpdateSecret": models.AuditActionUpload,
+	"/vault_v2.VaultV2/GetSecret":    models.AuditActionDownload,
+	"/vault_v2.VaultV2/ListSecrets":  models.AuditActionList,
+	"/vault_v2.VaultV2/DeleteSecret": models.AuditActionDelete,
+}
+
+// UnaryServerInterceptor records audited unary RPCs with their result.
+func UnaryServerInterceptor(w *Writer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		action, ok := actions[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx = interceptors.WithCaller(ctx)
+
+		res, err := handler(ctx, req)
+		w.Record(newEvent(ctx, action, info.FullMethod, err, req, res))
+
+		return res, err
+	}
+}
+
+// StreamServerInterceptor records audited streaming RPCs with their result,
+// the object is taken from metadata or from the first received message.
+func StreamServerInterceptor(w *Writer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		action, ok := actions[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		stream := &auditedStream{ServerStream: ss, ctx: interceptors.WithCaller(ss.Context())}
+
+		err := handler(srv, stream)
+		w.Record(newEvent(stream.ctx, action, info.FullMethod, err, stream.first))
+
+		return err
+	}
+}
+
+type auditedStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	first any
+}
+
+func (s *auditedStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *auditedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.first == nil {
+		s.first = m
+	}
+
+	return err
+}
+
+func newEvent(ctx context.Context, action models.AuditAction, method string, err error, msgs ...any) models.AuditEvent {
+	event := models.AuditEvent{
+		Time:      time.Now().UTC(),
+		Action:    action,
+		Method:    method,
+		RequestID: interceptors.RequestID(ctx),
+		Result:    status.Code(err).String(),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		event.Peer = p.Addr.String()
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	// actor is the authenticated user, login metadata of rejected requests is not trusted
+	event.Actor = interceptors.Caller(ctx)
+	event.ObjectID = first(md.Get("id"))
+
+	for _, msg := range msgs {
+		if event.Actor == "" {
+			event.Actor = loginOf(msg)
+		}
+
+		if event.ObjectID == "" {
+			event.ObjectID = objectOf(msg)
+		}
+	}
+
+	return event
+}
+
+// loginOf returns login passed in the message of login and registration requests.
+func loginOf(msg any) string {
+	if m, ok := msg.(interface{ GetLogin() string }); ok {
+		return strings.TrimSpace(m.GetLogin())
+	}
+
+	return ""
+}
+
+// objectOf returns id of the object the message refers to.
+func objectOf(msg any) string {
+	switch m := msg.(type) {
+	case interface{ GetUuid() string }:
+		return m.GetUuid()
+	case interface{ GetId() string }:
+		return m.GetId()
+	case interface{ GetSecret() *vaultpb.Secret }:
+		return m.GetSecret().GetId()
+	}
+
+	return ""
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxBatchSize is the most events saved in one statement
+	maxBatchSize = 100
+
+	// saveTimeout limits time of saving a batch of events
+	saveTimeout = 5 * time.Second
+)
+
+// Repository stores events of the audit log.
+type Repository interface {
+	SaveEvents(ctx context.Context, events []models.AuditEvent) error
+}
+
+// Writer saves events to the audit log asynchronously, so requests are not slowed down by it.
+// Events are buffered and saved in batches every flush interval or once a batch is full.
+type Writer struct {
+	repo          Repository
+	events        chan models.AuditEvent
+	flushInterval time.Duration
+
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWriter starts writer buffering up to bufferSize events.
+func NewWriter(repo Repository, bufferSize int, flushInterval time.Duration) *Writer {
+	w := &Writer{
+		repo:          repo,
+		events:        make(chan models.AuditEvent, bufferSize),
+		flushInterval: flushInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// Record queues the event for saving. The event is dropped, if the buffer is full or the writer is closed.
+func (w *Writer) Record(event models.AuditEvent) {
+	select {
+	case <-w.stop:
+		logger.Error("audit writer is closed, event dropped", zap.Any("event", event))
+	case w.events <- event:
+	default:
+		logger.Error("audit buffer is full, event dropped", zap.Any("event", event))
+	}
+}
+
+// Close saves buffered events and stops the writer.
+func (w *Writer) Close() error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+
+	<-w.done
+
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]models.AuditEvent, 0, maxBatchSize)
+
+	for {
+		select {
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) == maxBatchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+		case <-w.stop:
+			for {
+				select {
+				case event := <-w.events:
+					batch = append(batch, event)
+					if len(batch) == maxBatchSize {
+						batch = w.flush(batch)
+					}
+				default:
+					w.flush(batch)
+
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush saves the batch and returns it emptied.
+func (w *Writer) flush(batch []models.AuditEvent) []models.AuditEvent {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), saveTimeout)
+	defer cancel()
+
+	if err := w.repo.SaveEvents(ctx, batch); err != nil {
+		logger.Error("error saving audit events: ", zap.Error(err), zap.Int("count", len(batch)))
+	}
+
+	return batch[:0]
+}
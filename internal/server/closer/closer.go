@@ -1,6 +1,7 @@
 package closer
 
 import (
+	"errors"
 	"os"
 	"os/signal"
 	"sync"
@@ -26,12 +27,23 @@ func CloseAll() {
 	globalCloser.CloseAll()
 }
 
+// CloseOn makes globalCloser call CloseAll when one of signals is received from OS.
+func CloseOn(sig ...os.Signal) {
+	globalCloser.CloseOn(sig...)
+}
+
+// Err returns errors of globalCloser functions, once all of them are done.
+func Err() error {
+	return globalCloser.Err()
+}
+
 // Closer ...
 type Closer struct {
 	mu    sync.Mutex
 	once  sync.Once
 	done  chan struct{}
 	funcs []func() error
+	err   error
 }
 
 // New returns new Closer, if []os.Signal is specified Closer
@@ -39,17 +51,27 @@ type Closer struct {
 func New(sig ...os.Signal) *Closer {
 	c := &Closer{done: make(chan struct{})}
 	if len(sig) > 0 {
-		go func() {
-			ch := make(chan os.Signal, 1)
-			signal.Notify(ch, sig...)
-			<-ch
-			signal.Stop(ch)
-			c.CloseAll()
-		}()
+		c.CloseOn(sig...)
 	}
 	return c
 }
 
+// CloseOn calls CloseAll when one of signals is received from OS.
+func (c *Closer) CloseOn(sig ...os.Signal) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sig...)
+
+	go func() {
+		select {
+		case s := <-ch:
+			logger.Info("received signal, shutting down", zap.String("signal", s.String()))
+		case <-c.done:
+		}
+		signal.Stop(ch)
+		c.CloseAll()
+	}()
+}
+
 // Add func to closer
 func (c *Closer) Add(f ...func() error) {
 	c.mu.Lock()
@@ -62,7 +84,15 @@ func (c *Closer) Wait() {
 	<-c.done
 }
 
-// CloseAll calls all closer functions
+// Err returns errors of closer functions, it blocks until all of them are done.
+func (c *Closer) Err() error {
+	<-c.done
+
+	return c.err
+}
+
+// CloseAll calls all closer functions one by one in reverse order of adding,
+// so that resources are released before the resources they depend on.
 func (c *Closer) CloseAll() {
 	c.once.Do(func() {
 		defer close(c.done)
@@ -72,18 +102,15 @@ func (c *Closer) CloseAll() {
 		c.funcs = nil
 		c.mu.Unlock()
 
-		// call all Closer funcs async
-		errs := make(chan error, len(funcs))
-		for _, f := range funcs {
-			go func(f func() error) {
-				errs <- f()
-			}(f)
-		}
-
-		for i := 0; i < cap(errs); i++ {
-			if err := <-errs; err != nil {
+		var errs []error
+		for i := len(funcs) - 1; i >= 0; i-- {
+			if err := funcs[i](); err != nil {
 				logger.Error("error returned from Closer", zap.Error(err))
+
+				errs = append(errs, err)
 			}
 		}
+
+		c.err = errors.Join(errs...)
 	})
 }
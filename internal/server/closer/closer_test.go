@@ -0,0 +1,118 @@
+package closer
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.Initialize("fatal")
+
+	os.Exit(m.Run())
+}
+
+func TestCloser_ClosesInReverseOrder(t *testing.T) {
+	c := New()
+
+	var order []string
+	for _, name := range []string{"db", "storage", "grpc server", "health"} {
+		c.Add(func() error {
+			order = append(order, name)
+
+			return nil
+		})
+	}
+
+	c.CloseAll()
+
+	assert.Equal(t, []string{"health", "grpc server", "storage", "db"}, order)
+	assert.NoError(t, c.Err())
+}
+
+func TestCloser_JoinsErrors(t *testing.T) {
+	c := New()
+
+	errDB, errServer := errors.New("db"), errors.New("server")
+
+	closed := 0
+	c.Add(
+		func() error { closed++; return errDB },
+		func() error { closed++; return nil },
+		func() error { closed++; return errServer },
+	)
+
+	c.CloseAll()
+
+	// a failed function does not stop the rest
+	assert.Equal(t, 3, closed)
+
+	err := c.Err()
+	assert.ErrorIs(t, err, errDB)
+	assert.ErrorIs(t, err, errServer)
+}
+
+func TestCloser_ClosesOnce(t *testing.T) {
+	c := New()
+
+	closed := 0
+	c.Add(func() error { closed++; return nil })
+
+	c.CloseAll()
+	c.CloseAll()
+	c.Wait()
+
+	assert.Equal(t, 1, closed)
+}
+
+func TestCloser_ErrWaitsForClose(t *testing.T) {
+	c := New()
+
+	errClose := errors.New("close")
+	release := make(chan struct{})
+	c.Add(func() error {
+		<-release
+
+		return errClose
+	})
+
+	go c.CloseAll()
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- c.Err() }()
+
+	select {
+	case <-errCh:
+		t.Fatal("Err returned before closer functions are done")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.ErrorIs(t, <-errCh, errClose)
+}
+
+func TestCloser_ClosesOnSignal(t *testing.T) {
+	c := New(syscall.SIGUSR1)
+
+	closed := make(chan struct{})
+	c.Add(func() error {
+		close(closed)
+
+		return nil
+	})
+
+	require.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("closer functions were not called on signal")
+	}
+	c.Wait()
+}
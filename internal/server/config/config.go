@@ -1,56 +1,271 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
 	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap/zapcore"
 )
 
-func LoadFromFile(path string) error {
-	err := godotenv.Load(path)
-	if err != nil {
-		return err
-	}
+const (
+	// DefaultPath is the config file used, when neither --config flag nor CONFIG_PATH is set.
+	DefaultPath = "config/local_tests.yaml"
 
-	return nil
-}
+	configPathEnvName = "CONFIG_PATH"
+	envFileName       = ".env"
+)
+
+// ErrInvalid is returned by Load, when the config can not be read or is not valid.
+var ErrInvalid = errors.New("invalid config")
 
+// Config is the configuration of the server. Settings are taken from defaults, the config file,
+// environment variables and command line flags, each source overrides the previous ones.
 type Config struct {
-	FlagLogLevel   string
-	Env            string        `yaml:"env" env:"ENV" env-default:"local"`
-	StoragePath    string        `yaml:"storage_path" env:"STORAGE_PATH" env-default:"./storage/goph.db"`
-	GRPC           GrpcConfig    `yaml:"grpc"`
-	Timeout        time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"15s"`
-	MigrationsPath string
-	TokenTTL       time.Duration `yaml:"token_ttl" env-default:"1h"`
-	Key            string        `yaml:"key" env:"ENCRYPTION_KEY" env-default:"test_encryption_key"`
-	PG             struct {
-		DSN            string `yaml:"dsn" env:"PG_DSN"`
-		MigrationsPath string `yaml:"migrations_path" env:"PG_MIGRATIONS_PATH"`
-	} `yaml:"pg"`
+	Env     string        `yaml:"env" env:"ENV" env-default:"local"`
+	Timeout time.Duration `yaml:"timeout" env:"TIMEOUT" env-default:"15s"`
+	// ShutdownTimeout is how long in-flight requests may run after the server is asked to stop.
+	ShutdownTimeout time.Duration  `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" env-default:"30s"`
+	Log             LogConfig      `yaml:"log"`
+	GRPC            GRPCConfig     `yaml:"grpc"`
+	HTTP            HTTPConfig     `yaml:"http"`
+	PG              PGConfig       `yaml:"pg"`
+	Minio           MinioConfig    `yaml:"minio"`
+	Auth            AuthConfig     `yaml:"auth"`
+	Metrics         MetricsConfig  `yaml:"metrics"`
+	Tracing         tracing.Config `yaml:"tracing"`
+	Audit           AuditConfig    `yaml:"audit"`
+	Quota           QuotaConfig    `yaml:"quota"`
+}
+
+// LogConfig configures the logger.
+type LogConfig struct {
+	Level string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+}
+
+// PGConfig configures connection to Postgres.
+type PGConfig struct {
+	DSN            string `yaml:"dsn" env:"PG_DSN"`
+	MigrationsPath string `yaml:"migrations_path" env:"PG_MIGRATIONS_PATH" env-default:"./migrations"`
+}
+
+// MinioConfig configures connection to Minio, which stores the data of users.
+type MinioConfig struct {
+	Endpoint  string `yaml:"endpoint" env:"MINIO_ENDPOINT" env-default:"localhost:9000"`
+	AccessKey string `yaml:"access_key" env:"MINIO_ACCESS_KEY" env-default:"minioaccesskey"`
+	SecretKey string `yaml:"secret_key" env:"MINIO_SECRET_KEY" env-default:"miniosecretkey"`
+	UseSSL    bool   `yaml:"use_ssl" env:"MINIO_USE_SSL" env-default:"false"`
+}
+
+// AuthConfig configures issuing and verification of access tokens.
+type AuthConfig struct {
+	// JWTSecret is base64 encoded key signing access tokens.
+	JWTSecret string        `yaml:"jwt_secret" env:"JWT_SECRET"`
+	TokenTTL  time.Duration `yaml:"token_ttl" env:"TOKEN_TTL" env-default:"1h"`
+	// ResetTokenTTL is how long password reset tokens issued by administrators are valid.
+	ResetTokenTTL time.Duration `yaml:"reset_token_ttl" env:"RESET_TOKEN_TTL" env-default:"24h"`
+	// APITokenMaxTTL limits how long API tokens created by users are valid.
+	APITokenMaxTTL time.Duration `yaml:"api_token_max_ttl" env:"API_TOKEN_MAX_TTL" env-default:"8760h"`
+	// PasswordLogin allows users with SRP verifier to log in sending plaintext password, e.g. through REST gateway.
+	// Users registered before SRP was introduced always log in with password once to migrate to verifier.
+	PasswordLogin bool `yaml:"password_login" env:"PASSWORD_LOGIN" env-default:"true"`
+	// LoginAttempts limits login attempts per user a minute on each server instance, zero disables the limit.
+	LoginAttempts int `yaml:"login_attempts" env:"LOGIN_ATTEMPTS" env-default:"10"`
+}
+
+// MetricsConfig configures http endpoint exposing Prometheus metrics.
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" env:"METRICS_ENABLED" env-default:"true"`
+	Address string `yaml:"address" env:"METRICS_ADDRESS" env-default:":9100"`
+	Path    string `yaml:"path" env:"METRICS_PATH" env-default:"/metrics"`
+}
+
+// AuditConfig configures writing of the audit log.
+type AuditConfig struct {
+	BufferSize    int           `yaml:"buffer_size" env:"AUDIT_BUFFER_SIZE" env-default:"1024"` // events waiting to be saved
+	FlushInterval time.Duration `yaml:"flush_interval" env:"AUDIT_FLUSH_INTERVAL" env-default:"1s"`
 }
 
-func MustLoad() *Config {
-	return MustLoadPath("config/local_tests.yaml")
+// QuotaConfig limits storage used by every user, zero settings are not limited.
+type QuotaConfig struct {
+	MaxBytes      int64 `yaml:"max_bytes" env:"QUOTA_MAX_BYTES"`             // total size of saved data
+	MaxObjects    int64 `yaml:"max_objects" env:"QUOTA_MAX_OBJECTS"`         // number of saved secrets and files
+	MaxObjectSize int64 `yaml:"max_object_size" env:"QUOTA_MAX_OBJECT_SIZE"` // size of a single secret or file
+}
+
+// flagOverrides are command line flags overriding settings, by name of the flag.
+var flagOverrides = map[string]struct {
+	usage   string
+	setting func(cfg *Config) *string
+}{
+	"log-level":      {"logger level", func(cfg *Config) *string { return &cfg.Log.Level }},
+	"grpc-host":      {"host of the grpc server", func(cfg *Config) *string { return &cfg.GRPC.Host }},
+	"grpc-port":      {"port of the grpc server", func(cfg *Config) *string { return &cfg.GRPC.Port }},
+	"http-host":      {"host of the http gateway", func(cfg *Config) *string { return &cfg.HTTP.Host }},
+	"http-port":      {"port of the http gateway", func(cfg *Config) *string { return &cfg.HTTP.Port }},
+	"pg-dsn":         {"Postgres connection string", func(cfg *Config) *string { return &cfg.PG.DSN }},
+	"minio-endpoint": {"address of Minio", func(cfg *Config) *string { return &cfg.Minio.Endpoint }},
+}
+
+// RegisterFlags defines --config flag and flags overriding settings of the config file and environment.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.String("config", "", "path to config file, CONFIG_PATH or "+DefaultPath+" by default")
+
+	for name, o := range flagOverrides {
+		fs.String(name, "", o.usage)
+	}
+}
+
+// Load reads the config from the file given by --config flag, applies environment variables and flags
+// set in fs, which may be nil, and validates the result. Variables of .env file are loaded into
+// environment beforehand, unless the server runs in test environment.
+func Load(fs *pflag.FlagSet) (*Config, error) {
+	if os.Getenv("TEST_ENV") != "true" {
+		if err := godotenv.Load(envFileName); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("error loading %s: %w", envFileName, err)
+		}
+	}
+
+	path := os.Getenv(configPathEnvName)
+	if fs != nil {
+		if f := fs.Lookup("config"); f != nil && f.Changed {
+			path = f.Value.String()
+		}
+	}
+
+	if path == "" {
+		path = DefaultPath
+	}
+
+	cfg, err := read(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	if fs != nil {
+		fs.Visit(func(f *pflag.Flag) {
+			if o, ok := flagOverrides[f.Name]; ok {
+				*o.setting(cfg) = f.Value.String()
+			}
+		})
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w %s: %w", ErrInvalid, path, err)
+	}
+
+	return cfg, nil
 }
 
+// MustLoadPath reads the config file at path and environment variables without validation,
+// tests use it to find the server.
 func MustLoadPath(path string) *Config {
-	// check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		panic("config file does not exist: " + path)
+	cfg, err := read(path)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return cfg
+}
+
+func read(path string) (*Config, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("config file does not exist: %s", path)
 	}
 
 	var cfg Config
 
 	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
-		panic("failed to load config: " + err.Error())
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// Validate checks that settings required to run the server are set and well formed,
+// all problems are reported at once.
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	_, err := zapcore.ParseLevel(cfg.Log.Level)
+	check(err == nil, "log.level: unknown level %q (LOG_LEVEL)", cfg.Log.Level)
+
+	check(validPort(cfg.GRPC.Port), "grpc.port: invalid port %q (GRPC_PORT)", cfg.GRPC.Port)
+	check(fileExists(cfg.GRPC.TLS.CertFile), "grpc.tls.cert_file: file %q not found (TLS_CERT_FILE)", cfg.GRPC.TLS.CertFile)
+	check(fileExists(cfg.GRPC.TLS.KeyFile), "grpc.tls.key_file: file %q not found (TLS_KEY_FILE)", cfg.GRPC.TLS.KeyFile)
+	if cfg.GRPC.TLS.ClientCAFile != "" {
+		check(fileExists(cfg.GRPC.TLS.ClientCAFile), "grpc.tls.client_ca_file: file %q not found (TLS_CLIENT_CA_FILE)",
+			cfg.GRPC.TLS.ClientCAFile)
 	}
+	check(!cfg.GRPC.TLS.RequireClientCert || cfg.GRPC.TLS.ClientCAFile != "",
+		"grpc.tls.require_client_cert: client_ca_file must be set (TLS_CLIENT_CA_FILE)")
 
-	if envLogLevel := os.Getenv("LOG_LEVEL"); envLogLevel != "" {
-		cfg.FlagLogLevel = envLogLevel
+	check(validPort(cfg.HTTP.Port), "http.port: invalid port %q (HTTP_PORT)", cfg.HTTP.Port)
+	check(cfg.HTTP.Address() != cfg.GRPC.Address(), "http: address %s is used by grpc server", cfg.HTTP.Address())
+
+	check(cfg.PG.DSN != "", "pg.dsn: must be set (PG_DSN)")
+
+	check(cfg.Minio.Endpoint != "", "minio.endpoint: must be set (MINIO_ENDPOINT)")
+	check(cfg.Minio.AccessKey != "" && cfg.Minio.SecretKey != "",
+		"minio: access_key and secret_key must be set (MINIO_ACCESS_KEY, MINIO_SECRET_KEY)")
+
+	check(cfg.Auth.JWTSecret != "", "auth.jwt_secret: must be set (JWT_SECRET)")
+	if cfg.Auth.JWTSecret != "" {
+		_, err = base64.StdEncoding.DecodeString(cfg.Auth.JWTSecret)
+		check(err == nil, "auth.jwt_secret: must be base64 encoded (JWT_SECRET)")
 	}
+	check(cfg.Auth.TokenTTL > 0, "auth.token_ttl: must be positive (TOKEN_TTL)")
+	check(cfg.Auth.ResetTokenTTL > 0, "auth.reset_token_ttl: must be positive (RESET_TOKEN_TTL)")
+	check(cfg.Auth.APITokenMaxTTL > 0, "auth.api_token_max_ttl: must be positive (API_TOKEN_MAX_TTL)")
+
+	check(cfg.Timeout > 0, "timeout: must be positive (TIMEOUT)")
+	check(cfg.ShutdownTimeout > 0, "shutdown_timeout: must be positive (SHUTDOWN_TIMEOUT)")
+
+	if cfg.Metrics.Enabled {
+		check(strings.HasPrefix(cfg.Metrics.Path, "/"), "metrics.path: must start with / (METRICS_PATH)")
+	}
+
+	check(cfg.Tracing.SampleRatio >= 0 && cfg.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio: must be between 0 and 1 (TRACING_SAMPLE_RATIO)")
+
+	check(cfg.Audit.BufferSize > 0, "audit.buffer_size: must be positive (AUDIT_BUFFER_SIZE)")
+	check(cfg.Audit.FlushInterval > 0, "audit.flush_interval: must be positive (AUDIT_FLUSH_INTERVAL)")
+
+	check(cfg.Quota.MaxBytes >= 0, "quota.max_bytes: must not be negative (QUOTA_MAX_BYTES)")
+	check(cfg.Quota.MaxObjects >= 0, "quota.max_objects: must not be negative (QUOTA_MAX_OBJECTS)")
+	check(cfg.Quota.MaxObjectSize >= 0, "quota.max_object_size: must not be negative (QUOTA_MAX_OBJECT_SIZE)")
+
+	return errors.Join(errs...)
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+
+	return err == nil && n > 0 && n <= 65535
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+
+	return err == nil && !info.IsDir()
+}
 
-	return &cfg
+// address joins host and port, empty host stands for all interfaces.
+func address(host, port string) string {
+	return net.JoinHostPort(host, port)
 }
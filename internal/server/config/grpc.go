@@ -1,55 +1,23 @@
 package config
 
-import (
-	"errors"
-	"net"
-	"os"
-)
-
-const (
-	grpcHostEnvName    = "GRPC_HOST"
-	grpcPortEnvName    = "GRPC_PORT"
-	loggerLevelEnvName = "LOGGER_LEVEL"
-)
-
-type GRPCConfig interface {
-	Address() string
-	GetTLS() TLSConfig
-}
-
-type TLSConfig struct {
-	Enabled  bool   `yaml:"enabled"`
-	CertFile string `yaml:"cert_file"`
-	KeyFile  string `yaml:"key_file"`
-}
-
-type GrpcConfig struct {
-	Host string
-	Port string    `yaml:"port"`
+// GRPCConfig configures the grpc server.
+type GRPCConfig struct {
+	Host string    `yaml:"host" env:"GRPC_HOST"`
+	Port string    `yaml:"port" env:"GRPC_PORT" env-default:"9000"`
 	TLS  TLSConfig `yaml:"tls"`
 }
 
-func NewGRPCConfig() (GRPCConfig, error) {
-	host := os.Getenv(grpcHostEnvName)
-	if len(host) == 0 {
-		return nil, errors.New("grpc host not found")
-	}
-
-	port := os.Getenv(grpcPortEnvName)
-	if len(port) == 0 {
-		return nil, errors.New("grpc port not found")
-	}
-
-	return &GrpcConfig{
-		Host: host,
-		Port: port,
-	}, nil
-}
-
-func (cfg *GrpcConfig) Address() string {
-	return net.JoinHostPort(cfg.Host, cfg.Port)
+// TLSConfig configures certificate of the grpc server and the http gateway, clients always connect over TLS.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" env:"TLS_CERT_FILE" env-default:"certs/server.crt"`
+	KeyFile  string `yaml:"key_file" env:"TLS_KEY_FILE" env-default:"certs/server.key"`
+	// ClientCAFile enables client certificates of the grpc server, which are verified with CA certificates
+	// in the file and authenticate users they are bound to.
+	ClientCAFile string `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE"`
+	// RequireClientCert requires client certificate bound to the user together with token of the user.
+	RequireClientCert bool `yaml:"require_client_cert" env:"TLS_REQUIRE_CLIENT_CERT" env-default:"false"`
 }
 
-func (cfg *GrpcConfig) GetTLS() TLSConfig {
-	return cfg.TLS
+func (cfg GRPCConfig) Address() string {
+	return address(cfg.Host, cfg.Port)
 }
@@ -0,0 +1,11 @@
+package config
+
+// HTTPConfig configures the REST/JSON gateway.
+type HTTPConfig struct {
+	Host string `yaml:"host" env:"HTTP_HOST"`
+	Port string `yaml:"port" env:"HTTP_PORT" env-default:"8080"`
+}
+
+func (cfg HTTPConfig) Address() string {
+	return address(cfg.Host, cfg.Port)
+}
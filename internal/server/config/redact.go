@@ -0,0 +1,41 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+)
+
+// redacted replaces secrets in printed config.
+const redacted = "REDACTED"
+
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// Redacted returns copy of the config with secrets replaced, so that it can be printed or logged.
+func (cfg Config) Redacted() Config {
+	cfg.PG.DSN = redactDSN(cfg.PG.DSN)
+	cfg.Minio.SecretKey = redactValue(cfg.Minio.SecretKey)
+	cfg.Auth.JWTSecret = redactValue(cfg.Auth.JWTSecret)
+
+	return cfg
+}
+
+func redactValue(v string) string {
+	if v == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// redactDSN hides password of Postgres connection string given either as URL or as key=value pairs.
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+
+		return u.String()
+	}
+
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
@@ -0,0 +1,186 @@
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// chunkSize is the size of file chunks sent to upload stream.
+const chunkSize = 1024 * 1024
+
+// uploadFile streams request body to the upload stream, so the file is not buffered by the gateway.
+// Attributes of the file are passed as query parameters and are sent in the first message.
+func (g *Gateway) uploadFile(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	stream, err := g.uploadClient.UploadFile(ctx)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	query := r.URL.Query()
+	req := &uploadpb.UploadFileRequest{
+		FileName:    query.Get("file_name"),
+		Metadata:    query.Get("metadata"),
+		Compression: query.Get("compression"),
+		Sha256:      query.Get("sha256"),
+	}
+
+	buf := make([]byte, chunkSize)
+	for first := true; ; first = false {
+		num, readErr := io.ReadFull(r.Body, buf)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			logger.Error("error reading request body: ", zap.Error(readErr))
+
+			_ = stream.CloseSend()
+			writeError(w, status.Error(codes.InvalidArgument, "failed to read request body"))
+
+			return
+		}
+
+		// the first message is sent even for empty file, so the server receives its attributes
+		if num > 0 || first {
+			req.Chunk = buf[:num]
+			if err := stream.Send(req); err != nil {
+				// actual error is returned by CloseAndRecv
+				break
+			}
+			req = &uploadpb.UploadFileRequest{}
+		}
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+// downloadFile writes chunks of the download stream as response body. Attributes of the file
+// are sent in headers, so they are read from the first message before the body is written.
+func (g *Gateway) downloadFile(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	offset, length, partial, err := parseRange(r.Header.Get("Range"))
+	if err != nil {
+		writeError(w, status.Error(codes.OutOfRange, err.Error()))
+
+		return
+	}
+
+	stream, err := g.downloadClient.DownloadFile(ctx, &downloadpb.DownloadFileRequest{
+		Uuid:   r.PathValue("id"),
+		Offset: offset,
+		Length: length,
+	})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	size := first.GetSize()
+	end := size
+	if length > 0 {
+		end = min(offset+length, size)
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("Content-Length", strconv.FormatUint(end-offset, 10))
+	header.Set("X-File-Metadata", url.QueryEscape(first.GetMetadata()))
+	header.Set("X-File-Sha256", first.GetSha256())
+	header.Set("X-File-Compression", first.GetCompression())
+
+	if partial {
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, end-1, size))
+		w.WriteHeader(http.StatusPartialContent)
+	}
+
+	for res := first; ; {
+		if _, err := w.Write(res.GetChunk()); err != nil {
+			logger.Error("error writing response body: ", zap.Error(err))
+
+			return
+		}
+
+		res, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+
+		if err != nil {
+			// headers are already sent, the client sees body shorter than Content-Length
+			logger.Error("error receiving file chunk: ", zap.Error(err))
+
+			return
+		}
+	}
+}
+
+// parseRange parses Range header with a single byte range. Suffix ranges are not supported.
+func parseRange(value string) (offset, length uint64, partial bool, err error) {
+	if value == "" {
+		return 0, 0, false, nil
+	}
+
+	spec, ok := strings.CutPrefix(value, "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, false, fmt.Errorf("unsupported range: %s", value)
+	}
+
+	startStr, endStr, _ := strings.Cut(spec, "-")
+
+	offset, err = strconv.ParseUint(startStr, 10, 64)
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("invalid range: %s", value)
+	}
+
+	if endStr != "" {
+		end, err := strconv.ParseUint(endStr, 10, 64)
+		if err != nil || end < offset {
+			return 0, 0, false, fmt.Errorf("invalid range: %s", value)
+		}
+		length = end - offset + 1
+	}
+
+	return offset, length, true, nil
+}
@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	authpb "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	listpb "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	jwt "github.com/igortoigildin/goph-keeper/pkg/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxMessageSize limits JSON request bodies, files are streamed and not limited.
+const maxMessageSize = 4 * 1024 * 1024
+
+var errUnauthenticated = status.Error(codes.Unauthenticated, "authorization token not provided")
+
+// Gateway serves REST/JSON api by forwarding requests to grpc services, so they pass
+// the same interceptors and JWT authorization as requests of grpc clients.
+type Gateway struct {
+	authClient     authpb.AuthV1Client
+	uploadClient   uploadpb.UploadV1Client
+	downloadClient downloadpb.DownloadV1Client
+	listClient     listpb.SyncV1Client
+	jwtSecret      string
+}
+
+// New returns gateway forwarding requests over conn, access tokens of requests are verified with jwtSecret.
+func New(conn grpc.ClientConnInterface, jwtSecret string) *Gateway {
+	return &Gateway{
+		jwtSecret:      jwtSecret,
+		authClient:     authpb.NewAuthV1Client(conn),
+		uploadClient:   uploadpb.NewUploadV1Client(conn),
+		downloadClient: downloadpb.NewDownloadV1Client(conn),
+		listClient:     listpb.NewSyncV1Client(conn),
+	}
+}
+
+// Handler returns handler serving all routes of the gateway and its OpenAPI spec.
+func (g *Gateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, r := range g.routes() {
+		mux.HandleFunc(r.method+" "+r.path, r.handler)
+	}
+
+	mux.HandleFunc("GET /openapi.json", g.openAPI)
+
+	return mux
+}
+
+// outgoingContext returns context with grpc metadata for the request. Login of the user is taken
+// from the bearer token, id of the item is taken from the path. Owner of api token is set by the server.
+func (g *Gateway) outgoingContext(r *http.Request) (context.Context, error) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return nil, errUnauthenticated
+	}
+
+	md := metadata.Pairs("authorization", "Bearer "+token)
+
+	if !strings.HasPrefix(token, models.APITokenPrefix) {
+		claims, err := jwt.VeryfyToken(token, []byte(g.jwtSecret))
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		md.Set("login", claims.Login)
+	}
+
+	if id := r.PathValue("id"); id != "" {
+		md.Set("id", id)
+	}
+	if requestID := r.Header.Get(interceptors.RequestIDKey); requestID != "" {
+		md.Set(interceptors.RequestIDKey, requestID)
+	}
+
+	return metadata.NewOutgoingContext(r.Context(), md), nil
+}
+
+// readMessage decodes JSON body of the request into msg.
+func readMessage(r *http.Request, msg proto.Message) error {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "failed to read request body")
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		return status.Error(codes.InvalidArgument, "invalid request body: "+err.Error())
+	}
+
+	return nil
+}
+
+// writeMessage encodes msg as JSON body of the response.
+func writeMessage(w http.ResponseWriter, msg proto.Message) {
+	body, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		logger.Error("error encoding response: ", zap.Error(err))
+
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// writeError writes grpc status of the error as JSON body with matching http status.
+func writeError(w http.ResponseWriter, err error) {
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.New(codes.Unknown, err.Error())
+	}
+
+	body, _ := protojson.Marshal(st.Proto())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus(st.Code()))
+	_, _ = w.Write(body)
+}
+
+// httpStatus maps grpc code to http status, as grpc-gateway does.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.OutOfRange:
+		return http.StatusRequestedRangeNotSatisfiable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,247 @@
+package gateway
+
+import (
+	"net/http"
+
+	authpb "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	listpb "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+)
+
+func (g *Gateway) register(w http.ResponseWriter, r *http.Request) {
+	req := &authpb.RegisterRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.authClient.Register(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) login(w http.ResponseWriter, r *http.Request) {
+	req := &authpb.LoginRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.authClient.Login(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) loginStart(w http.ResponseWriter, r *http.Request) {
+	req := &authpb.LoginStartRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.authClient.LoginStart(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) loginFinish(w http.ResponseWriter, r *http.Request) {
+	req := &authpb.LoginFinishRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.authClient.LoginFinish(r.Context(), req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) uploadPassword(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	req := &uploadpb.UploadPasswordRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.uploadClient.UploadPassword(ctx, req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) downloadPassword(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.downloadClient.DownloadPassword(ctx, &downloadpb.DownloadPasswordRequest{Uuid: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) uploadText(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	req := &uploadpb.UploadTextRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.uploadClient.UploadText(ctx, req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) downloadText(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.downloadClient.DownloadText(ctx, &downloadpb.DownloadTextRequest{Uuid: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) uploadBankData(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	req := &uploadpb.UploadBankDataRequest{}
+	if err := readMessage(r, req); err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.uploadClient.UploadBankData(ctx, req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) downloadBankData(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.downloadClient.DownloadBankData(ctx, &downloadpb.DownloadBankDataRequest{Uuid: r.PathValue("id")})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) deleteFile(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.uploadClient.DeleteFile(ctx, &uploadpb.DeleteFileRequest{})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
+
+func (g *Gateway) listObjects(w http.ResponseWriter, r *http.Request) {
+	ctx, err := g.outgoingContext(r)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	res, err := g.listClient.GetObjectList(ctx, &listpb.SyncRequest{})
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	writeMessage(w, res)
+}
@@ -0,0 +1,222 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// openAPI serves OpenAPI spec of the gateway.
+func (g *Gateway) openAPI(w http.ResponseWriter, _ *http.Request) {
+	body, err := json.MarshalIndent(g.Spec(), "", "  ")
+	if err != nil {
+		logger.Error("error encoding openapi spec: ", zap.Error(err))
+
+		http.Error(w, "failed to encode openapi spec", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
+// Spec returns OpenAPI 3 spec of the gateway. Schemas of request and response bodies are
+// generated from descriptors of proto messages, so the spec follows changes of the protos.
+func (g *Gateway) Spec() map[string]any {
+	s := &schemas{defs: map[string]any{}}
+	paths := map[string]map[string]any{}
+
+	for _, r := range g.routes() {
+		op := map[string]any{
+			"summary":     r.summary,
+			"operationId": operationID(r),
+			"responses":   s.responses(r),
+		}
+
+		params := s.parameters(r)
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		switch {
+		case r.request != nil:
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": s.ref(r.request.ProtoReflect().Descriptor())}},
+			}
+		case r.raw && r.method == http.MethodPut:
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}},
+			}
+		}
+
+		if r.auth {
+			op["security"] = []map[string]any{{"bearerAuth": []string{}}}
+		}
+
+		if paths[r.path] == nil {
+			paths[r.path] = map[string]any{}
+		}
+		paths[r.path][strings.ToLower(r.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "GophKeeper REST API",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": s.defs,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}
+}
+
+// operationID returns id of the operation made of the method and path without parameters.
+func operationID(r route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(r.method))
+
+	for _, part := range strings.Split(r.path, "/") {
+		if part == "" || strings.HasPrefix(part, "{") {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+
+	return b.String()
+}
+
+// schemas collects schemas of proto messages referenced by the spec.
+type schemas struct {
+	defs map[string]any
+}
+
+func (s *schemas) parameters(r route) []map[string]any {
+	var params []map[string]any
+	if strings.Contains(r.path, "{id}") {
+		params = append(params, map[string]any{
+			"name": "id", "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+		})
+	}
+
+	for _, name := range r.query {
+		params = append(params, map[string]any{
+			"name": name, "in": "query", "schema": map[string]any{"type": "string"},
+		})
+	}
+
+	if r.raw && r.method == http.MethodGet {
+		params = append(params, map[string]any{
+			"name": "Range", "in": "header", "schema": map[string]any{"type": "string", "example": "bytes=0-1023"},
+		})
+	}
+
+	return params
+}
+
+func (s *schemas) responses(r route) map[string]any {
+	ok := map[string]any{"description": "OK"}
+
+	switch {
+	case r.response != nil:
+		ok["content"] = map[string]any{"application/json": map[string]any{"schema": s.ref(r.response.ProtoReflect().Descriptor())}}
+	case r.raw:
+		ok["content"] = map[string]any{"application/octet-stream": map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}}}
+	}
+
+	return map[string]any{
+		"200": ok,
+		"default": map[string]any{
+			"description": "Error, body is grpc status of the error",
+			"content":     map[string]any{"application/json": map[string]any{"schema": s.ref((&spb.Status{}).ProtoReflect().Descriptor())}},
+		},
+	}
+}
+
+// ref returns reference to schema of the message, adding the schema if it is not added yet.
+func (s *schemas) ref(md protoreflect.MessageDescriptor) map[string]any {
+	name := string(md.FullName())
+	if _, ok := s.defs[name]; !ok {
+		// placeholder stops recursion for self-referencing messages
+		s.defs[name] = nil
+		s.defs[name] = s.message(md)
+	}
+
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func (s *schemas) message(md protoreflect.MessageDescriptor) map[string]any {
+	props := map[string]any{}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		props[fd.JSONName()] = s.field(fd)
+	}
+
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (s *schemas) field(fd protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case fd.IsMap():
+		return map[string]any{"type": "object", "additionalProperties": s.value(fd.MapValue())}
+	case fd.IsList():
+		return map[string]any{"type": "array", "items": s.value(fd)}
+	default:
+		return s.value(fd)
+	}
+}
+
+// value returns schema of a single value of the field as protojson encodes it.
+func (s *schemas) value(fd protoreflect.FieldDescriptor) map[string]any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]any{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return map[string]any{"type": "string", "format": "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]any{"type": "string", "format": "uint64"}
+	case protoreflect.FloatKind:
+		return map[string]any{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]any{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]any{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+
+		return map[string]any{"type": "string", "enum": names}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		switch fd.Message().FullName() {
+		case "google.protobuf.Timestamp":
+			return map[string]any{"type": "string", "format": "date-time"}
+		case "google.protobuf.Any":
+			return map[string]any{"type": "object"}
+		}
+
+		return s.ref(fd.Message())
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
@@ -0,0 +1,88 @@
+package gateway
+
+import (
+	"net/http"
+
+	authpb "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	downloadpb "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	listpb "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	uploadpb "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// route describes the http endpoint. Request and response are messages of JSON bodies used to
+// describe the endpoint in OpenAPI spec, nil means the body is absent or is raw file content.
+type route struct {
+	method   string
+	path     string
+	summary  string
+	auth     bool
+	query    []string // names of query parameters
+	request  proto.Message
+	response proto.Message
+	raw      bool // request or response body is raw file content
+	handler  http.HandlerFunc
+}
+
+func (g *Gateway) routes() []route {
+	return []route{
+		{
+			method: http.MethodPost, path: "/v1/auth/register", summary: "Register new user",
+			request: &authpb.RegisterRequest{}, response: &authpb.RegisterResponse{}, handler: g.register,
+		},
+		{
+			method: http.MethodPost, path: "/v1/auth/login", summary: "Log in and get access token",
+			request: &authpb.LoginRequest{}, response: &authpb.LoginResponse{}, handler: g.login,
+		},
+		{
+			method: http.MethodPost, path: "/v1/auth/login/start", summary: "Start SRP-6a login without sending password",
+			request: &authpb.LoginStartRequest{}, response: &authpb.LoginStartResponse{}, handler: g.loginStart,
+		},
+		{
+			method: http.MethodPost, path: "/v1/auth/login/finish", summary: "Finish SRP-6a login and get access token",
+			request: &authpb.LoginFinishRequest{}, response: &authpb.LoginFinishResponse{}, handler: g.loginFinish,
+		},
+		{
+			method: http.MethodPut, path: "/v1/passwords/{id}", summary: "Save login and password", auth: true,
+			request: &uploadpb.UploadPasswordRequest{}, response: &uploadpb.UploadPasswordResponse{}, handler: g.uploadPassword,
+		},
+		{
+			method: http.MethodGet, path: "/v1/passwords/{id}", summary: "Get login and password", auth: true,
+			response: &downloadpb.DownloadPasswordResponse{}, handler: g.downloadPassword,
+		},
+		{
+			method: http.MethodPut, path: "/v1/texts/{id}", summary: "Save text", auth: true,
+			request: &uploadpb.UploadTextRequest{}, response: &uploadpb.UploadTextResponse{}, handler: g.uploadText,
+		},
+		{
+			method: http.MethodGet, path: "/v1/texts/{id}", summary: "Get text", auth: true,
+			response: &downloadpb.DownloadTextResponse{}, handler: g.downloadText,
+		},
+		{
+			method: http.MethodPut, path: "/v1/cards/{id}", summary: "Save bank card details", auth: true,
+			request: &uploadpb.UploadBankDataRequest{}, response: &uploadpb.UploadBankDataResponse{}, handler: g.uploadBankData,
+		},
+		{
+			method: http.MethodGet, path: "/v1/cards/{id}", summary: "Get bank card details", auth: true,
+			response: &downloadpb.DownloadBankDataResponse{}, handler: g.downloadBankData,
+		},
+		{
+			method: http.MethodPut, path: "/v1/files/{id}", summary: "Upload file, request body is file content", auth: true,
+			query: []string{"file_name", "metadata", "compression", "sha256"}, raw: true,
+			response: &uploadpb.UploadFileResponse{}, handler: g.uploadFile,
+		},
+		{
+			method: http.MethodGet, path: "/v1/files/{id}", summary: "Download file, a single byte range may be requested", auth: true,
+			raw: true, handler: g.downloadFile,
+		},
+		{
+			method: http.MethodDelete, path: "/v1/files/{id}", summary: "Delete file", auth: true,
+			response: &emptypb.Empty{}, handler: g.deleteFile,
+		},
+		{
+			method: http.MethodGet, path: "/v1/objects", summary: "List saved data", auth: true,
+			response: &listpb.SyncResponse{}, handler: g.listObjects,
+		},
+	}
+}
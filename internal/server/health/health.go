@@ -0,0 +1,190 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// checkTimeout limits time of a single dependency check.
+const checkTimeout = 3 * time.Second
+
+// Check reports whether the dependency is available.
+type Check func(ctx context.Context) error
+
+// Checker periodically checks dependencies of the server and reports grpc services depending
+// on an unavailable dependency as NOT_SERVING through grpc health service and /readyz endpoint.
+type Checker struct {
+	server   *health.Server
+	interval time.Duration
+
+	checks   map[string]Check    // dependency name to its check
+	services map[string][]string // grpc service name to dependencies it needs
+
+	mu       sync.RWMutex
+	results  map[string]error
+	ready    bool
+	shutdown bool
+
+	stop chan struct{}
+	once sync.Once
+}
+
+func New(interval time.Duration) *Checker {
+	return &Checker{
+		server:   health.NewServer(),
+		interval: interval,
+		checks:   map[string]Check{},
+		services: map[string][]string{},
+		results:  map[string]error{},
+		stop:     make(chan struct{}),
+	}
+}
+
+// AddCheck adds check of the dependency with name. Checks must be added before Start.
+func (c *Checker) AddCheck(name string, check Check) {
+	c.checks[name] = check
+}
+
+// AddService adds grpc service, which is serving only while all its dependencies are available.
+func (c *Checker) AddService(service string, deps ...string) {
+	c.services[service] = deps
+	c.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+}
+
+// Server returns grpc health service.
+func (c *Checker) Server() healthpb.HealthServer {
+	return c.server
+}
+
+// Start runs checks at once and then periodically until Shutdown is called.
+func (c *Checker) Start(ctx context.Context) {
+	c.run(ctx)
+
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.run(ctx)
+			case <-c.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Shutdown stops checks and reports all services as NOT_SERVING, so load balancers stop sending requests.
+func (c *Checker) Shutdown() error {
+	c.once.Do(func() {
+		close(c.stop)
+
+		c.mu.Lock()
+		c.shutdown = true
+		c.mu.Unlock()
+
+		c.server.Shutdown()
+	})
+
+	return nil
+}
+
+// run checks all dependencies concurrently and updates statuses of the services.
+func (c *Checker) run(ctx context.Context) {
+	results := make(map[string]error, len(c.checks))
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for name, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			err := check(checkCtx)
+			if err != nil {
+				logger.Warn("dependency check failed", zap.String("dependency", name), zap.Error(err))
+			}
+
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = results
+	c.ready = true
+
+	// statuses are not updated after shutdown, health server ignores them as well
+	if c.shutdown {
+		return
+	}
+
+	overall := healthpb.HealthCheckResponse_SERVING
+	for _, err := range results {
+		if err != nil {
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+	}
+	c.server.SetServingStatus("", overall)
+
+	for service, deps := range c.services {
+		status := healthpb.HealthCheckResponse_SERVING
+		for _, dep := range deps {
+			if results[dep] != nil {
+				status = healthpb.HealthCheckResponse_NOT_SERVING
+			}
+		}
+		c.server.SetServingStatus(service, status)
+	}
+}
+
+// Healthz reports that the server process is alive.
+func (c *Checker) Healthz(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ok"))
+}
+
+// Readyz reports whether the server is ready to serve requests, that is all dependencies are available
+// and the server is not shutting down. Results of the last checks are returned in the body.
+func (c *Checker) Readyz(w http.ResponseWriter, _ *http.Request) {
+	c.mu.RLock()
+	ready := c.ready && !c.shutdown
+	checks := make(map[string]string, len(c.results))
+	for name, err := range c.results {
+		checks[name] = "ok"
+		if err != nil {
+			checks[name] = err.Error()
+			ready = false
+		}
+	}
+	c.mu.RUnlock()
+
+	code := http.StatusOK
+	if !ready {
+		code = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(map[string]any{"ready": ready, "checks": checks})
+}
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+)
+
+// DBClient wraps db client, so queries made through it are counted and timed by db.Query.Name.
+func DBClient(client db.Client) db.Client {
+	return &dbClient{Client: client, db: &meteredDB{DB: client.DB()}}
+}
+
+type dbClient struct {
+	db.Client
+	db db.DB
+}
+
+func (c *dbClient) DB() db.DB {
+	return c.db
+}
+
+type meteredDB struct {
+	db.DB
+}
+
+func (d *meteredDB) ScanOneContext(ctx context.Context, dest interface{}, q db.Query, args ...interface{}) error {
+	start := time.Now()
+
+	err := d.DB.ScanOneContext(ctx, dest, q, args...)
+	observeQuery(q, start, err)
+
+	return err
+}
+
+func (d *meteredDB) ScanAllContext(ctx context.Context, dest interface{}, q db.Query, args ...interface{}) error {
+	start := time.Now()
+
+	err := d.DB.ScanAllContext(ctx, dest, q, args...)
+	observeQuery(q, start, err)
+
+	return err
+}
+
+func (d *meteredDB) ExecContect(ctx context.Context, q db.Query, args ...interface{}) (pgconn.CommandTag, error) {
+	start := time.Now()
+
+	tag, err := d.DB.ExecContect(ctx, q, args...)
+	observeQuery(q, start, err)
+
+	return tag, err
+}
+
+func (d *meteredDB) QueryContext(ctx context.Context, q db.Query, args ...interface{}) (pgx.Rows, error) {
+	start := time.Now()
+
+	rows, err := d.DB.QueryContext(ctx, q, args...)
+	observeQuery(q, start, err)
+
+	return rows, err
+}
+
+// QueryRowContext observes the query once its row is scanned, since the query error is returned by Scan.
+func (d *meteredDB) QueryRowContext(ctx context.Context, q db.Query, args ...interface{}) pgx.Row {
+	start := time.Now()
+
+	return &meteredRow{Row: d.DB.QueryRowContext(ctx, q, args...), query: q, start: start}
+}
+
+type meteredRow struct {
+	pgx.Row
+	query db.Query
+	start time.Time
+}
+
+func (r *meteredRow) Scan(dest ...interface{}) error {
+	err := r.Row.Scan(dest...)
+	observeQuery(r.query, r.start, err)
+
+	return err
+}
+
+func observeQuery(q db.Query, start time.Time, err error) {
+	// no rows is a valid result of the query
+	if errors.Is(err, pgx.ErrNoRows) || pgxscan.NotFound(err) {
+		err = nil
+	}
+
+	dbQueries.WithLabelValues(q.Name, result(err)).Inc()
+	dbDuration.WithLabelValues(q.Name).Observe(time.Since(start).Seconds())
+}
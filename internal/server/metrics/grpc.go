@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor counts unary RPCs by status code and observes their latency.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		res, err := handler(ctx, req)
+		observeRPC(info.FullMethod, start, err)
+
+		return res, err
+	}
+}
+
+// StreamServerInterceptor counts streaming RPCs by status code, observes their latency
+// and size of messages sent and received, which are file chunks for file streams.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		err := handler(srv, &meteredStream{ServerStream: ss, method: info.FullMethod})
+		observeRPC(info.FullMethod, start, err)
+
+		return err
+	}
+}
+
+func observeRPC(method string, start time.Time, err error) {
+	rpcHandled.WithLabelValues(method, status.Code(err).String()).Inc()
+	rpcDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+}
+
+type meteredStream struct {
+	grpc.ServerStream
+	method string
+}
+
+func (s *meteredStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if msg, ok := m.(proto.Message); ok && err == nil {
+		streamBytes.WithLabelValues(s.method, "sent").Add(float64(proto.Size(msg)))
+	}
+
+	return err
+}
+
+func (s *meteredStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if msg, ok := m.(proto.Message); ok && err == nil {
+		streamBytes.WithLabelValues(s.method, "received").Add(float64(proto.Size(msg)))
+	}
+
+	return err
+}
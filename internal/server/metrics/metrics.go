@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophkeeper"
+
+var registry = prometheus.NewRegistry()
+
+var (
+	rpcHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "handled_total",
+		Help:      "Number of RPCs completed on the server by method and status code.",
+	}, []string{"method", "code"})
+
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "handling_seconds",
+		Help:      "Time of handling RPCs by method.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"method"})
+
+	streamBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "grpc",
+		Name:      "stream_bytes_total",
+		Help:      "Size of messages of streaming RPCs by method and direction.",
+	}, []string{"method", "direction"})
+
+	dbQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "queries_total",
+		Help:      "Number of Postgres queries by query name and result.",
+	}, []string{"query", "result"})
+
+	dbDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "postgres",
+		Name:      "query_seconds",
+		Help:      "Time of Postgres queries by query name.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"query"})
+
+	storageCalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "minio",
+		Name:      "calls_total",
+		Help:      "Number of object storage calls by operation and result.",
+	}, []string{"operation", "result"})
+
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "minio",
+		Name:      "call_seconds",
+		Help:      "Time of object storage calls by operation.",
+		Buckets:   prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"operation"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		rpcHandled,
+		rpcDuration,
+		streamBytes,
+		dbQueries,
+		dbDuration,
+		storageCalls,
+		storageDuration,
+	)
+}
+
+// Handler returns handler exposing collected metrics in Prometheus format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// result returns result label of the call.
+func result(err error) string {
+	if err != nil {
+		return "error"
+	}
+
+	return "ok"
+}
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+)
+
+// DataRepository wraps object storage repository, so its calls are counted and timed by operation.
+func DataRepository(next storage.DataRepository) storage.DataRepository {
+	return &dataRepository{next: next}
+}
+
+type dataRepository struct {
+	next storage.DataRepository
+}
+
+func (d *dataRepository) Ping(ctx context.Context) (err error) {
+	defer observeCall("Ping", time.Now(), &err)
+
+	return d.next.Ping(ctx)
+}
+
+func (d *dataRepository) SaveFile(ctx context.Context, reader io.Reader, login string, id string, meta string, compression string) (etag string, err error) {
+	defer observeCall("SaveFile", time.Now(), &err)
+
+	return d.next.SaveFile(ctx, reader, login, id, meta, compression)
+}
+
+func (d *dataRepository) DownloadFile(ctx context.Context, bucketName, objectName string, offset, length int64) (res *model.FileObject, err error) {
+	defer observeCall("DownloadFile", time.Now(), &err)
+
+	return d.next.DownloadFile(ctx, bucketName, objectName, offset, length)
+}
+
+func (d *dataRepository) SaveTextData(ctx context.Context, data any, login string, id string, info string, dataType string, compression string) (etag string, err error) {
+	defer observeCall("SaveTextData", time.Now(), &err)
+
+	return d.next.SaveTextData(ctx, data, login, id, info, dataType, compression)
+}
+
+func (d *dataRepository) DownloadTextData(ctx context.Context, bucketName, objectName, dataType string) (res *model.TextObject, err error) {
+	defer observeCall("DownloadTextData", time.Now(), &err)
+
+	return d.next.DownloadTextData(ctx, bucketName, objectName, dataType)
+}
+
+func (d *dataRepository) ListObjects(ctx context.Context, login string) (res []model.ObjectInfo, err error) {
+	defer observeCall("ListObjects", time.Now(), &err)
+
+	return d.next.ListObjects(ctx, login)
+}
+
+func (d *dataRepository) StatObject(ctx context.Context, login string, key string) (res *model.ObjectInfo, err error) {
+	defer observeCall("StatObject", time.Now(), &err)
+
+	return d.next.StatObject(ctx, login, key)
+}
+
+func (d *dataRepository) NewMultipartUpload(ctx context.Context, login string, id string, info string, compression string) (uploadID string, err error) {
+	defer observeCall("NewMultipartUpload", time.Now(), &err)
+
+	return d.next.NewMultipartUpload(ctx, login, id, info, compression)
+}
+
+func (d *dataRepository) UploadPart(ctx context.Context, login string, id string, uploadID string, partNumber int, data []byte) (err error) {
+	defer observeCall("UploadPart", time.Now(), &err)
+
+	return d.next.UploadPart(ctx, login, id, uploadID, partNumber, data)
+}
+
+func (d *dataRepository) CompleteMultipartUpload(ctx context.Context, login string, id string, uploadID string) (etag string, err error) {
+	defer observeCall("CompleteMultipartUpload", time.Now(), &err)
+
+	return d.next.CompleteMultipartUpload(ctx, login, id, uploadID)
+}
+
+func (d *dataRepository) AbortMultipartUpload(ctx context.Context, login string, id string, uploadID string) (err error) {
+	defer observeCall("AbortMultipartUpload", time.Now(), &err)
+
+	return d.next.AbortMultipartUpload(ctx, login, id, uploadID)
+}
+
+func (d *dataRepository) PresignedPostURL(
+	ctx context.Context,
+	login string,
+	id string,
+	maxSize int64,
+	expiry time.Duration,
+) (res *model.PresignedURL, err error) {
+	defer observeCall("PresignedPostURL", time.Now(), &err)
+
+	return d.next.PresignedPostURL(ctx, login, id, maxSize, expiry)
+}
+
+func (d *dataRepository) PresignedGetURL(ctx context.Context, login string, id string, expiry time.Duration) (res *model.PresignedURL, err error) {
+	defer observeCall("PresignedGetURL", time.Now(), &err)
+
+	return d.next.PresignedGetURL(ctx, login, id, expiry)
+}
+
+func (d *dataRepository) CompleteFile(ctx context.Context, login string, id string, info string, checksum string, compression string) (res *model.UploadResult, err error) {
+	defer observeCall("CompleteFile", time.Now(), &err)
+
+	return d.next.CompleteFile(ctx, login, id, info, checksum, compression)
+}
+
+func (d *dataRepository) RemoveFile(ctx context.Context, login string, id string) (err error) {
+	defer observeCall("RemoveFile", time.Now(), &err)
+
+	return d.next.RemoveFile(ctx, login, id)
+}
+
+func (d *dataRepository) StatFile(ctx context.Context, login string, id string) (res *model.FileObject, err error) {
+	defer observeCall("StatFile", time.Now(), &err)
+
+	return d.next.StatFile(ctx, login, id)
+}
+
+func (d *dataRepository) RemoveTextData(ctx context.Context, login, id, dataType string) (err error) {
+	defer observeCall("RemoveTextData", time.Now(), &err)
+
+	return d.next.RemoveTextData(ctx, login, id, dataType)
+}
+
+func (d *dataRepository) NewBlob(ctx context.Context, login string, id string, checksum string) (res *model.Blob, err error) {
+	defer observeCall("NewBlob", time.Now(), &err)
+
+	return d.next.NewBlob(ctx, login, id, checksum)
+}
+
+func (d *dataRepository) CopyToBlob(ctx context.Context, login string, id string, blob *model.Blob) (err error) {
+	defer observeCall("CopyToBlob", time.Now(), &err)
+
+	return d.next.CopyToBlob(ctx, login, id, blob)
+}
+
+func (d *dataRepository) DownloadBlob(ctx context.Context, login string, key string, offset, length int64) (res *model.FileObject, err error) {
+	defer observeCall("DownloadBlob", time.Now(), &err)
+
+	return d.next.DownloadBlob(ctx, login, key, offset, length)
+}
+
+func (d *dataRepository) PresignedBlobURL(ctx context.Context, login string, key string, expiry time.Duration) (res *model.PresignedURL, err error) {
+	defer observeCall("PresignedBlobURL", time.Now(), &err)
+
+	return d.next.PresignedBlobURL(ctx, login, key, expiry)
+}
+
+func (d *dataRepository) RemoveBlob(ctx context.Context, login string, key string) (err error) {
+	defer observeCall("RemoveBlob", time.Now(), &err)
+
+	return d.next.RemoveBlob(ctx, login, key)
+}
+
+func (d *dataRepository) RemoveBucket(ctx context.Context, login string) (err error) {
+	defer observeCall("RemoveBucket", time.Now(), &err)
+
+	return d.next.RemoveBucket(ctx, login)
+}
+
+func observeCall(operation string, start time.Time, err *error) {
+	res := result(*err)
+	if errors.Is(*err, storage.ErrObjectNotFound) {
+		res = "not_found"
+	}
+
+	storageCalls.WithLabelValues(operation, res).Inc()
+	storageDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// AuditAction is the kind of audited event.
+type AuditAction string
+
+const (
+	AuditActionLogin    AuditAction = "login"
+	AuditActionRegister AuditAction = "register"
+	AuditActionUpload   AuditAction = "upload"
+	AuditActionDownload AuditAction = "download"
+	AuditActionList     AuditAction = "list"
+	AuditActionDelete   AuditAction = "delete"
+	AuditActionShare    AuditAction = "share"
+)
+
+// AuditEvent is a record of the audit log about an access to secrets or an account event.
+type AuditEvent struct {
+	ID        int64       `db:"id"`
+	Time      time.Time   `db:"occurred_at"`
+	Actor     string      `db:"actor"` // login of the user, who made the request
+	Action    AuditAction `db:"action"`
+	ObjectID  string      `db:"object_id"`
+	Method    string      `db:"method"` // full name of the RPC
+	Peer      string      `db:"peer"`   // network address of the client
+	RequestID string      `db:"request_id"`
+	Result    string      `db:"result"` // grpc status code of the request
+}
+
+// AuditFilter selects events of the audit log, zero fields do not restrict the result.
+// Events are returned newest first, BeforeID continues listing from the last returned event.
+type AuditFilter struct {
+	From     time.Time
+	To       time.Time
+	Actor    string
+	ObjectID string
+	Action   AuditAction
+	BeforeID int64
+	Limit    uint64
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// Blob is file content stored once per user and shared by all files with the same checksum.
+type Blob struct {
+	Key      string `db:"object_key"` // name of the object in storage
+	Login    string `db:"login"`
+	Checksum string `db:"sha256"` // hex encoded sha256 of the stored (client-encrypted) content
+	Size     int64  `db:"size"`
+	ETag     string `db:"etag"`
+	RefCount int    `db:"ref_count"`
+}
+
+// FileRef links file saved by the user under its id with the blob holding its content.
+type FileRef struct {
+	DataID      string    `db:"data_id"`
+	Login       string    `db:"login"`
+	BlobKey     string    `db:"object_key"`
+	Checksum    string    `db:"sha256"`
+	Size        int64     `db:"size"`
+	ETag        string    `db:"etag"`
+	Metadata    string    `db:"metadata"`
+	Compression string    `db:"compression"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"crypto/x509"
+	"time"
+)
+
+// CertBinding binds identity of client certificates to the user, who is authenticated by them.
+type CertBinding struct {
+	Identity  string    `db:"identity"`
+	Login     string    `db:"login"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CertIdentities returns identities of the certificate, which may be bound to a user, in order of precedence:
+// email, URI and DNS names of subject alternative names, then common name of the subject.
+// Identities are prefixed with their kind, e.g. "email:user@example.com" or "cn:user".
+func CertIdentities(cert *x509.Certificate) []string {
+	var res []string
+	for _, email := range cert.EmailAddresses {
+		res = append(res, "email:"+email)
+	}
+	for _, uri := range cert.URIs {
+		res = append(res, "uri:"+uri.String())
+	}
+	for _, name := range cert.DNSNames {
+		res = append(res, "dns:"+name)
+	}
+	if cn := cert.Subject.CommonName; cn != "" {
+		res = append(res, "cn:"+cn)
+	}
+
+	return res
+}
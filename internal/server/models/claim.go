@@ -5,4 +5,6 @@ import "github.com/golang-jwt/jwt"
 type UserClaims struct {
 	jwt.StandardClaims
 	Login string `db:"login"`
+	// Scope restricts access of requests authenticated by API token, it is nil for access tokens.
+	Scope *TokenScope `json:"-"`
 }
@@ -1,6 +1,36 @@
 package model
 
+import (
+	"io"
+	"time"
+)
+
 type FileInfo struct {
 	Login string `db:"login"`   // owner login
 	Id    string `db:"data_id"` // file id
 }
+
+// FileObject is a readable range of the stored binary file.
+type FileObject struct {
+	Reader   io.ReadCloser
+	Size     int64 // total size of the stored file
+	Metadata string
+	Checksum string // hex encoded sha256 of the whole file, empty for files uploaded before checksums were stored
+
+	Compression string // algorithm the file was compressed with by the client, empty if not compressed
+}
+
+// PresignedURL is a short-lived url granting direct access to the binary object in storage.
+type PresignedURL struct {
+	URL       string
+	ExpiresAt time.Time
+
+	// fields of the POST form, set only for upload urls
+	FormData map[string]string
+
+	// attributes of the stored file, set only for download urls
+	Size        int64
+	Metadata    string
+	Checksum    string
+	Compression string
+}
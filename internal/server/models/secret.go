@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// SecretType is the kind of data kept in the secret.
+type SecretType string
+
+const (
+	SecretTypeCredential SecretType = "credential"
+	SecretTypeCard       SecretType = "card"
+	SecretTypeText       SecretType = "text"
+	SecretTypeFile       SecretType = "file"
+	SecretTypeCustom     SecretType = "custom"
+)
+
+// SecretInfo is the index record of the secret, its payload is kept in object storage.
+type SecretInfo struct {
+	ID        string     `db:"data_id"`
+	Login     string     `db:"login"`
+	Type      SecretType `db:"type"`
+	Name      string     `db:"name"`
+	Tags      []string   `db:"tags"`
+	Version   int64      `db:"version"`
+	ETag      string     `db:"etag"` // etag of the stored payload
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+// Secret is the secret together with its payload, only the payload field matching the type is set.
+type Secret struct {
+	SecretInfo
+
+	Credential *Credential
+	Card       *Card
+	Text       *SecretText
+	File       *FileObject // attributes of the file uploaded under the secret id, reader is not set
+	Custom     map[string]string
+}
+
+type Credential struct {
+	Login    string
+	Password string
+}
+
+type Card struct {
+	Number  string
+	CVC     string
+	ExpDate string
+}
+
+type SecretText struct {
+	Text        string
+	Compression string
+}
+
+// SecretFilter narrows listed secrets, empty fields match all secrets.
+type SecretFilter struct {
+	Type SecretType
+	Tag  string
+}
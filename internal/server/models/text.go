@@ -0,0 +1,8 @@
+package model
+
+// TextObject is the stored text or serialized structured data.
+type TextObject struct {
+	Data        []byte
+	Metadata    string
+	Compression string // algorithm the data was compressed with by the client, empty if not compressed
+}
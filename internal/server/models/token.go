@@ -0,0 +1,41 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// APITokenPrefix tells API tokens from access tokens issued on login.
+const APITokenPrefix = "gkp_"
+
+// TokenScope restricts access granted by API token. Empty lists do not restrict access.
+type TokenScope struct {
+	ReadOnly  bool     `db:"read_only"`
+	SecretIDs []string `db:"secret_ids"`
+	DataTypes []string `db:"data_types"`
+}
+
+// Allows reports whether the scope grants access to data with id of dataType,
+// nil scope of access tokens issued on login grants access to all data of the user.
+func (s *TokenScope) Allows(id, dataType string) bool {
+	if s == nil {
+		return true
+	}
+
+	if len(s.SecretIDs) > 0 && !slices.Contains(s.SecretIDs, id) {
+		return false
+	}
+
+	return len(s.DataTypes) == 0 || slices.Contains(s.DataTypes, dataType)
+}
+
+// APIToken is long-lived token the user creates for automation, only hash of the token is stored.
+type APIToken struct {
+	ID    string `db:"id"`
+	Login string `db:"login"`
+	Name  string `db:"name"`
+	Hash  string `db:"token_hash"`
+	TokenScope
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// UploadSession describes the state of a resumable file upload.
+type UploadSession struct {
+	UploadID        string    `db:"upload_id"`
+	Login           string    `db:"login"`
+	DataID          string    `db:"data_id"`
+	FileName        string    `db:"file_name"`
+	Metadata        string    `db:"metadata"`
+	Compression     string    `db:"compression"`
+	StorageUploadID string    `db:"storage_upload_id"` // multipart upload id in object storage
+	Size            int64     `db:"size"`
+	CommittedOffset int64     `db:"committed_offset"`
+	PartsCount      int       `db:"parts_count"`
+	HashState       []byte    `db:"hash_state"` // marshalled sha256 state of the committed bytes
+	CreatedAt       time.Time `db:"created_at"`
+}
+
+// UploadResult is returned once a resumable upload has been finalized.
+type UploadResult struct {
+	FileName string
+	Size     int64
+	ETag     string
+	Checksum string // hex encoded sha256 of the file content
+}
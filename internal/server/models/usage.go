@@ -0,0 +1,15 @@
+package model
+
+// Usage is storage used by the user.
+type Usage struct {
+	Login   string `db:"login"`
+	Bytes   int64  `db:"bytes"`   // total size of saved data
+	Objects int64  `db:"objects"` // number of saved secrets and files
+}
+
+// Quota limits storage used by every user, zero fields are not limited.
+type Quota struct {
+	MaxBytes      int64
+	MaxObjects    int64
+	MaxObjectSize int64
+}
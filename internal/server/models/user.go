@@ -1,6 +1,62 @@
 package model
 
+import "time"
+
+// Roles of users.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type UserInfo struct {
 	Login string `db:"login"`
-	Hash  []byte `db:"password_hash"`
+	// Hash is bcrypt hash of the password of users, who have not logged in since verifiers were introduced.
+	Hash []byte `db:"password_hash"`
+	// Salt and Verifier of the password for SRP-6a, the password itself is never sent to the server.
+	Salt     []byte `db:"srp_salt"`
+	Verifier []byte `db:"srp_verifier"`
+	Role     string `db:"role"`
+	Disabled bool   `db:"disabled"`
+	// TokensValidAfter is the time access tokens issued before are rejected.
+	TokensValidAfter time.Time `db:"tokens_valid_after"`
+	CreatedAt        time.Time `db:"created_at"`
+}
+
+// Migrated reports whether the user has SRP verifier instead of password hash.
+func (u *UserInfo) Migrated() bool {
+	return len(u.Verifier) != 0
+}
+
+// Credentials are either password of the user, whose verifier is computed by the server,
+// or salt and verifier computed by the client, so that the password is not sent to the server.
+type Credentials struct {
+	Password string
+	Salt     []byte
+	Verifier []byte
+}
+
+// LoginChallenge is returned to the client starting password authenticated login.
+type LoginChallenge struct {
+	SessionID string
+	Salt      []byte
+	ServerKey []byte
+}
+
+// LoginSession is started password authenticated login, which is finished by the client proving the password.
+type LoginSession struct {
+	ID          string    `db:"id"`
+	Login       string    `db:"login"`
+	ClientProof []byte    `db:"client_proof"`
+	ServerProof []byte    `db:"server_proof"`
+	ExpiresAt   time.Time `db:"expires_at"`
+}
+
+// UserSummary describes the user for administrators together with storage used by the user.
+type UserSummary struct {
+	Login     string    `db:"login"`
+	Role      string    `db:"role"`
+	Disabled  bool      `db:"disabled"`
+	CreatedAt time.Time `db:"created_at"`
+	Bytes     int64     `db:"bytes"`
+	Objects   int64     `db:"objects"`
 }
@@ -0,0 +1,50 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const login = "login"
+
+type UsageRepository interface {
+	GetUsage(ctx context.Context, login string) (*models.Usage, error)
+}
+
+type AccountService struct {
+	usageRepository UsageRepository
+	quota           models.Quota
+}
+
+func New(usageRep UsageRepository, quota models.Quota) *AccountService {
+	return &AccountService{usageRepository: usageRep, quota: quota}
+}
+
+// GetUsage returns storage used by the caller and the quota applied to it.
+func (a *AccountService) GetUsage(ctx context.Context) (*models.Usage, models.Quota, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return nil, models.Quota{}, storage.ErrLoginRequired
+	}
+
+	// usage is kept under the login data is saved with, @ is removed since it is not allowed for Minio bucket name
+	caller := strings.Replace(md[login][0], "@", "", -1)
+
+	usage, err := a.usageRepository.GetUsage(ctx, caller)
+	if err != nil {
+		logger.Error("error getting usage: ", zap.Error(err))
+
+		return nil, models.Quota{}, fmt.Errorf("error getting usage: %w", err)
+	}
+
+	return usage, a.quota, nil
+}
@@ -0,0 +1,226 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/srp"
+	utils "github.com/igortoigildin/goph-keeper/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAdminRequired = storage.NewError(storage.ErrPermissionDenied, "ADMIN_REQUIRED", "administrator role is required")
+	ErrInvalidRole   = storage.NewError(storage.ErrInvalidArgument, "INVALID_ROLE", "role must be user or admin")
+)
+
+type UserRepository interface {
+	GetUser(ctx context.Context, login string) (*models.UserInfo, error)
+	SaveUser(ctx context.Context, login string, salt, verifier []byte) (uid int64, err error)
+	ListUsers(ctx context.Context) ([]models.UserSummary, error)
+	SetRole(ctx context.Context, login string, role string) error
+	SetDisabled(ctx context.Context, login string, disabled bool) error
+	RevokeTokens(ctx context.Context, login string) error
+	SaveResetToken(ctx context.Context, login string, tokenHash string, expiresAt time.Time) error
+	DeleteData(ctx context.Context, login string) error
+}
+
+type DataRepository interface {
+	RemoveBucket(ctx context.Context, login string) error
+}
+
+type AdminService struct {
+	userRepository UserRepository
+	dataRepository DataRepository
+	certRepository CertRepository
+	resetTokenTTL  time.Duration
+}
+
+// New returns service managing users and their client certificates, password reset tokens it issues
+// are valid for resetTokenTTL.
+func New(userRep UserRepository, dataRep DataRepository, certRep CertRepository, resetTokenTTL time.Duration) *AdminService {
+	return &AdminService{
+		userRepository: userRep,
+		dataRepository: dataRep,
+		certRepository: certRep,
+		resetTokenTTL:  resetTokenTTL,
+	}
+}
+
+// Authorize rejects the caller, who is not an administrator. The caller is identified by the access token,
+// and the role is read from the database, so revoked roles take effect immediately.
+func (a *AdminService) Authorize(ctx context.Context) error {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return storage.ErrNotLoggedIn
+	}
+
+	user, err := a.userRepository.GetUser(ctx, claims.Login)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return ErrAdminRequired
+		}
+
+		logger.Error("failed to get user", zap.Error(err))
+
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Role != models.RoleAdmin {
+		logger.Warn("administrative request rejected:", zap.String("login", claims.Login))
+
+		return ErrAdminRequired
+	}
+
+	return nil
+}
+
+// CreateUser registers the user with the role, the first administrator is created this way.
+func (a *AdminService) CreateUser(ctx context.Context, login, password, role string) error {
+	if !validRole(role) {
+		return ErrInvalidRole
+	}
+
+	salt, verifier, err := srp.NewVerifier(login, password)
+	if err != nil {
+		return fmt.Errorf("failed to compute verifier: %w", err)
+	}
+
+	_, err = a.userRepository.SaveUser(ctx, login, salt, verifier)
+	if err != nil {
+		return fmt.Errorf("failed to save user: %w", err)
+	}
+
+	if role == models.RoleUser {
+		return nil
+	}
+
+	return a.SetRole(ctx, login, role)
+}
+
+// ListUsers returns all users with storage used by them.
+func (a *AdminService) ListUsers(ctx context.Context) ([]models.UserSummary, error) {
+	users, err := a.userRepository.ListUsers(ctx)
+	if err != nil {
+		logger.Error("failed to list users", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetRole grants the role to the user.
+func (a *AdminService) SetRole(ctx context.Context, login, role string) error {
+	if !validRole(role) {
+		return ErrInvalidRole
+	}
+
+	if err := a.userRepository.SetRole(ctx, login, role); err != nil {
+		return fmt.Errorf("failed to set role: %w", err)
+	}
+
+	logger.Info("role of user changed:", zap.String("login", login), zap.String("role", role))
+
+	return nil
+}
+
+// DisableUser rejects login and access tokens of the user until the user is enabled again.
+func (a *AdminService) DisableUser(ctx context.Context, login string) error {
+	if err := a.userRepository.SetDisabled(ctx, login, true); err != nil {
+		return fmt.Errorf("failed to disable user: %w", err)
+	}
+
+	logger.Info("user disabled:", zap.String("login", login))
+
+	return nil
+}
+
+// EnableUser allows the disabled user to log in again.
+func (a *AdminService) EnableUser(ctx context.Context, login string) error {
+	if err := a.userRepository.SetDisabled(ctx, login, false); err != nil {
+		return fmt.Errorf("failed to enable user: %w", err)
+	}
+
+	logger.Info("user enabled:", zap.String("login", login))
+
+	return nil
+}
+
+// LogoutUser revokes all access tokens issued to the user so far.
+func (a *AdminService) LogoutUser(ctx context.Context, login string) error {
+	if err := a.userRepository.RevokeTokens(ctx, login); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+
+	logger.Info("user logged out:", zap.String("login", login))
+
+	return nil
+}
+
+// IssueResetToken returns one-time token, which lets the user set new password until it expires.
+// Only hash of the token is stored.
+func (a *AdminService) IssueResetToken(ctx context.Context, login string) (string, time.Time, error) {
+	if _, err := a.userRepository.GetUser(ctx, login); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	token, err := utils.NewRandomToken()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate reset token: %w", err)
+	}
+
+	expiresAt := time.Now().Add(a.resetTokenTTL)
+
+	err = a.userRepository.SaveResetToken(ctx, login, utils.HashToken(token), expiresAt)
+	if err != nil {
+		logger.Error("failed to save reset token", zap.Error(err))
+
+		return "", time.Time{}, fmt.Errorf("failed to save reset token: %w", err)
+	}
+
+	logger.Info("reset token issued:", zap.String("login", login), zap.Time("expires_at", expiresAt))
+
+	return token, expiresAt, nil
+}
+
+// DeleteUserData removes all secrets and files of the user, the account itself is kept.
+// Records are deleted first, so data is not available anymore even if removal of objects fails,
+// which is completed by calling it again.
+func (a *AdminService) DeleteUserData(ctx context.Context, login string) error {
+	if _, err := a.userRepository.GetUser(ctx, login); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// data is saved under the login without @, since it is not allowed for Minio bucket name
+	owner := strings.Replace(login, "@", "", -1)
+
+	err := a.userRepository.DeleteData(ctx, owner)
+	if err != nil {
+		logger.Error("failed to delete data records", zap.Error(err))
+
+		return fmt.Errorf("failed to delete data records: %w", err)
+	}
+
+	err = a.dataRepository.RemoveBucket(ctx, owner)
+	if err != nil {
+		logger.Error("failed to remove objects", zap.Error(err))
+
+		return fmt.Errorf("failed to remove objects: %w", err)
+	}
+
+	logger.Info("data of user deleted:", zap.String("login", login))
+
+	return nil
+}
+
+func validRole(role string) bool {
+	return role == models.RoleUser || role == models.RoleAdmin
+}
@@ -0,0 +1,76 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidIdentity = storage.NewError(storage.ErrInvalidArgument, "INVALID_CERT_IDENTITY",
+	"certificate identity must be email:, uri:, dns: or cn: followed by the value")
+
+// identityKinds are prefixes of identities returned by models.CertIdentities.
+var identityKinds = []string{"email:", "uri:", "dns:", "cn:"}
+
+type CertRepository interface {
+	BindCert(ctx context.Context, identity string, login string) error
+	UnbindCert(ctx context.Context, identity string) error
+	ListCerts(ctx context.Context, login string) ([]models.CertBinding, error)
+}
+
+// BindCert lets client certificates with the identity authenticate the user.
+func (a *AdminService) BindCert(ctx context.Context, login, identity string) error {
+	if !validIdentity(identity) {
+		return ErrInvalidIdentity
+	}
+
+	if _, err := a.userRepository.GetUser(ctx, login); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := a.certRepository.BindCert(ctx, identity, login); err != nil {
+		return fmt.Errorf("failed to bind certificate: %w", err)
+	}
+
+	logger.Info("certificate bound:", zap.String("login", login), zap.String("identity", identity))
+
+	return nil
+}
+
+// UnbindCert revokes authentication of the user by client certificates with the identity.
+func (a *AdminService) UnbindCert(ctx context.Context, identity string) error {
+	if err := a.certRepository.UnbindCert(ctx, identity); err != nil {
+		return fmt.Errorf("failed to unbind certificate: %w", err)
+	}
+
+	logger.Info("certificate unbound:", zap.String("identity", identity))
+
+	return nil
+}
+
+// ListCerts returns certificate identities bound to the user, or to all users if login is empty.
+func (a *AdminService) ListCerts(ctx context.Context, login string) ([]models.CertBinding, error) {
+	bindings, err := a.certRepository.ListCerts(ctx, login)
+	if err != nil {
+		logger.Error("failed to list certificates", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list certificates: %w", err)
+	}
+
+	return bindings, nil
+}
+
+func validIdentity(identity string) bool {
+	for _, kind := range identityKinds {
+		if value, ok := strings.CutPrefix(identity, kind); ok {
+			return value != ""
+		}
+	}
+
+	return false
+}
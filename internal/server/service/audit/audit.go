@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	login = "login"
+
+	// DefaultPageSize is the number of events returned, when the limit is not set.
+	DefaultPageSize = 100
+)
+
+// dataTypes maps methods of v1 RPCs to data type of their object, API tokens limited to certain
+// data types see events of these methods only. Text RPCs serve folders as well, those count as text.
+var dataTypes = map[string]string{
+	"/upload_v1.UploadV1/UploadPassword": "login_password",
+	"/upload_v1.UploadV1/UploadText":     "text_data",
+	"/upload_v1.UploadV1/UploadFile":     "bin_data",
+	"/upload_v1.UploadV1/UploadBankData": "bank_data",
+	"/upload_v1.UploadV1/FinalizeUpload": "bin_data",
+	"/upload_v1.UploadV1/CompleteUpload": "bin_data",
+	"/upload_v1.UploadV1/DeleteFile":     "bin_data",
+
+	"/download_v1.DownloadV1/DownloadPassword": "login_password",
+	"/download_v1.DownloadV1/DownloadText":     "text_data",
+	"/download_v1.DownloadV1/DownloadFile":     "bin_data",
+	"/download_v1.DownloadV1/DownloadBankData": "bank_data",
+	"/download_v1.DownloadV1/GetDownloadURL":   "bin_data",
+}
+
+var ErrAccessDenied = storage.NewError(storage.ErrPermissionDenied, "AUDIT_ACCESS_DENIED", "events of other users are not available")
+
+type Repository interface {
+	QueryEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+// Authorizer rejects callers, who are not administrators.
+type Authorizer interface {
+	Authorize(ctx context.Context) error
+}
+
+type AuditService struct {
+	repository Repository
+	admins     Authorizer
+}
+
+func New(repository Repository, admins Authorizer) *AuditService {
+	return &AuditService{repository: repository, admins: admins}
+}
+
+// Query returns events made by the caller matching the filter, administrators may query events of any user, newest first, and id of the last returned event,
+// if there are more events to list, which is passed as BeforeID of the filter for the next page.
+func (a *AuditService) Query(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, int64, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return nil, 0, storage.ErrLoginRequired
+	}
+
+	caller := md[login][0]
+	if filter.Actor == "" {
+		filter.Actor = caller
+	}
+
+	if filter.Actor != caller {
+		if err := a.admins.Authorize(ctx); err != nil {
+			if errors.Is(err, storage.ErrPermissionDenied) {
+				return nil, 0, ErrAccessDenied
+			}
+
+			return nil, 0, err
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+
+	pageSize := filter.Limit
+	// one more event tells whether there is the next page
+	filter.Limit++
+
+	// API token may grant access to a part of data only, events of other objects are skipped
+	scope := interceptors.ScopeFromContext(ctx)
+
+	var events []models.AuditEvent
+	for {
+		batch, err := a.repository.QueryEvents(ctx, filter)
+		if err != nil {
+			logger.Error("error querying audit events: ", zap.Error(err))
+
+			return nil, 0, fmt.Errorf("error querying audit events: %w", err)
+		}
+
+		for _, event := range batch {
+			if scope.Allows(event.ObjectID, dataTypes[event.Method]) {
+				events = append(events, event)
+			}
+		}
+
+		if uint64(len(events)) > pageSize || uint64(len(batch)) < filter.Limit {
+			break
+		}
+
+		filter.BeforeID = batch[len(batch)-1].ID
+	}
+
+	if uint64(len(events)) <= pageSize {
+		return events, 0, nil
+	}
+
+	events = events[:pageSize]
+
+	return events, events[len(events)-1].ID, nil
+}
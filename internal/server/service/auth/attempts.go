@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+)
+
+var ErrTooManyAttempts = storage.NewError(storage.ErrQuotaExceeded, "TOO_MANY_LOGIN_ATTEMPTS",
+	"too many login attempts, try again later")
+
+// attempts limits how many times each login may be attempted within a window, so that passwords
+// can not be guessed online. Counters are kept in memory of the server instance.
+type attempts struct {
+	limit  int
+	window time.Duration
+
+	mu       sync.Mutex
+	counters map[string]*counter
+}
+
+type counter struct {
+	count   int
+	resetAt time.Time
+}
+
+// newAttempts returns limiter allowing limit attempts per window, zero limit disables it.
+func newAttempts(limit int, window time.Duration) *attempts {
+	return &attempts{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*counter),
+	}
+}
+
+// allow counts the attempt to log in as the user and returns ErrTooManyAttempts once the limit is reached.
+func (a *attempts) allow(login string) error {
+	if a.limit <= 0 {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+
+	c, ok := a.counters[login]
+	if !ok || !now.Before(c.resetAt) {
+		a.sweep(now)
+
+		c = &counter{resetAt: now.Add(a.window)}
+		a.counters[login] = c
+	}
+
+	if c.count >= a.limit {
+		return ErrTooManyAttempts
+	}
+	c.count++
+
+	return nil
+}
+
+// sweep forgets counters of expired windows, so that attempts with many logins do not grow the map.
+func (a *attempts) sweep(now time.Time) {
+	for login, c := range a.counters {
+		if !now.Before(c.resetAt) {
+			delete(a.counters, login)
+		}
+	}
+}
@@ -4,56 +4,93 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
 	"github.com/igortoigildin/goph-keeper/internal/server/service"
 	"github.com/igortoigildin/goph-keeper/internal/server/storage"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/srp"
 	utils "github.com/igortoigildin/goph-keeper/pkg/utils"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
-)
-
-const (
-	tokenExpiration = 60 * time.Minute
 )
 
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrUserExists         = errors.New("user already exists")
+	ErrInvalidCredentials = storage.NewError(storage.ErrUnauthenticated, "INVALID_CREDENTIALS", "invalid credentials")
+	ErrUserExists         = storage.ErrUserExists
+	ErrUserDisabled       = storage.NewError(storage.ErrPermissionDenied, "USER_DISABLED", "user is disabled")
+	ErrSessionRevoked     = storage.NewError(storage.ErrUnauthenticated, "SESSION_REVOKED", "session is revoked, log in again")
+	ErrPasswordLogin      = storage.NewError(storage.ErrFailedPrecondition, "PASSWORD_LOGIN_DISABLED",
+		"password login is disabled, use LoginStart")
 )
 
 type UserRepository interface {
 	GetUser(ctx context.Context, login string) (*models.UserInfo, error)
-	SaveUser(ctx context.Context, login string, passHash []byte) (uid int64, err error)
+	SaveUser(ctx context.Context, login string, salt, verifier []byte) (uid int64, err error)
+	SetPassword(ctx context.Context, login string, salt, verifier []byte) error
+	MigratePassword(ctx context.Context, login string, salt, verifier []byte) error
+	ConsumeResetToken(ctx context.Context, login string, tokenHash string) error
+	SaveLoginSession(ctx context.Context, session *models.LoginSession) error
+	ConsumeLoginSession(ctx context.Context, id string) (*models.LoginSession, error)
 }
 
 type authServ struct {
-	userRepo UserRepository
+	userRepo       UserRepository
+	tokenRepo      TokenRepository
+	certRepo       CertRepository
+	jwtSecret      string
+	tokenTTL       time.Duration
+	apiTokenMaxTTL time.Duration
+	passwordLogin  bool
+	attempts       *attempts
 }
 
-func New(userRepo UserRepository) service.AuthService {
+// New returns auth service issuing access tokens signed with jwtSecret, which are valid for tokenTTL,
+// and API tokens valid for up to apiTokenMaxTTL. Client certificates are bound to users by certRepo.
+// Unless passwordLogin is set, users with verifier can log in only without sending the password.
+// Each login may be attempted loginAttempts times a minute, zero disables the limit.
+func New(
+	userRepo UserRepository,
+	tokenRepo TokenRepository,
+	certRepo CertRepository,
+	jwtSecret string,
+	tokenTTL, apiTokenMaxTTL time.Duration,
+	passwordLogin bool,
+	loginAttempts int,
+) service.AuthService {
 	return &authServ{
-		userRepo: userRepo,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		certRepo:       certRepo,
+		jwtSecret:      jwtSecret,
+		tokenTTL:       tokenTTL,
+		apiTokenMaxTTL: apiTokenMaxTTL,
+		passwordLogin:  passwordLogin,
+		attempts:       newAttempts(loginAttempts, time.Minute),
 	}
 }
 
 // Login checks if user with given credentials exists in the system and returns access token.
 // If user exists, but password is incorrect, returns error.
 // If user doesn't exist, returns error.
+// Password hash of users registered before SRP was introduced is replaced with verifier on successful login.
 func (a *authServ) Login(ctx context.Context, login, password string) (string, error) {
 	const op = "Auth.Login"
 	logger.Info("attempting to login user")
 
+	if err := a.attempts.allow(login); err != nil {
+		logger.Warn("too many login attempts", zap.String("login", login))
+
+		return "", err
+	}
+
 	// identify user by email
 	user, err := a.userRepo.GetUser(ctx, login)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			logger.Warn("user not found", zap.Error(err))
 
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			return "", a.invalidCredentials(op)
 		}
 
 		logger.Error("failed to get user", zap.Error(err))
@@ -61,15 +98,32 @@ func (a *authServ) Login(ctx context.Context, login, password string) (string, e
 		return "", fmt.Errorf("%s: %w", op, err)
 	}
 
-	// compare password hash
-	if !utils.VerifyPassword(string(user.Hash), password) {
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	// password is not hashed for disabled users, which can not log in anyway
+	if user.Disabled {
+		logger.Info("disabled user tried to login:", zap.String("login", login))
+
+		return "", ErrUserDisabled
 	}
 
-	jwtSecret := os.Getenv("JWT_SECRET")
+	if user.Migrated() {
+		if !a.passwordLogin {
+			return "", ErrPasswordLogin
+		}
+
+		if !srp.CheckPassword(login, password, user.Salt, user.Verifier) {
+			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+	} else {
+		// compare password hash
+		if !utils.VerifyPassword(string(user.Hash), password) {
+			return "", a.invalidCredentials(op)
+		}
+
+		a.migratePassword(ctx, login, password)
+	}
 
 	// generate refresh token
-	refreshToken, err := utils.GenerateToken(*user, []byte(jwtSecret), tokenExpiration)
+	refreshToken, err := utils.GenerateToken(*user, []byte(a.jwtSecret), a.tokenTTL)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate token: %w", err)
 	}
@@ -79,19 +133,46 @@ func (a *authServ) Login(ctx context.Context, login, password string) (string, e
 	return refreshToken, nil
 }
 
+// invalidCredentials returns error for unknown users and wrong passwords. With password login disabled
+// it is the same error migrated users get, so the response does not tell whether the user exists.
+func (a *authServ) invalidCredentials(op string) error {
+	if !a.passwordLogin {
+		return ErrPasswordLogin
+	}
+
+	return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+}
+
+// migratePassword replaces password hash of the user with verifier of the password.
+// Failure does not prevent the login, the user is migrated on one of the next logins.
+func (a *authServ) migratePassword(ctx context.Context, login, password string) {
+	salt, verifier, err := srp.NewVerifier(login, password)
+	if err != nil {
+		logger.Error("failed to compute verifier", zap.Error(err))
+
+		return
+	}
+
+	if err := a.userRepo.MigratePassword(ctx, login, salt, verifier); err != nil {
+		logger.Error("failed to migrate password", zap.Error(err))
+
+		return
+	}
+
+	logger.Info("password hash replaced with verifier:", zap.String("login", login))
+}
+
 // RegisterNewUser registers new user in the system and returns user ID.
 // If user with given username already exists, returns error.
-func (a *authServ) RegisterNewUser(ctx context.Context, login string, pass string) (int64, error) {
+func (a *authServ) RegisterNewUser(ctx context.Context, login string, cred models.Credentials) (int64, error) {
 	op := "server/service/auth"
 
-	passHash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	salt, verifier, err := verifierOf(login, cred)
 	if err != nil {
-		logger.Error("failed to generate password hash", zap.Error(err))
-
-		return 0, fmt.Errorf("%s: %w", op, err)
+		return 0, err
 	}
 
-	id, err := a.userRepo.SaveUser(ctx, login, passHash)
+	id, err := a.userRepo.SaveUser(ctx, login, salt, verifier)
 	if err != nil {
 		logger.Error("failed to save user", zap.Error(err))
 
@@ -102,3 +183,61 @@ func (a *authServ) RegisterNewUser(ctx context.Context, login string, pass strin
 
 	return id, nil
 }
+
+// CheckSession rejects access token of the user issued at issuedAt, if the user is disabled, deleted
+// or was logged out after the token was issued.
+func (a *authServ) CheckSession(ctx context.Context, login string, issuedAt time.Time) error {
+	user, err := a.userRepo.GetUser(ctx, login)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return ErrSessionRevoked
+		}
+
+		logger.Error("failed to get user", zap.Error(err))
+
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Disabled {
+		return ErrUserDisabled
+	}
+
+	if issuedAt.Before(user.TokensValidAfter) {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// ResetPassword sets new password of the user, who presents one-time reset token issued by an administrator.
+// Access tokens issued with the old password are revoked.
+func (a *authServ) ResetPassword(ctx context.Context, login, resetToken string, cred models.Credentials) error {
+	salt, verifier, err := verifierOf(login, cred)
+	if err != nil {
+		return err
+	}
+
+	err = a.userRepo.ConsumeResetToken(ctx, login, utils.HashToken(resetToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrResetTokenInvalid) {
+			logger.Warn("invalid reset token", zap.String("login", login))
+
+			return err
+		}
+
+		logger.Error("failed to consume reset token", zap.Error(err))
+
+		return fmt.Errorf("failed to consume reset token: %w", err)
+	}
+
+	err = a.userRepo.SetPassword(ctx, login, salt, verifier)
+	if err != nil {
+		logger.Error("failed to set password", zap.Error(err))
+
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+
+	logger.Info("password reset:", zap.String("login", login))
+
+	return nil
+}
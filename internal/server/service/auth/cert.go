@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"time"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type CertRepository interface {
+	FindBinding(ctx context.Context, identities []string) (*models.CertBinding, error)
+}
+
+// AuthenticateCert returns claims of the user, whom the client certificate verified by the TLS handshake
+// is bound to. Certificates of disabled users are rejected. Logging the user out does not revoke
+// certificates, their binding has to be removed instead.
+func (a *authServ) AuthenticateCert(ctx context.Context, cert *x509.Certificate) (*models.UserClaims, error) {
+	binding, err := a.certRepo.FindBinding(ctx, models.CertIdentities(cert))
+	if err != nil {
+		if errors.Is(err, storage.ErrCertNotBound) {
+			logger.Warn("client certificate is not bound:", zap.String("subject", cert.Subject.String()))
+
+			return nil, storage.ErrCertNotBound
+		}
+
+		logger.Error("failed to find certificate binding", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to find certificate binding: %w", err)
+	}
+
+	if err := a.CheckSession(ctx, binding.Login, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &models.UserClaims{Login: binding.Login}, nil
+}
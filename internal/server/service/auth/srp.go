@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/srp"
+	utils "github.com/igortoigildin/goph-keeper/pkg/utils"
+	"go.uber.org/zap"
+)
+
+// loginSessionTTL is how long started password authenticated login can be finished.
+const loginSessionTTL = time.Minute
+
+var (
+	ErrInvalidPublicKey = storage.NewError(storage.ErrInvalidArgument, "INVALID_PUBLIC_KEY", "public key is invalid")
+	ErrInvalidVerifier  = storage.NewError(storage.ErrInvalidArgument, "INVALID_VERIFIER",
+		"either password or salt and verifier of it are required")
+)
+
+// LoginStart starts SRP-6a login of the user with public ephemeral key of the client.
+// Unknown users and users with password hash get made up salt, which stays the same between attempts,
+// so that the response does not tell whether the user exists. Users with password hash have to log in
+// with password once to get verifier, their SRP login fails until then.
+func (a *authServ) LoginStart(ctx context.Context, login string, clientKey []byte) (*models.LoginChallenge, error) {
+	if err := a.attempts.allow(login); err != nil {
+		logger.Warn("too many login attempts", zap.String("login", login))
+
+		return nil, err
+	}
+
+	var salt, verifier []byte
+
+	user, err := a.userRepo.GetUser(ctx, login)
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound), err == nil && !user.Migrated():
+		salt, verifier = a.fakeVerifier(login)
+	case err != nil:
+		logger.Error("failed to get user", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	default:
+		salt, verifier = user.Salt, user.Verifier
+	}
+
+	server, err := srp.NewServer(verifier)
+	if err != nil {
+		logger.Error("failed to start login", zap.String("login", login), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to start login: %w", err)
+	}
+
+	clientProof, serverProof, err := server.Proofs(clientKey)
+	if err != nil {
+		return nil, ErrInvalidPublicKey
+	}
+
+	session := &models.LoginSession{
+		ID:          uuid.NewString(),
+		Login:       login,
+		ClientProof: clientProof,
+		ServerProof: serverProof,
+		ExpiresAt:   time.Now().Add(loginSessionTTL),
+	}
+
+	if err := a.userRepo.SaveLoginSession(ctx, session); err != nil {
+		logger.Error("failed to save login session", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to save login session: %w", err)
+	}
+
+	return &models.LoginChallenge{
+		SessionID: session.ID,
+		Salt:      salt,
+		ServerKey: server.PublicKey(),
+	}, nil
+}
+
+// LoginFinish finishes started login with proof of the password and returns access token together with
+// proof of the server. Each login can be finished once, whether the proof is correct or not.
+func (a *authServ) LoginFinish(ctx context.Context, login, sessionID string, clientProof []byte) (string, []byte, error) {
+	session, err := a.userRepo.ConsumeLoginSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, storage.ErrLoginSessionInvalid) {
+			return "", nil, err
+		}
+
+		logger.Error("failed to consume login session", zap.Error(err))
+
+		return "", nil, fmt.Errorf("failed to consume login session: %w", err)
+	}
+
+	if session.Login != login || subtle.ConstantTimeCompare(session.ClientProof, clientProof) != 1 {
+		logger.Warn("invalid proof of password", zap.String("login", login))
+
+		return "", nil, ErrInvalidCredentials
+	}
+
+	user, err := a.userRepo.GetUser(ctx, login)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return "", nil, ErrInvalidCredentials
+		}
+
+		logger.Error("failed to get user", zap.Error(err))
+
+		return "", nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Disabled {
+		logger.Info("disabled user tried to login:", zap.String("login", login))
+
+		return "", nil, ErrUserDisabled
+	}
+
+	token, err := utils.GenerateToken(*user, []byte(a.jwtSecret), a.tokenTTL)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	logger.Info("user logged in successfully:", zap.String("login", login))
+
+	return token, session.ServerProof, nil
+}
+
+// fakeVerifier returns salt and verifier of unknown user derived from the signing key,
+// nobody knows the password matching them.
+func (a *authServ) fakeVerifier(login string) (salt, verifier []byte) {
+	mac := hmac.New(sha256.New, []byte(a.jwtSecret))
+	mac.Write([]byte("srp-salt:" + login))
+	salt = mac.Sum(nil)[:srp.SaltSize]
+
+	mac.Reset()
+	mac.Write([]byte("srp-key:" + login))
+
+	return salt, srp.VerifierFromKey(mac.Sum(nil))
+}
+
+// verifierOf returns salt and verifier of the credentials, computing them from password if it is given.
+func verifierOf(login string, cred models.Credentials) (salt, verifier []byte, err error) {
+	if cred.Password != "" {
+		salt, verifier, err = srp.NewVerifier(login, cred.Password)
+		if err != nil {
+			logger.Error("failed to compute verifier", zap.Error(err))
+
+			return nil, nil, fmt.Errorf("failed to compute verifier: %w", err)
+		}
+
+		return salt, verifier, nil
+	}
+
+	if len(cred.Salt) < srp.SaltSize || !srp.ValidVerifier(cred.Verifier) {
+		return nil, nil, ErrInvalidVerifier
+	}
+
+	return cred.Salt, cred.Verifier, nil
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	utils "github.com/igortoigildin/goph-keeper/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrAPITokenInvalid = storage.NewError(storage.ErrUnauthenticated, "API_TOKEN_INVALID", "API token is invalid, expired or revoked")
+	ErrTokenExpiry     = storage.NewError(storage.ErrInvalidArgument, "TOKEN_EXPIRY_INVALID", "token must expire in the future within the allowed lifetime")
+)
+
+type TokenRepository interface {
+	SaveToken(ctx context.Context, token *models.APIToken) (*models.APIToken, error)
+	GetToken(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	ListTokens(ctx context.Context, login string) ([]models.APIToken, error)
+	RevokeToken(ctx context.Context, login string, id string) error
+}
+
+// CreateToken creates API token of the caller with the scope, which is valid until expiresAt.
+// The token is returned once, only its hash is stored.
+func (a *authServ) CreateToken(ctx context.Context, name string, expiresAt time.Time, scope models.TokenScope) (string, *models.APIToken, error) {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return "", nil, storage.ErrNotLoggedIn
+	}
+
+	if now := time.Now(); !expiresAt.After(now) || expiresAt.After(now.Add(a.apiTokenMaxTTL)) {
+		return "", nil, ErrTokenExpiry
+	}
+
+	secret, err := utils.NewRandomToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := models.APITokenPrefix + secret
+
+	saved, err := a.tokenRepo.SaveToken(ctx, &models.APIToken{
+		ID:         uuid.NewString(),
+		Login:      claims.Login,
+		Name:       name,
+		Hash:       utils.HashToken(token),
+		TokenScope: scope,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenExists) {
+			return "", nil, err
+		}
+
+		logger.Error("failed to save token", zap.Error(err))
+
+		return "", nil, fmt.Errorf("failed to save token: %w", err)
+	}
+
+	logger.Info("API token created:", zap.String("login", claims.Login), zap.String("id", saved.ID))
+
+	return token, saved, nil
+}
+
+// ListTokens returns API tokens of the caller, which are not revoked, expired ones included.
+func (a *authServ) ListTokens(ctx context.Context) ([]models.APIToken, error) {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, storage.ErrNotLoggedIn
+	}
+
+	tokens, err := a.tokenRepo.ListTokens(ctx, claims.Login)
+	if err != nil {
+		logger.Error("failed to list tokens", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken revokes API token of the caller with id.
+func (a *authServ) RevokeToken(ctx context.Context, id string) error {
+	claims, ok := interceptors.ClaimsFromContext(ctx)
+	if !ok {
+		return storage.ErrNotLoggedIn
+	}
+
+	err := a.tokenRepo.RevokeToken(ctx, claims.Login, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return err
+		}
+
+		logger.Error("failed to revoke token", zap.Error(err))
+
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+
+	logger.Info("API token revoked:", zap.String("login", claims.Login), zap.String("id", id))
+
+	return nil
+}
+
+// AuthenticateToken returns claims of the user, who created API token, restricted by the scope of the token.
+// Tokens of disabled users and tokens created before the user was logged out are rejected, like access tokens.
+func (a *authServ) AuthenticateToken(ctx context.Context, token string) (*models.UserClaims, error) {
+	saved, err := a.tokenRepo.GetToken(ctx, utils.HashToken(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrTokenNotFound) {
+			return nil, ErrAPITokenInvalid
+		}
+
+		logger.Error("failed to get token", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+
+	if !time.Now().Before(saved.ExpiresAt) {
+		return nil, ErrAPITokenInvalid
+	}
+
+	if err := a.CheckSession(ctx, saved.Login, saved.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &models.UserClaims{
+		StandardClaims: jwt.StandardClaims{
+			Id:        saved.ID,
+			IssuedAt:  saved.CreatedAt.Unix(),
+			ExpiresAt: saved.ExpiresAt.Unix(),
+		},
+		Login: saved.Login,
+		Scope: &saved.TokenScope,
+	}, nil
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
 	rep "github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
@@ -20,40 +22,57 @@ const (
 	bankData      = "bank_data"
 	textData      = "text_data"
 	binData       = "bin_data"
+	customData    = "custom_data"
+	folderData    = "folder_data"
+
+	// presignedURLExpiry is how long presigned urls stay valid.
+	presignedURLExpiry = 15 * time.Minute
 )
 
+var ErrAccessDenied = rep.NewError(rep.ErrPermissionDenied, "ACCESS_DENIED", "data belongs to another user")
+
 type AccessRepository interface {
 	GetAccess(ctx context.Context, login string, id string) (*models.FileInfo, error)
 	SaveAccess(ctx context.Context, login string, id string) error
 }
 
+type RefRepository interface {
+	GetRef(ctx context.Context, id string) (*models.FileRef, error)
+}
+
 type DownloadService struct {
 	dataRepository   rep.DataRepository
 	accessRepository AccessRepository
+	refRepository    RefRepository
 }
 
-func New(ctx context.Context, dataRep rep.DataRepository, accessRep AccessRepository) *DownloadService {
-	return &DownloadService{dataRepository: dataRep, accessRepository: accessRep}
+func New(ctx context.Context, dataRep rep.DataRepository, accessRep AccessRepository, refRep RefRepository) *DownloadService {
+	return &DownloadService{dataRepository: dataRep, accessRepository: accessRep, refRepository: refRep}
 }
 
 // DownloadFile checks whether user is authorized to download file with certain id,
-// if so, downloading begins from storage and file is being returned, if not - returns error.
-func (d *DownloadService) DownloadFile(ctx context.Context, id string) ([]byte, string, error) {
+// if so, requested range of the file is opened for reading from storage, if not - returns error.
+// If length is zero, file is read from offset till the end.
+func (d *DownloadService) DownloadFile(ctx context.Context, id string, offset, length int64) (*models.FileObject, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, binData) {
+		return nil, rep.ErrOutOfScope
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metadata is not received from incoming context")
 
-		return nil, "", errors.New("metada not received from md")
+		return nil, rep.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metadata is emty")
 
-		return nil, "", errors.New("md is empty")
+		return nil, rep.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return nil, "", errors.New("login is needed")
+		return nil, rep.ErrLoginRequired
 	}
 
 	login := md[login][0]
@@ -65,42 +84,131 @@ func (d *DownloadService) DownloadFile(ctx context.Context, id string) ([]byte,
 	if err != nil {
 		logger.Error("failed to get access for file", zap.Error(err))
 
-		return nil, "", fmt.Errorf("error getting access for specific file from repo: %w", err)
+		return nil, fmt.Errorf("error getting access for specific file from repo: %w", err)
+	}
+
+	// check whether user is authorized to get access to this specific file
+	if fileInfo.Login != login {
+		logger.Info("Authorization error")
+
+		return nil, ErrAccessDenied
+	}
+
+	ref, err := d.refRepository.GetRef(ctx, id)
+	if errors.Is(err, rep.ErrRefNotFound) {
+		// file saved before deduplication was introduced is stored under its id
+		file, err := d.dataRepository.DownloadFile(ctx, login, id, offset, length)
+		if err != nil {
+			return nil, fmt.Errorf("error downloading file from repository: %w", err)
+		}
+
+		return file, nil
+	}
+
+	if err != nil {
+		logger.Error("failed to get file reference", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting file reference: %w", err)
+	}
+
+	file, err := d.dataRepository.DownloadBlob(ctx, login, ref.BlobKey, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading file from repository: %w", err)
+	}
+
+	// blob is shared by files with the same content, so attributes of the file are kept in its reference
+	file.Metadata = ref.Metadata
+	file.Checksum = ref.Checksum
+	file.Compression = ref.Compression
+
+	return file, nil
+}
+
+// PresignDownload checks whether user is authorized to download file with certain id,
+// if so, returns presigned url for downloading the file directly from storage.
+func (d *DownloadService) PresignDownload(ctx context.Context, id string) (*models.PresignedURL, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, binData) {
+		return nil, rep.ErrOutOfScope
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		logger.Error("metadata is not received from incoming context")
+
+		return nil, rep.ErrMetadataMissing
+	}
+
+	if len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return nil, rep.ErrLoginRequired
+	}
+
+	// remove @ since this charac is not allowed for Minio bucket name
+	login := strings.Replace(md[login][0], "@", "", -1)
+
+	// get metadata about file with provided id
+	fileInfo, err := d.accessRepository.GetAccess(ctx, login, id)
+	if err != nil {
+		logger.Error("failed to get access for file", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting access for specific file from repo: %w", err)
 	}
 
 	// check whether user is authorized to get access to this specific file
 	if fileInfo.Login != login {
 		logger.Info("Authorization error")
 
-		return nil, "", errors.New("authorization error")
+		return nil, ErrAccessDenied
+	}
+
+	ref, err := d.refRepository.GetRef(ctx, id)
+	if errors.Is(err, rep.ErrRefNotFound) {
+		url, err := d.dataRepository.PresignedGetURL(ctx, login, id, presignedURLExpiry)
+		if err != nil {
+			return nil, fmt.Errorf("error presigning download url: %w", err)
+		}
+
+		return url, nil
 	}
 
-	file, metadata, err := d.dataRepository.DownloadFile(ctx, login, id)
 	if err != nil {
-		return nil, "", fmt.Errorf("error downloading file from repository: %w", err)
+		logger.Error("failed to get file reference", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting file reference: %w", err)
 	}
 
-	logger.Info("File successfully downloaded from Minio bucket ")
+	url, err := d.dataRepository.PresignedBlobURL(ctx, login, ref.BlobKey, presignedURLExpiry)
+	if err != nil {
+		return nil, fmt.Errorf("error presigning download url: %w", err)
+	}
+	url.Metadata = ref.Metadata
+	url.Checksum = ref.Checksum
+	url.Compression = ref.Compression
 
-	return file.Bytes(), metadata, nil
+	return url, nil
 }
 
 func (d *DownloadService) DownloadBankData(ctx context.Context, id string) (map[string]string, string, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, bankData) {
+		return nil, "", rep.ErrOutOfScope
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metada is not received from incoming context")
 
-		return nil, "", errors.New("metada not received from md")
+		return nil, "", rep.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metada is emty")
 
-		return nil, "", errors.New("md is empty")
+		return nil, "", rep.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return nil, "", errors.New("login is needed")
+		return nil, "", rep.ErrLoginRequired
 	}
 
 	login := md["login"][0]
@@ -119,42 +227,58 @@ func (d *DownloadService) DownloadBankData(ctx context.Context, id string) (map[
 	if fileInfo.Login != login {
 		logger.Info("Authorization error")
 
-		return nil, "", errors.New("authorization error")
+		return nil, "", ErrAccessDenied
 	}
 
-	data, metadata, err := d.dataRepository.DownloadTextData(ctx, login, id, bankData)
+	obj, err := d.dataRepository.DownloadTextData(ctx, login, id, bankData)
 	if err != nil {
-		return nil, "", fmt.Errorf("error downloading bank details: %s", err)
+		return nil, "", fmt.Errorf("error downloading bank details: %w", err)
 	}
 
 	res := make(map[string]string, 3)
 
-	err = json.Unmarshal(data, &res)
+	err = json.Unmarshal(obj.Data, &res)
 	if err != nil {
 		logger.Error("error unmarshalling JSON:", zap.Error(err))
 
 		return nil, "", fmt.Errorf("error unmarshalling JSON: %w", err)
 	}
 
-	return res, metadata, nil
+	return res, obj.Metadata, nil
+}
+
+// DownloadText returns text saved by the user. Text is returned as stored, so if it was
+// compressed by the client, algorithm from object metadata is returned for client to decompress it.
+func (d *DownloadService) DownloadText(ctx context.Context, id string) (*models.TextObject, error) {
+	return d.downloadText(ctx, id, textData)
+}
+
+// DownloadFolder returns manifest of the folder saved by the user.
+// storage.ErrObjectNotFound is returned if there is no folder with the id.
+func (d *DownloadService) DownloadFolder(ctx context.Context, id string) (*models.TextObject, error) {
+	return d.downloadText(ctx, id, folderData)
 }
 
-func (d *DownloadService) DownloadText(ctx context.Context, id string) (string, string, error) {
+func (d *DownloadService) downloadText(ctx context.Context, id, dataType string) (*models.TextObject, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, dataType) {
+		return nil, rep.ErrOutOfScope
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metada is not received from incoming context")
 
-		return "", "", errors.New("metada not received from md")
+		return nil, rep.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metada is emty")
 
-		return "", "", errors.New("md is empty")
+		return nil, rep.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return "", "", errors.New("login is needed")
+		return nil, rep.ErrLoginRequired
 	}
 
 	login := md["login"][0]
@@ -166,40 +290,44 @@ func (d *DownloadService) DownloadText(ctx context.Context, id string) (string,
 	if err != nil {
 		logger.Error("failed to get access for file")
 
-		return "", "", fmt.Errorf("error getting access for specific file from repo: %w", err)
+		return nil, fmt.Errorf("error getting access for specific file from repo: %w", err)
 	}
 
 	// check whether user is authorized to get access to this specific file
 	if fileInfo.Login != login {
 		logger.Info("Authorization error")
 
-		return "", "", errors.New("authorization error")
+		return nil, ErrAccessDenied
 	}
 
-	data, metadata, err := d.dataRepository.DownloadTextData(ctx, login, id, textData)
+	obj, err := d.dataRepository.DownloadTextData(ctx, login, id, dataType)
 	if err != nil {
-		return "", "", fmt.Errorf("error downloading text: %s", err)
+		return nil, fmt.Errorf("error downloading text: %w", err)
 	}
 
-	return string(data), metadata, nil
+	return obj, nil
 }
 
 func (d *DownloadService) DownloadLoginPassword(ctx context.Context, id string) (map[string]string, string, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, loginPassword) {
+		return nil, "", rep.ErrOutOfScope
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metada is not received from incoming context")
 
-		return nil, "", errors.New("metada not received from md")
+		return nil, "", rep.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metada is emty")
 
-		return nil, "", errors.New("md is empty")
+		return nil, "", rep.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return nil, "", errors.New("login is needed")
+		return nil, "", rep.ErrLoginRequired
 	}
 
 	login := md["login"][0]
@@ -218,24 +346,118 @@ func (d *DownloadService) DownloadLoginPassword(ctx context.Context, id string)
 	if fileInfo.Login != login {
 		logger.Info("Authorization error")
 
-		return nil, "", errors.New("authorization error")
+		return nil, "", ErrAccessDenied
 	}
 
-	data, metadata, err := d.dataRepository.DownloadTextData(ctx, login, id, loginPassword)
+	obj, err := d.dataRepository.DownloadTextData(ctx, login, id, loginPassword)
 	if err != nil {
 		logger.Error("error downloading login credentials: ", zap.Error(err))
 
-		return nil, "", fmt.Errorf("error downloading login credentials: %s", err)
+		return nil, "", fmt.Errorf("error downloading login credentials: %w", err)
 	}
 
 	res := make(map[string]string, 3)
 
-	err = json.Unmarshal(data, &res)
+	err = json.Unmarshal(obj.Data, &res)
 	if err != nil {
 		logger.Error("error unmarshalling JSON:", zap.Error(err))
 
 		return nil, "", fmt.Errorf("error unmarshalling JSON: %w", err)
 	}
 
-	return res, metadata, nil
+	return res, obj.Metadata, nil
+}
+
+// DownloadCustomData returns fields of the secret saved with SaveCustomData together with additional info.
+func (d *DownloadService) DownloadCustomData(ctx context.Context, id string) (map[string]string, string, error) {
+	login, err := d.authorize(ctx, id, customData)
+	if err != nil {
+		return nil, "", err
+	}
+
+	obj, err := d.dataRepository.DownloadTextData(ctx, login, id, customData)
+	if err != nil {
+		return nil, "", fmt.Errorf("error downloading custom data: %w", err)
+	}
+
+	res := make(map[string]string)
+
+	err = json.Unmarshal(obj.Data, &res)
+	if err != nil {
+		logger.Error("error unmarshalling JSON:", zap.Error(err))
+
+		return nil, "", fmt.Errorf("error unmarshalling JSON: %w", err)
+	}
+
+	return res, obj.Metadata, nil
+}
+
+// StatFile returns attributes of the file with certain id without opening it.
+func (d *DownloadService) StatFile(ctx context.Context, id string) (*models.FileObject, error) {
+	login, err := d.authorize(ctx, id, binData)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, err := d.refRepository.GetRef(ctx, id)
+	if errors.Is(err, rep.ErrRefNotFound) {
+		file, err := d.dataRepository.StatFile(ctx, login, id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting file attributes: %w", err)
+		}
+
+		return file, nil
+	}
+
+	if err != nil {
+		logger.Error("failed to get file reference", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting file reference: %w", err)
+	}
+
+	return &models.FileObject{
+		Size:        ref.Size,
+		Metadata:    ref.Metadata,
+		Checksum:    ref.Checksum,
+		Compression: ref.Compression,
+	}, nil
+}
+
+// authorize returns login of the user from incoming metadata, if the user has access to data with id
+// and the data of the type is within scope of the request.
+func (d *DownloadService) authorize(ctx context.Context, id, dataType string) (string, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, dataType) {
+		return "", rep.ErrOutOfScope
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		logger.Error("metadata is not received from incoming context")
+
+		return "", rep.ErrMetadataMissing
+	}
+
+	if len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return "", rep.ErrLoginRequired
+	}
+
+	// remove @ since this charac is not allowed for Minio bucket name
+	login := strings.Replace(md[login][0], "@", "", -1)
+
+	fileInfo, err := d.accessRepository.GetAccess(ctx, login, id)
+	if err != nil {
+		logger.Error("failed to get access for data", zap.Error(err))
+
+		return "", fmt.Errorf("error getting access for specific file from repo: %w", err)
+	}
+
+	if fileInfo.Login != login {
+		logger.Info("Authorization error")
+
+		return "", ErrAccessDenied
+	}
+
+	return login, nil
 }
@@ -8,58 +8,169 @@ import (
 
 	model "github.com/igortoigildin/goph-keeper/internal/server/models"
 	rep "github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
 
 const (
-	login = "login"
+	login   = "login"
+	binData = "bin_data"
+	blob    = "blob"
 )
 
+// dataTypes are data types objects are named by, along with their ids.
+var dataTypes = []string{"login_password", "bank_data", "text_data", "folder_data", binData, "custom_data"}
+
 type AccessRepository interface {
 	GetAccess(ctx context.Context, login string, id string) (*model.FileInfo, error)
 	SaveAccess(ctx context.Context, login string, id string) error
 }
 
+type RefRepository interface {
+	GetRef(ctx context.Context, id string) (*model.FileRef, error)
+	ListRefs(ctx context.Context, login string) ([]model.FileRef, error)
+}
+
 type ListService struct {
 	dataRepository   rep.DataRepository
 	accessRepository AccessRepository
+	refRepository    RefRepository
 }
 
-func New(ctx context.Context, dataRep rep.DataRepository, accessRep AccessRepository) *ListService {
-	return &ListService{dataRepository: dataRep, accessRepository: accessRep}
+func New(ctx context.Context, dataRep rep.DataRepository, accessRep AccessRepository, refRep RefRepository) *ListService {
+	return &ListService{dataRepository: dataRep, accessRepository: accessRep, refRepository: refRep}
 }
 
 func (l *ListService) List(ctx context.Context) ([]model.ObjectInfo, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	objs, err := l.dataRepository.ListObjects(ctx, login)
+	if err != nil {
+		logger.Error("failed to list objects", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing objects: %w", err)
+	}
+
+	refs, err := l.refRepository.ListRefs(ctx, login)
+	if err != nil {
+		logger.Error("failed to list file references", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing file references: %w", err)
+	}
+
+	// API token may grant access to a part of data only
+	scope := interceptors.ScopeFromContext(ctx)
+
+	// Blobs are internal to storage, files stored in them are listed under their ids instead.
+	res := make([]model.ObjectInfo, 0, len(objs)+len(refs))
+	for _, obj := range objs {
+		if strings.HasPrefix(obj.Key, blob+"_") {
+			continue
+		}
+
+		// objects are named by data type and id
+		if i := strings.LastIndex(obj.Key, "_"); !scope.Allows(obj.Key[i+1:], obj.Key[:max(i, 0)]) {
+			continue
+		}
+
+		res = append(res, obj)
+	}
+
+	for _, ref := range refs {
+		if !scope.Allows(ref.DataID, binData) {
+			continue
+		}
+
+		res = append(res, model.ObjectInfo{
+			Key:          binData + "_" + ref.DataID,
+			Size:         ref.Size,
+			LastModified: ref.UpdatedAt,
+			ETag:         ref.ETag,
+			Datatype:     binData,
+		})
+	}
+
+	return res, nil
+}
 
+// Object returns info of the data with certain id, the same way List lists it.
+// Returns storage.ErrObjectNotFound if the user has no such data.
+func (l *ListService) Object(ctx context.Context, id string) (*model.ObjectInfo, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// API token may grant access to a part of data only
+	scope := interceptors.ScopeFromContext(ctx)
+
+	ref, err := l.refRepository.GetRef(ctx, id)
+	if err != nil && !errors.Is(err, rep.ErrRefNotFound) {
+		logger.Error("failed to get file reference", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting file reference: %w", err)
+	}
+
+	if err == nil && ref.Login == login {
+		if !scope.Allows(id, binData) {
+			return nil, rep.ErrObjectNotFound
+		}
+
+		return &model.ObjectInfo{
+			Key:          binData + "_" + id,
+			Size:         ref.Size,
+			LastModified: ref.UpdatedAt,
+			ETag:         ref.ETag,
+			Datatype:     binData,
+		}, nil
+	}
+
+	for _, dataType := range dataTypes {
+		if !scope.Allows(id, dataType) {
+			continue
+		}
+
+		obj, err := l.dataRepository.StatObject(ctx, login, dataType+"_"+id)
+		if errors.Is(err, rep.ErrObjectNotFound) {
+			continue
+		}
+
+		if err != nil {
+			logger.Error("failed to get object info", zap.Error(err))
+
+			return nil, fmt.Errorf("error getting object info: %w", err)
+		}
+
+		return obj, nil
+	}
+
+	return nil, rep.ErrObjectNotFound
+}
+
+// loginFromContext returns login of the user from incoming metadata.
+func loginFromContext(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metadata is not received from incoming context")
 
-		return nil, errors.New("metada not received from md")
+		return "", rep.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metadata is emty")
 
-		return nil, errors.New("md is empty")
+		return "", rep.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return nil, errors.New("login is needed")
+		return "", rep.ErrLoginRequired
 	}
 
-	login := md[login][0]
 	// remove @ since this charac is not allowed for Minio bucket name
-	login = strings.Replace(login, "@", "", -1)
-
-	objs, err := l.dataRepository.ListObjects(ctx, login)
-	if err != nil {
-		logger.Error("failed to list objects", zap.Error(err))
-
-		return nil, fmt.Errorf("error listing objects: %w", err)
-	}
-
-	return objs, nil
+	return strings.Replace(md[login][0], "@", "", -1), nil
 }
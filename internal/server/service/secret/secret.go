@@ -0,0 +1,547 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/service"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	login = "login"
+	id    = "id"
+
+	// update paths
+	PathName    = "name"
+	PathTags    = "tags"
+	PathPayload = "payload"
+)
+
+var (
+	ErrInvalidSecret = storage.NewError(storage.ErrInvalidArgument, "INVALID_SECRET", "secret payload is missing or does not match its type")
+	ErrInvalidPath   = storage.NewError(storage.ErrInvalidArgument, "INVALID_UPDATE_PATH", "unsupported update path")
+	ErrAccessDenied  = storage.NewError(storage.ErrPermissionDenied, "ACCESS_DENIED", "data belongs to another user")
+)
+
+// dataTypes maps secret types to data types v1 services store the payload under.
+var dataTypes = map[models.SecretType]string{
+	models.SecretTypeCredential: "login_password",
+	models.SecretTypeCard:       "bank_data",
+	models.SecretTypeText:       "text_data",
+	models.SecretTypeFile:       "bin_data",
+	models.SecretTypeCustom:     "custom_data",
+}
+
+type AccessRepository interface {
+	GetAccess(ctx context.Context, login string, id string) (*models.FileInfo, error)
+}
+
+type SecretRepository interface {
+	CreateSecret(ctx context.Context, secret *models.SecretInfo) (*models.SecretInfo, error)
+	GetSecret(ctx context.Context, id string) (*models.SecretInfo, error)
+	UpdateSecret(ctx context.Context, secret *models.SecretInfo, version int64) (*models.SecretInfo, error)
+	DeleteSecret(ctx context.Context, id string) error
+	ListSecrets(ctx context.Context, login string, filter models.SecretFilter) ([]models.SecretInfo, error)
+}
+
+// SecretService serves secrets of all types through a single resource. Payloads are saved
+// and loaded by the same upload and download services as v1 data, so both APIs share secrets,
+// while name, tags and version of the secret are kept in the secret index.
+type SecretService struct {
+	uploadService    service.UploadService
+	downloadService  service.DownloadService
+	listService      service.ListService
+	accessRepository AccessRepository
+	secretRepository SecretRepository
+}
+
+func New(
+	ctx context.Context,
+	uploadSrv service.UploadService,
+	downloadSrv service.DownloadService,
+	listSrv service.ListService,
+	accessRep AccessRepository,
+	secretRep SecretRepository,
+) *SecretService {
+	return &SecretService{
+		uploadService:    uploadSrv,
+		downloadService:  downloadSrv,
+		listService:      listSrv,
+		accessRepository: accessRep,
+		secretRepository: secretRep,
+	}
+}
+
+// Create saves the secret, id is generated if it is empty. Secret of file type registers
+// the file uploaded beforehand under the same id, other types save their payload.
+func (s *SecretService) Create(ctx context.Context, secret *models.Secret) (*models.Secret, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePayload(secret); err != nil {
+		return nil, err
+	}
+
+	if secret.ID == "" {
+		secret.ID = uuid.NewString()
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(secret.ID, dataTypes[secret.Type]) {
+		return nil, storage.ErrOutOfScope
+	}
+	secret.Login = login
+
+	access, err := s.accessRepository.GetAccess(ctx, login, secret.ID)
+	switch {
+	case errors.Is(err, storage.ErrAccessNotFound):
+		if secret.Type == models.SecretTypeFile {
+			return nil, storage.ErrObjectNotFound
+		}
+	case err != nil:
+		logger.Error("failed to get access", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting access: %w", err)
+	case access.Login != login:
+		return nil, ErrAccessDenied
+	case secret.Type != models.SecretTypeFile:
+		// data saved with v1 under the id is updated instead
+		return nil, storage.ErrSecretExists
+	}
+
+	etag, err := s.savePayload(withID(ctx, secret.ID), secret)
+	if err != nil {
+		return nil, err
+	}
+	secret.ETag = etag
+
+	info, err := s.secretRepository.CreateSecret(ctx, &secret.SecretInfo)
+	if err != nil {
+		logger.Error("error saving secret: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error saving secret: %w", err)
+	}
+	secret.SecretInfo = *info
+
+	return secret, nil
+}
+
+// Get returns the secret with id, payload is loaded only if withPayload is set.
+// Data saved with v1 is added to the secret index on first access.
+func (s *SecretService) Get(ctx context.Context, id string, withPayload bool) (*models.Secret, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.secretRepository.GetSecret(ctx, id)
+	if errors.Is(err, storage.ErrSecretNotFound) {
+		return s.adopt(ctx, login, id)
+	}
+
+	if err != nil {
+		logger.Error("error getting secret: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting secret: %w", err)
+	}
+
+	if info.Login != login {
+		return nil, ErrAccessDenied
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(info.ID, dataTypes[info.Type]) {
+		return nil, storage.ErrOutOfScope
+	}
+
+	secret := &models.Secret{SecretInfo: *info}
+	if withPayload {
+		if _, err := s.loadPayload(ctx, secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return secret, nil
+}
+
+// Update changes fields of the secret listed in paths, all fields are changed if paths are empty.
+// Type of the secret can not be changed. If version is not zero, the secret is updated only
+// if its current version equals it.
+func (s *SecretService) Update(ctx context.Context, secret *models.Secret, paths []string, version int64) (*models.Secret, error) {
+	current, err := s.Get(ctx, secret.ID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// stale update must not replace the payload, which is saved before the index is updated
+	if version != 0 && current.Version != version {
+		return nil, storage.ErrVersionMismatch
+	}
+
+	if len(paths) == 0 {
+		paths = []string{PathName, PathTags, PathPayload}
+	}
+
+	var savePayload bool
+	for _, path := range paths {
+		switch path {
+		case PathName:
+			savePayload = savePayload || current.Name != secret.Name
+			current.Name = secret.Name
+		case PathTags:
+			current.Tags = secret.Tags
+		case PathPayload:
+			if err := validatePayload(secret); err != nil {
+				return nil, err
+			}
+
+			if secret.Type != current.Type {
+				return nil, ErrInvalidSecret
+			}
+
+			current.Credential, current.Card, current.Text, current.Custom =
+				secret.Credential, secret.Card, secret.Text, secret.Custom
+			savePayload = true
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+		}
+	}
+
+	// name is saved with the payload as v1 metadata, so the payload is saved again once it is renamed
+	if savePayload {
+		if !hasPayload(current) {
+			if _, err := s.loadPayload(ctx, current); err != nil {
+				return nil, err
+			}
+		}
+
+		etag, err := s.savePayload(withID(ctx, current.ID), current)
+		if err != nil {
+			return nil, err
+		}
+		current.ETag = etag
+	}
+
+	info, err := s.secretRepository.UpdateSecret(ctx, &current.SecretInfo, version)
+	if err != nil {
+		logger.Error("error updating secret: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error updating secret: %w", err)
+	}
+	current.SecretInfo = *info
+
+	return current, nil
+}
+
+// Delete deletes the secret together with its payload.
+func (s *SecretService) Delete(ctx context.Context, id string) error {
+	secret, err := s.Get(ctx, id, false)
+	if err != nil {
+		return err
+	}
+
+	ctx = withID(ctx, id)
+	if secret.Type == models.SecretTypeFile {
+		err = s.uploadService.DeleteFile(ctx)
+	} else {
+		err = s.uploadService.DeleteData(ctx, dataTypes[secret.Type])
+	}
+	if err != nil && !errors.Is(err, storage.ErrObjectNotFound) {
+		return err
+	}
+
+	err = s.secretRepository.DeleteSecret(ctx, id)
+	if err != nil {
+		logger.Error("error deleting secret: ", zap.Error(err))
+
+		return fmt.Errorf("error deleting secret: %w", err)
+	}
+
+	return nil
+}
+
+// List returns secrets of the user matching the filter, including data saved with v1,
+// which is not in the secret index yet. Payloads are loaded only if withPayload is set.
+func (s *SecretService) List(ctx context.Context, filter models.SecretFilter, withPayload bool) ([]*models.Secret, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	infos, err := s.secretRepository.ListSecrets(ctx, login, filter)
+	if err != nil {
+		logger.Error("error listing secrets: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing secrets: %w", err)
+	}
+
+	// data out of scope of the token is not listed, v1 data is filtered by list service
+	scope := interceptors.ScopeFromContext(ctx)
+
+	indexed := make(map[string]struct{}, len(infos))
+	res := make([]*models.Secret, 0, len(infos))
+	for _, info := range infos {
+		indexed[info.ID] = struct{}{}
+		if !scope.Allows(info.ID, dataTypes[info.Type]) {
+			continue
+		}
+		res = append(res, &models.Secret{SecretInfo: info})
+	}
+
+	// data saved with v1 has no tags
+	if filter.Tag == "" {
+		objs, err := s.listService.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			secretType, dataID, ok := parseKey(obj.Key)
+			if !ok || (filter.Type != "" && filter.Type != secretType) {
+				continue
+			}
+
+			if _, ok := indexed[dataID]; ok {
+				continue
+			}
+
+			res = append(res, &models.Secret{SecretInfo: models.SecretInfo{
+				ID:        dataID,
+				Login:     login,
+				Type:      secretType,
+				ETag:      obj.ETag,
+				CreatedAt: obj.LastModified,
+				UpdatedAt: obj.LastModified,
+			}})
+		}
+	}
+
+	if withPayload {
+		for _, secret := range res {
+			info, err := s.loadPayload(ctx, secret)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := indexed[secret.ID]; !ok {
+				secret.Name = info
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// adopt adds data saved with v1 under id to the secret index, additional info of the data becomes its name.
+func (s *SecretService) adopt(ctx context.Context, login, dataID string) (*models.Secret, error) {
+	access, err := s.accessRepository.GetAccess(ctx, login, dataID)
+	if errors.Is(err, storage.ErrAccessNotFound) {
+		return nil, storage.ErrSecretNotFound
+	}
+
+	if err != nil {
+		logger.Error("failed to get access", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting access: %w", err)
+	}
+
+	if access.Login != login {
+		return nil, ErrAccessDenied
+	}
+
+	obj, err := s.listService.Object(ctx, dataID)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, storage.ErrSecretNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	secretType, _, ok := parseKey(obj.Key)
+	if !ok {
+		return nil, storage.ErrSecretNotFound
+	}
+
+	secret := &models.Secret{SecretInfo: models.SecretInfo{ID: dataID, Login: login, Type: secretType, ETag: obj.ETag}}
+
+	info, err := s.loadPayload(ctx, secret)
+	if err != nil {
+		return nil, err
+	}
+	secret.Name = info
+
+	created, err := s.secretRepository.CreateSecret(ctx, &secret.SecretInfo)
+	if errors.Is(err, storage.ErrSecretExists) {
+		// adopted concurrently
+		return s.Get(ctx, dataID, true)
+	}
+	if err != nil {
+		logger.Error("error saving secret: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error saving secret: %w", err)
+	}
+	secret.SecretInfo = *created
+
+	return secret, nil
+}
+
+// savePayload saves payload of the secret with id provided in metadata and returns its etag.
+// Name of the secret is saved as additional info of the data, so v1 clients see it as metadata.
+func (s *SecretService) savePayload(ctx context.Context, secret *models.Secret) (string, error) {
+	switch secret.Type {
+	case models.SecretTypeCredential:
+		return s.uploadService.SaveLoginPassword(ctx, map[string]string{
+			"login":    secret.Credential.Login,
+			"password": secret.Credential.Password,
+			"metadata": secret.Name,
+		}, secret.Name)
+	case models.SecretTypeCard:
+		return s.uploadService.SaveBankData(ctx, map[string]string{
+			"card_number":     secret.Card.Number,
+			"CVC":             secret.Card.CVC,
+			"expiration_date": secret.Card.ExpDate,
+			"metadata":        secret.Name,
+		}, secret.Name)
+	case models.SecretTypeText:
+		return s.uploadService.SaveText(ctx, secret.Text.Text, secret.Name, secret.Text.Compression)
+	case models.SecretTypeCustom:
+		return s.uploadService.SaveCustomData(ctx, secret.Custom, secret.Name)
+	case models.SecretTypeFile:
+		// content of the file is uploaded with v1 upload rpcs
+		file, err := s.downloadService.StatFile(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+		secret.File = file
+
+		return file.Checksum, nil
+	default:
+		return "", ErrInvalidSecret
+	}
+}
+
+// loadPayload loads payload of the secret and returns additional info the data was saved with.
+func (s *SecretService) loadPayload(ctx context.Context, secret *models.Secret) (string, error) {
+	switch secret.Type {
+	case models.SecretTypeCredential:
+		data, info, err := s.downloadService.DownloadLoginPassword(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+		secret.Credential = &models.Credential{Login: data["login"], Password: data["password"]}
+
+		return info, nil
+	case models.SecretTypeCard:
+		data, info, err := s.downloadService.DownloadBankData(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+		secret.Card = &models.Card{Number: data["card_number"], CVC: data["CVC"], ExpDate: data["expiration_date"]}
+
+		return info, nil
+	case models.SecretTypeText:
+		obj, err := s.downloadService.DownloadText(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+
+		// text is stored as JSON string
+		var text string
+		if err := json.Unmarshal(obj.Data, &text); err != nil {
+			text = string(obj.Data)
+		}
+		secret.Text = &models.SecretText{Text: text, Compression: obj.Compression}
+
+		return obj.Metadata, nil
+	case models.SecretTypeCustom:
+		data, info, err := s.downloadService.DownloadCustomData(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+		secret.Custom = data
+
+		return info, nil
+	case models.SecretTypeFile:
+		file, err := s.downloadService.StatFile(ctx, secret.ID)
+		if err != nil {
+			return "", err
+		}
+		secret.File = file
+
+		return file.Metadata, nil
+	default:
+		return "", ErrInvalidSecret
+	}
+}
+
+// validatePayload checks that payload matching the type of the secret is set.
+func validatePayload(secret *models.Secret) error {
+	var ok bool
+	switch secret.Type {
+	case models.SecretTypeCredential:
+		ok = secret.Credential != nil
+	case models.SecretTypeCard:
+		ok = secret.Card != nil
+	case models.SecretTypeText:
+		ok = secret.Text != nil
+	case models.SecretTypeCustom:
+		ok = secret.Custom != nil
+	case models.SecretTypeFile:
+		ok = true
+	}
+
+	if !ok {
+		return ErrInvalidSecret
+	}
+
+	return nil
+}
+
+func hasPayload(secret *models.Secret) bool {
+	return secret.Credential != nil || secret.Card != nil || secret.Text != nil ||
+		secret.Custom != nil || secret.Type == models.SecretTypeFile
+}
+
+// parseKey returns type and id of the secret stored in object with key.
+func parseKey(key string) (models.SecretType, string, bool) {
+	for secretType, dataType := range dataTypes {
+		if dataID, ok := strings.CutPrefix(key, dataType+"_"); ok {
+			return secretType, dataID, true
+		}
+	}
+
+	return "", "", false
+}
+
+// withID returns context with id of the secret in incoming metadata, which is where
+// upload service expects it.
+func withID(ctx context.Context, dataID string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(id, dataID)
+
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+// loginFromContext returns login of the user from incoming metadata, @ is removed as in bucket names.
+func loginFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return "", storage.ErrLoginRequired
+	}
+
+	return strings.Replace(md[login][0], "@", "", -1), nil
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
+	"time"
 
 	model "github.com/igortoigildin/goph-keeper/internal/server/models"
 	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
@@ -9,23 +11,78 @@ import (
 
 type AuthService interface {
 	Login(ctx context.Context, email, password string) (string, error)
-	RegisterNewUser(ctx context.Context, Email string, pass string) (int64, error)
+	LoginStart(ctx context.Context, login string, clientKey []byte) (*model.LoginChallenge, error)
+	LoginFinish(ctx context.Context, login, sessionID string, clientProof []byte) (string, []byte, error)
+	RegisterNewUser(ctx context.Context, Email string, cred model.Credentials) (int64, error)
+	CheckSession(ctx context.Context, login string, issuedAt time.Time) error
+	ResetPassword(ctx context.Context, login, resetToken string, cred model.Credentials) error
+	CreateToken(ctx context.Context, name string, expiresAt time.Time, scope model.TokenScope) (string, *model.APIToken, error)
+	ListTokens(ctx context.Context) ([]model.APIToken, error)
+	RevokeToken(ctx context.Context, id string) error
+	AuthenticateToken(ctx context.Context, token string) (*model.UserClaims, error)
+	AuthenticateCert(ctx context.Context, cert *x509.Certificate) (*model.UserClaims, error)
 }
 
 type UploadService interface {
 	SaveFile(stream desc.UploadV1_UploadFileServer) error
 	SaveBankData(ctx context.Context, data map[string]string, info string) (string, error)
-	SaveText(ctx context.Context, text string, info string) (string, error)
+	SaveText(ctx context.Context, text string, info string, algo string) (string, error)
+	SaveFolder(ctx context.Context, manifest string, info string, algo string) (string, error)
 	SaveLoginPassword(ctx context.Context, data map[string]string, info string) (string, error)
+	InitUpload(ctx context.Context, fileName string, size int64, info string, algo string) (*model.UploadSession, error)
+	UploadChunk(stream desc.UploadV1_UploadChunkServer) error
+	UploadStatus(ctx context.Context, uploadID string) (*model.UploadSession, error)
+	FinalizeUpload(ctx context.Context, uploadID string, size int64, checksum string) (*model.UploadResult, error)
+	PresignUpload(ctx context.Context) (*model.PresignedURL, error)
+	CompleteUpload(ctx context.Context, fileName, info, checksum, algo string) (*model.UploadResult, error)
+	DeleteFile(ctx context.Context) error
+	SaveCustomData(ctx context.Context, data map[string]string, info string) (string, error)
+	DeleteData(ctx context.Context, dataType string) error
 }
 
 type DownloadService interface {
-	DownloadFile(ctx context.Context, id string) ([]byte, string, error)
+	DownloadFile(ctx context.Context, id string, offset, length int64) (*model.FileObject, error)
 	DownloadBankData(ctx context.Context, id string) (map[string]string, string, error)
-	DownloadText(ctx context.Context, id string) (string, string, error)
+	DownloadText(ctx context.Context, id string) (*model.TextObject, error)
+	DownloadFolder(ctx context.Context, id string) (*model.TextObject, error)
 	DownloadLoginPassword(ctx context.Context, id string) (map[string]string, string, error)
+	PresignDownload(ctx context.Context, id string) (*model.PresignedURL, error)
+	DownloadCustomData(ctx context.Context, id string) (map[string]string, string, error)
+	StatFile(ctx context.Context, id string) (*model.FileObject, error)
 }
 
 type ListService interface {
 	List(ctx context.Context) ([]model.ObjectInfo, error)
+	Object(ctx context.Context, id string) (*model.ObjectInfo, error)
+}
+
+type SecretService interface {
+	Create(ctx context.Context, secret *model.Secret) (*model.Secret, error)
+	Get(ctx context.Context, id string, withPayload bool) (*model.Secret, error)
+	Update(ctx context.Context, secret *model.Secret, paths []string, version int64) (*model.Secret, error)
+	Delete(ctx context.Context, id string) error
+	List(ctx context.Context, filter model.SecretFilter, withPayload bool) ([]*model.Secret, error)
+}
+
+type AuditService interface {
+	Query(ctx context.Context, filter model.AuditFilter) ([]model.AuditEvent, int64, error)
+}
+
+type AccountService interface {
+	GetUsage(ctx context.Context) (*model.Usage, model.Quota, error)
+}
+
+type AdminService interface {
+	Authorize(ctx context.Context) error
+	CreateUser(ctx context.Context, login, password, role string) error
+	ListUsers(ctx context.Context) ([]model.UserSummary, error)
+	SetRole(ctx context.Context, login, role string) error
+	DisableUser(ctx context.Context, login string) error
+	EnableUser(ctx context.Context, login string) error
+	LogoutUser(ctx context.Context, login string) error
+	IssueResetToken(ctx context.Context, login string) (string, time.Time, error)
+	DeleteUserData(ctx context.Context, login string) error
+	BindCert(ctx context.Context, login, identity string) error
+	UnbindCert(ctx context.Context, identity string) error
+	ListCerts(ctx context.Context, login string) ([]model.CertBinding, error)
 }
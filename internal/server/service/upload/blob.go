@@ -0,0 +1,121 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type BlobRepository interface {
+	AcquireBlob(ctx context.Context, blob *models.Blob) (*models.Blob, error)
+	ReleaseBlob(ctx context.Context, key string) (bool, error)
+	SaveRef(ctx context.Context, ref *models.FileRef) (string, error)
+	DeleteRef(ctx context.Context, id string) (*models.FileRef, error)
+}
+
+// storeBlob moves content of the uploaded file into the blob keyed by its checksum and points
+// the file to the blob, so the same content saved by the user under different ids is stored once.
+// Content already stored by another file is not copied. Blob previously referenced by the file is released.
+func (f *UploadService) storeBlob(ctx context.Context, login, id, checksum, info, algo string) (*models.FileRef, error) {
+	blob, err := f.dataRepository.NewBlob(ctx, login, id, checksum)
+	if err != nil {
+		logger.Error("error preparing blob: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error preparing blob: %w", err)
+	}
+
+	shared, err := f.blobRepository.AcquireBlob(ctx, blob)
+	if err != nil {
+		logger.Error("error acquiring blob: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error acquiring blob: %w", err)
+	}
+
+	// the content is copied only if it is not stored by another file of the user yet
+	if shared.Key == blob.Key {
+		if err := f.dataRepository.CopyToBlob(ctx, login, id, blob); err != nil {
+			logger.Error("error copying file to blob: ", zap.Error(err))
+
+			f.releaseBlob(ctx, login, blob.Key)
+
+			return nil, fmt.Errorf("error copying file to blob: %w", err)
+		}
+	}
+
+	ref := &models.FileRef{
+		DataID:      id,
+		Login:       login,
+		BlobKey:     shared.Key,
+		Checksum:    shared.Checksum,
+		Size:        shared.Size,
+		ETag:        shared.ETag,
+		Metadata:    info,
+		Compression: algo,
+	}
+
+	prev, err := f.blobRepository.SaveRef(ctx, ref)
+	if err != nil {
+		logger.Error("error saving file reference: ", zap.Error(err))
+
+		f.releaseBlob(ctx, login, shared.Key)
+
+		return nil, fmt.Errorf("error saving file reference: %w", err)
+	}
+
+	if prev != "" {
+		f.releaseBlob(ctx, login, prev)
+	}
+
+	// uploaded object is not needed anymore, since the content is kept in the blob
+	f.removeFile(ctx, login, id)
+
+	logger.Info("file stored in blob", zap.String("id", id), zap.String("blob", shared.Key),
+		zap.Bool("deduplicated", shared.Key != blob.Key))
+
+	return ref, nil
+}
+
+// unlinkBlob deletes reference of the file to its blob and releases the blob.
+// Reports whether the file referenced a blob.
+func (f *UploadService) unlinkBlob(ctx context.Context, login, id string) (bool, error) {
+	ref, err := f.blobRepository.DeleteRef(ctx, id)
+	if errors.Is(err, storage.ErrRefNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		logger.Error("error deleting file reference: ", zap.Error(err))
+
+		return false, fmt.Errorf("error deleting file reference: %w", err)
+	}
+
+	f.releaseBlob(ctx, login, ref.BlobKey)
+
+	return true, nil
+}
+
+// releaseBlob drops a reference to the blob and removes the blob from storage
+// once it is not referenced anymore. Errors are only logged, since the file is already saved.
+func (f *UploadService) releaseBlob(ctx context.Context, login, key string) {
+	collected, err := f.blobRepository.ReleaseBlob(ctx, key)
+	if err != nil {
+		logger.Error("error releasing blob: ", zap.Error(err))
+
+		return
+	}
+
+	if collected {
+		f.removeBlob(ctx, login, key)
+	}
+}
+
+func (f *UploadService) removeBlob(ctx context.Context, login, key string) {
+	if err := f.dataRepository.RemoveBlob(ctx, login, key); err != nil {
+		logger.Error("error removing blob: ", zap.Error(err))
+	}
+}
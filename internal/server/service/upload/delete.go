@@ -0,0 +1,103 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DeleteFile deletes file with id provided in metadata. Content of the file
+// is removed from storage once no other file of the user references it.
+func (f *UploadService) DeleteFile(ctx context.Context) error {
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(dataID, binData) {
+		return storage.ErrOutOfScope
+	}
+
+	exists, err := f.checkAccess(ctx, login, dataID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return storage.ErrAccessNotFound
+	}
+
+	unlinked, err := f.unlinkBlob(ctx, login, dataID)
+	if err != nil {
+		return err
+	}
+
+	// file saved before deduplication was introduced is stored as is
+	if !unlinked {
+		err = f.dataRepository.RemoveFile(ctx, login, dataID)
+		if err != nil {
+			logger.Error("error removing file: ", zap.Error(err))
+
+			return fmt.Errorf("error removing file: %w", err)
+		}
+	}
+
+	err = f.accessRepository.DeleteAccess(ctx, login, dataID)
+	if err != nil {
+		logger.Error("error deleting access: ", zap.Error(err))
+
+		return fmt.Errorf("error deleting access: %w", err)
+	}
+
+	f.release(ctx, login, dataID)
+
+	logger.Info("file deleted", zap.String("id", dataID))
+
+	return nil
+}
+
+// DeleteData deletes data of provided type with id provided in metadata.
+// Binary files are deleted with DeleteFile, since their content may be shared.
+func (f *UploadService) DeleteData(ctx context.Context, dataType string) error {
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(dataID, dataType) {
+		return storage.ErrOutOfScope
+	}
+
+	exists, err := f.checkAccess(ctx, login, dataID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return storage.ErrAccessNotFound
+	}
+
+	err = f.dataRepository.RemoveTextData(ctx, login, dataID, dataType)
+	if err != nil {
+		logger.Error("error removing data: ", zap.Error(err))
+
+		return fmt.Errorf("error removing data: %w", err)
+	}
+
+	err = f.accessRepository.DeleteAccess(ctx, login, dataID)
+	if err != nil {
+		logger.Error("error deleting access: ", zap.Error(err))
+
+		return fmt.Errorf("error deleting access: %w", err)
+	}
+
+	f.release(ctx, login, dataID)
+
+	logger.Info("data deleted", zap.String("id", dataID), zap.String("type", dataType))
+
+	return nil
+}
@@ -0,0 +1,211 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+// presignedURLExpiry is how long presigned urls stay valid.
+const presignedURLExpiry = 15 * time.Minute
+
+var (
+	ErrAccessDenied = storage.NewError(storage.ErrPermissionDenied, "ACCESS_DENIED", "data belongs to another user")
+	ErrEmptyData    = storage.NewError(storage.ErrInvalidArgument, "EMPTY_DATA", "data to be saved not provided")
+)
+
+// PresignUpload returns presigned POST url and form fields for uploading the file with id provided in metadata
+// directly to storage. CompleteUpload must be called once the file is uploaded.
+func (f *UploadService) PresignUpload(ctx context.Context) (*models.PresignedURL, error) {
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(dataID, binData) {
+		return nil, storage.ErrOutOfScope
+	}
+
+	if _, err := f.checkAccess(ctx, login, dataID); err != nil {
+		return nil, err
+	}
+
+	// storage rejects the file over the limit, the quota is checked again once the file is uploaded
+	limit, err := f.limit(ctx, login, dataID)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit == 0 {
+		return nil, f.exceeded(0)
+	}
+
+	url, err := f.dataRepository.PresignedPostURL(ctx, login, dataID, limit, presignedURLExpiry)
+	if err != nil {
+		logger.Error("error presigning upload url: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error presigning upload url: %w", err)
+	}
+
+	return url, nil
+}
+
+// CompleteUpload records metadata of the file uploaded through presigned url
+// and saves information about the user, who has right to access it.
+func (f *UploadService) CompleteUpload(ctx context.Context, fileName, info, checksum, algo string) (*models.UploadResult, error) {
+	if err := compression.Validate(algo); err != nil {
+		return nil, err
+	}
+
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(dataID, binData) {
+		return nil, storage.ErrOutOfScope
+	}
+
+	exists, err := f.checkAccess(ctx, login, dataID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := f.dataRepository.CompleteFile(ctx, login, dataID, info, checksum, algo)
+	if err != nil {
+		logger.Error("error completing upload: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error completing upload: %w", err)
+	}
+
+	refund, err := f.reserve(ctx, login, dataID, res.Size)
+	if err != nil {
+		// storage does not limit size of the object uploaded through presigned url
+		f.removeFile(ctx, login, dataID)
+
+		return nil, err
+	}
+
+	// Content is uploaded bypassing the server, so the checksum sent by the client
+	// is only compared with the one calculated over the stored object.
+	sum, err := f.hashFile(ctx, login, dataID)
+	if err != nil {
+		refund()
+
+		return nil, err
+	}
+
+	if checksum != "" && !strings.EqualFold(sum, checksum) {
+		logger.Error("checksum mismatch", zap.String("expected", checksum), zap.String("actual", sum))
+
+		f.removeFile(ctx, login, dataID)
+		refund()
+
+		return nil, ErrChecksumMismatch
+	}
+
+	ref, err := f.storeBlob(ctx, login, dataID, sum, info, algo)
+	if err != nil {
+		refund()
+
+		return nil, err
+	}
+	res.ETag = ref.ETag
+	res.Checksum = sum
+
+	// file uploaded again under the same id is already accessible by the user
+	if !exists {
+		err = f.accessRepository.SaveAccess(ctx, login, dataID)
+		if err != nil {
+			logger.Error("error saving access: ", zap.Error(err))
+
+			refund()
+
+			return nil, fmt.Errorf("error saving access: %w", err)
+		}
+	}
+
+	res.FileName = filepath.Base(fileName)
+
+	return res, nil
+}
+
+// hashFile returns hex encoded sha256 of the stored binary object.
+func (f *UploadService) hashFile(ctx context.Context, login, id string) (string, error) {
+	obj, err := f.dataRepository.DownloadFile(ctx, login, id, 0, 0)
+	if err != nil {
+		logger.Error("error opening uploaded file: ", zap.Error(err))
+
+		return "", fmt.Errorf("error opening uploaded file: %w", err)
+	}
+	defer obj.Reader.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, obj.Reader); err != nil {
+		logger.Error("error reading uploaded file: ", zap.Error(err))
+
+		return "", fmt.Errorf("error reading uploaded file: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func (f *UploadService) removeFile(ctx context.Context, login, id string) {
+	if err := f.dataRepository.RemoveFile(ctx, login, id); err != nil {
+		logger.Error("error removing uploaded file: ", zap.Error(err))
+	}
+}
+
+// checkAccess reports whether data with id is already saved by the user.
+// ErrAccessDenied is returned if the id is taken by another user.
+func (f *UploadService) checkAccess(ctx context.Context, login, id string) (bool, error) {
+	access, err := f.accessRepository.GetAccess(ctx, login, id)
+	if errors.Is(err, storage.ErrAccessNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		logger.Error("failed to get access", zap.Error(err))
+
+		return false, fmt.Errorf("error getting access: %w", err)
+	}
+
+	if access.Login != login {
+		logger.Info("Authorization error")
+
+		return true, ErrAccessDenied
+	}
+
+	return true, nil
+}
+
+// dataFromContext returns user login and item id from incoming metadata.
+func dataFromContext(ctx context.Context) (string, string, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md[id]) == 0 {
+		logger.Error("item id not provided")
+
+		return "", "", storage.ErrIDRequired
+	}
+
+	return login, md[id][0], nil
+}
@@ -0,0 +1,160 @@
+package upload
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+)
+
+var ErrObjectTooLarge = storage.NewError(storage.ErrQuotaExceeded, "OBJECT_TOO_LARGE", "object exceeds maximum size")
+
+type UsageRepository interface {
+	GetUsage(ctx context.Context, login string) (*models.Usage, error)
+	ObjectSize(ctx context.Context, login string, id string) (int64, bool, error)
+	Charge(ctx context.Context, login string, id string, size int64, quota models.Quota) (int64, bool, error)
+	Release(ctx context.Context, login string, id string) error
+}
+
+// limit returns the maximum size the object with id may have, so uploads of unknown size are aborted
+// as soon as it is exceeded. The user, who can not save one more object, is rejected right away.
+func (f *UploadService) limit(ctx context.Context, login, id string) (int64, error) {
+	limit := int64(math.MaxInt64)
+	if f.quota.MaxObjectSize > 0 {
+		limit = f.quota.MaxObjectSize
+	}
+
+	if f.quota.MaxBytes == 0 && f.quota.MaxObjects == 0 {
+		return limit, nil
+	}
+
+	usage, err := f.usageRepository.GetUsage(ctx, login)
+	if err != nil {
+		logger.Error("error getting usage: ", zap.Error(err))
+
+		return 0, fmt.Errorf("error getting usage: %w", err)
+	}
+
+	size, exists, err := f.usageRepository.ObjectSize(ctx, login, id)
+	if err != nil {
+		logger.Error("error getting object size: ", zap.Error(err))
+
+		return 0, fmt.Errorf("error getting object size: %w", err)
+	}
+
+	if !exists && f.quota.MaxObjects > 0 && usage.Objects >= f.quota.MaxObjects {
+		return 0, storage.ErrStorageQuotaExceeded
+	}
+
+	// the object saved again replaces its previous content
+	if f.quota.MaxBytes > 0 {
+		limit = min(limit, f.quota.MaxBytes-usage.Bytes+size)
+	}
+
+	// usage may exceed the quota lowered after the data was saved
+	return max(limit, 0), nil
+}
+
+// exceeded returns the error the object of size is rejected with.
+func (f *UploadService) exceeded(size int64) error {
+	if f.quota.MaxObjectSize > 0 && size > f.quota.MaxObjectSize {
+		return ErrObjectTooLarge
+	}
+
+	return storage.ErrStorageQuotaExceeded
+}
+
+// reserve charges size of the object with id to usage of the user before the object is committed,
+// so concurrent uploads can not exceed the quota together. Returned refund restores the usage
+// if the object fails to be saved.
+func (f *UploadService) reserve(ctx context.Context, login, id string, size int64) (func(), error) {
+	if f.quota.MaxObjectSize > 0 && size > f.quota.MaxObjectSize {
+		return nil, ErrObjectTooLarge
+	}
+
+	prev, existed, err := f.usageRepository.Charge(ctx, login, id, size, f.quota)
+	if errors.Is(err, storage.ErrStorageQuotaExceeded) {
+		logger.Info("storage quota exceeded", zap.String("login", login), zap.Int64("size", size))
+
+		return nil, err
+	}
+
+	if err != nil {
+		logger.Error("error charging usage: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error charging usage: %w", err)
+	}
+
+	refund := func() {
+		// usage must be restored even if the request is canceled
+		ctx := context.WithoutCancel(ctx)
+
+		var err error
+		if existed {
+			_, _, err = f.usageRepository.Charge(ctx, login, id, prev, models.Quota{})
+		} else {
+			err = f.usageRepository.Release(ctx, login, id)
+		}
+
+		if err != nil {
+			logger.Error("error refunding usage: ", zap.Error(err))
+		}
+	}
+
+	return refund, nil
+}
+
+// release subtracts size of the deleted object from usage of the user. Errors are only logged,
+// since the object is already deleted.
+func (f *UploadService) release(ctx context.Context, login, id string) {
+	if err := f.usageRepository.Release(ctx, login, id); err != nil {
+		logger.Error("error releasing usage: ", zap.Error(err))
+	}
+}
+
+// saveData saves data of the type once its size is charged to usage of the user,
+// together with information about the user, who has right to access it.
+func (f *UploadService) saveData(ctx context.Context, data any, login, id, info, dataType, algo string) (string, error) {
+	if !interceptors.ScopeFromContext(ctx).Allows(id, dataType) {
+		return "", storage.ErrOutOfScope
+	}
+
+	// nothing is charged or written, unless the id is free or taken by the user
+	if _, err := f.checkAccess(ctx, login, id); err != nil {
+		return "", err
+	}
+
+	// data is stored JSON encoded
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("serialization error: %w", err)
+	}
+
+	refund, err := f.reserve(ctx, login, id, int64(len(encoded)))
+	if err != nil {
+		return "", err
+	}
+
+	err = f.accessRepository.SaveAccess(ctx, login, id)
+	if err != nil {
+		refund()
+
+		return "", fmt.Errorf("error saving access: %w", err)
+	}
+
+	etag, err := f.dataRepository.SaveTextData(ctx, data, login, id, info, dataType, algo)
+	if err != nil {
+		refund()
+
+		return "", err
+	}
+
+	return etag, nil
+}
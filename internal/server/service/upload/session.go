@@ -0,0 +1,418 @@
+package upload
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
+)
+
+// PartSize is the number of bytes buffered in memory before a part is committed to storage.
+// Minio rejects parts smaller than 5 MiB, except the last one.
+const PartSize = 5 * 1024 * 1024
+
+// maxParts is the number of parts Minio accepts in a multipart upload.
+const maxParts = 10000
+
+var (
+	ErrUploadForbidden  = storage.NewError(storage.ErrPermissionDenied, "UPLOAD_FORBIDDEN", "upload session belongs to another user")
+	ErrUploadInProgress = storage.NewError(storage.ErrAborted, "UPLOAD_IN_PROGRESS", "upload session is already in progress")
+	ErrOffsetMismatch   = storage.NewError(storage.ErrAborted, "OFFSET_MISMATCH", "chunk offset does not match committed offset")
+	ErrSizeExceeded     = storage.NewError(storage.ErrInvalidArgument, "SIZE_EXCEEDED", "chunk exceeds declared file size")
+	ErrUploadIncomplete = storage.NewError(storage.ErrFailedPrecondition, "UPLOAD_INCOMPLETE", "upload is not complete")
+	ErrSizeMismatch     = storage.NewError(storage.ErrInvalidArgument, "SIZE_MISMATCH", "file size does not match declared size")
+	ErrChecksumMismatch = storage.NewError(storage.ErrInvalidArgument, "CHECKSUM_MISMATCH", "file checksum mismatch")
+	ErrFileTooLarge     = storage.NewError(storage.ErrInvalidArgument, "FILE_TOO_LARGE", "file exceeds the size of resumable upload")
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *models.UploadSession) error
+	GetSession(ctx context.Context, uploadID string) (*models.UploadSession, error)
+	UpdateProgress(ctx context.Context, uploadID string, committedOffset int64, partsCount int, hashState []byte) error
+	DeleteSession(ctx context.Context, uploadID string) error
+}
+
+// InitUpload starts new resumable upload of the file with id provided in metadata.
+// The file may be compressed by the client with algo, which is recorded in object metadata.
+func (f *UploadService) InitUpload(ctx context.Context, fileName string, size int64, info string, algo string) (*models.UploadSession, error) {
+	if err := compression.Validate(algo); err != nil {
+		return nil, err
+	}
+
+	// parts are committed by PartSize, larger file could not be completed
+	if size > maxParts*PartSize {
+		return nil, ErrFileTooLarge
+	}
+
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(dataID, binData) {
+		return nil, storage.ErrOutOfScope
+	}
+
+	if _, err := f.checkAccess(ctx, login, dataID); err != nil {
+		return nil, err
+	}
+
+	limit, err := f.limit(ctx, login, dataID)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > limit {
+		return nil, f.exceeded(size)
+	}
+
+	storageUploadID, err := f.dataRepository.NewMultipartUpload(ctx, login, dataID, info, algo)
+	if err != nil {
+		logger.Error("error starting multipart upload: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error starting multipart upload: %w", err)
+	}
+
+	hashState, err := marshalHash(sha256.New())
+	if err != nil {
+		return nil, err
+	}
+
+	session := &models.UploadSession{
+		UploadID:        uuid.NewString(),
+		Login:           login,
+		DataID:          dataID,
+		FileName:        filepath.Base(fileName),
+		Metadata:        info,
+		Compression:     algo,
+		StorageUploadID: storageUploadID,
+		Size:            size,
+		HashState:       hashState,
+	}
+
+	err = f.sessionRepository.CreateSession(ctx, session)
+	if err != nil {
+		logger.Error("error saving upload session: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error saving upload session: %w", err)
+	}
+
+	logger.Info("upload session created", zap.String("upload_id", session.UploadID), zap.Int64("size", size))
+
+	return session, nil
+}
+
+// UploadChunk receives chunks of the file starting from the committed offset.
+// Chunks are buffered until a whole part is collected, then the part is uploaded to storage
+// and the committed offset is advanced. Bytes not forming a whole part are dropped
+// when the stream ends, so the client resumes from the committed offset.
+func (f *UploadService) UploadChunk(stream desc.UploadV1_UploadChunkServer) error {
+	ctx := stream.Context()
+
+	req, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("error receiveing the first request message from the client: %w", err)
+	}
+
+	session, err := f.ownedSession(ctx, req.GetUploadId())
+	if err != nil {
+		return err
+	}
+
+	if _, busy := f.activeUploads.LoadOrStore(session.UploadID, struct{}{}); busy {
+		return ErrUploadInProgress
+	}
+	defer f.activeUploads.Delete(session.UploadID)
+
+	hasher := sha256.New()
+	if err := unmarshalHash(hasher, session.HashState); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, PartSize)
+
+	for {
+		if req.GetOffset() != uint64(session.CommittedOffset)+uint64(len(buf)) {
+			logger.Error("unexpected chunk offset", zap.Uint64("offset", req.GetOffset()),
+				zap.Int64("committed", session.CommittedOffset))
+
+			return ErrOffsetMismatch
+		}
+
+		data := req.GetChunk()
+		if session.CommittedOffset+int64(len(buf))+int64(len(data)) > session.Size {
+			return ErrSizeExceeded
+		}
+
+		for len(data) > 0 {
+			n := min(PartSize-len(buf), len(data))
+			buf = append(buf, data[:n]...)
+			data = data[n:]
+
+			if len(buf) == PartSize || session.CommittedOffset+int64(len(buf)) == session.Size {
+				if err := f.commitPart(ctx, session, hasher, buf); err != nil {
+					return err
+				}
+				buf = buf[:0]
+			}
+		}
+
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			logger.Error("error receiving chunk", zap.Error(err), zap.Int64("committed", session.CommittedOffset))
+
+			return fmt.Errorf("error receiveing the next request message from the client: %w", err)
+		}
+	}
+
+	if len(buf) > 0 {
+		logger.Info("dropping uncommitted bytes", zap.Int("size", len(buf)), zap.String("upload_id", session.UploadID))
+	}
+
+	return stream.SendAndClose(&desc.UploadChunkResponse{
+		UploadId:        session.UploadID,
+		CommittedOffset: uint64(session.CommittedOffset),
+	})
+}
+
+// UploadStatus returns upload session, so the client knows from which offset to resume.
+func (f *UploadService) UploadStatus(ctx context.Context, uploadID string) (*models.UploadSession, error) {
+	return f.ownedSession(ctx, uploadID)
+}
+
+// FinalizeUpload verifies size and checksum of the uploaded file and commits the object in storage.
+// If the checksum does not match, all uploaded parts are discarded.
+func (f *UploadService) FinalizeUpload(ctx context.Context, uploadID string, size int64, checksum string) (*models.UploadResult, error) {
+	session, err := f.ownedSession(ctx, uploadID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, busy := f.activeUploads.LoadOrStore(session.UploadID, struct{}{}); busy {
+		return nil, ErrUploadInProgress
+	}
+	defer f.activeUploads.Delete(session.UploadID)
+
+	if session.Size != size {
+		return nil, ErrSizeMismatch
+	}
+
+	if session.CommittedOffset != session.Size {
+		return nil, ErrUploadIncomplete
+	}
+
+	// Empty file still needs a single part to complete multipart upload.
+	if session.PartsCount == 0 {
+		err = f.dataRepository.UploadPart(ctx, session.Login, session.DataID, session.StorageUploadID, 1, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error uploading empty part: %w", err)
+		}
+	}
+
+	hasher := sha256.New()
+	if err := unmarshalHash(hasher, session.HashState); err != nil {
+		return nil, err
+	}
+	sum := hex.EncodeToString(hasher.Sum(nil))
+
+	if !strings.EqualFold(sum, checksum) {
+		logger.Error("checksum mismatch", zap.String("upload_id", uploadID),
+			zap.String("expected", checksum), zap.String("actual", sum))
+
+		f.discardSession(ctx, session)
+
+		return nil, ErrChecksumMismatch
+	}
+
+	// the id might be taken by another user during the upload
+	if _, err := f.checkAccess(ctx, session.Login, session.DataID); err != nil {
+		f.discardSession(ctx, session)
+
+		return nil, err
+	}
+
+	// quota is checked once again, since other data of the user might be saved during the upload
+	refund, err := f.reserve(ctx, session.Login, session.DataID, session.Size)
+	if err != nil {
+		f.discardSession(ctx, session)
+
+		return nil, err
+	}
+
+	_, err = f.dataRepository.CompleteMultipartUpload(ctx, session.Login, session.DataID, session.StorageUploadID)
+	if err != nil {
+		logger.Error("error completing multipart upload: ", zap.Error(err))
+
+		refund()
+
+		return nil, fmt.Errorf("error completing multipart upload: %w", err)
+	}
+
+	ref, err := f.storeBlob(ctx, session.Login, session.DataID, sum, session.Metadata, session.Compression)
+	if err != nil {
+		refund()
+
+		return nil, err
+	}
+
+	// Save information in storage about authorized user, which has right to access this data.
+	err = f.accessRepository.SaveAccess(ctx, session.Login, session.DataID)
+	if err != nil {
+		logger.Error("error saving access: ", zap.Error(err))
+
+		refund()
+
+		return nil, fmt.Errorf("error saving access: %w", err)
+	}
+
+	err = f.sessionRepository.DeleteSession(ctx, session.UploadID)
+	if err != nil {
+		logger.Error("error deleting upload session: ", zap.Error(err))
+	}
+
+	logger.Info("upload finalized", zap.String("upload_id", uploadID), zap.Int64("size", size))
+
+	return &models.UploadResult{
+		FileName: session.FileName,
+		Size:     session.Size,
+		ETag:     ref.ETag,
+		Checksum: sum,
+	}, nil
+}
+
+// commitPart uploads buffered data as the next part and persists the new upload state.
+func (f *UploadService) commitPart(ctx context.Context, session *models.UploadSession, hasher hash.Hash, data []byte) error {
+	partNumber := session.PartsCount + 1
+
+	err := f.dataRepository.UploadPart(ctx, session.Login, session.DataID, session.StorageUploadID, partNumber, data)
+	if err != nil {
+		logger.Error("error uploading part: ", zap.Error(err))
+
+		return fmt.Errorf("error uploading part: %w", err)
+	}
+
+	hasher.Write(data)
+
+	hashState, err := marshalHash(hasher)
+	if err != nil {
+		return err
+	}
+
+	committed := session.CommittedOffset + int64(len(data))
+
+	err = f.sessionRepository.UpdateProgress(ctx, session.UploadID, committed, partNumber, hashState)
+	if err != nil {
+		logger.Error("error updating upload session: ", zap.Error(err))
+
+		return fmt.Errorf("error updating upload session: %w", err)
+	}
+
+	session.CommittedOffset = committed
+	session.PartsCount = partNumber
+	session.HashState = hashState
+
+	logger.Info("part committed", zap.String("upload_id", session.UploadID),
+		zap.Int("part", partNumber), zap.Int64("committed", committed))
+
+	return nil
+}
+
+// discardSession aborts multipart upload and removes upload session.
+func (f *UploadService) discardSession(ctx context.Context, session *models.UploadSession) {
+	err := f.dataRepository.AbortMultipartUpload(ctx, session.Login, session.DataID, session.StorageUploadID)
+	if err != nil {
+		logger.Error("error aborting multipart upload: ", zap.Error(err))
+	}
+
+	err = f.sessionRepository.DeleteSession(ctx, session.UploadID)
+	if err != nil {
+		logger.Error("error deleting upload session: ", zap.Error(err))
+	}
+}
+
+// ownedSession returns upload session if it belongs to the user from incoming context.
+func (f *UploadService) ownedSession(ctx context.Context, uploadID string) (*models.UploadSession, error) {
+	login, err := loginFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := f.sessionRepository.GetSession(ctx, uploadID)
+	if err != nil {
+		logger.Error("failed to get upload session", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting upload session: %w", err)
+	}
+
+	if session.Login != login {
+		logger.Info("Authorization error")
+
+		return nil, ErrUploadForbidden
+	}
+
+	if !interceptors.ScopeFromContext(ctx).Allows(session.DataID, binData) {
+		return nil, storage.ErrOutOfScope
+	}
+
+	return session, nil
+}
+
+// loginFromContext returns user login from incoming metadata.
+func loginFromContext(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		logger.Error("metadata is not received from incoming context")
+
+		return "", storage.ErrMetadataMissing
+	}
+
+	if len(md[login]) == 0 {
+		logger.Error("login not provided")
+
+		return "", storage.ErrLoginRequired
+	}
+
+	// remove @ since this charac is not allowed for Minio bucket name
+	return strings.Replace(md[login][0], "@", "", -1), nil
+}
+
+func marshalHash(h hash.Hash) ([]byte, error) {
+	state, err := h.(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling hash state: %w", err)
+	}
+
+	return state, nil
+}
+
+func unmarshalHash(h hash.Hash, state []byte) error {
+	if len(state) == 0 {
+		return nil
+	}
+
+	err := h.(encoding.BinaryUnmarshaler).UnmarshalBinary(state)
+	if err != nil {
+		return fmt.Errorf("error restoring hash state: %w", err)
+	}
+
+	return nil
+}
@@ -2,14 +2,19 @@ package upload
 
 import (
 	"context"
-	"errors"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
-	fl "github.com/igortoigildin/goph-keeper/pkg/file"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	desc "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
 	"go.uber.org/zap"
@@ -23,25 +28,63 @@ const (
 	bankData      = "bank_data"
 	textData      = "text_data"
 	binData       = "bin_data"
+	customData    = "custom_data"
+	folderData    = "folder_data"
 )
 
 type AccessRepository interface {
 	GetAccess(ctx context.Context, login string, id string) (*models.FileInfo, error)
 	SaveAccess(ctx context.Context, login string, id string) error
+	DeleteAccess(ctx context.Context, login string, id string) error
 }
 
 type DataRepository interface {
-	SaveTextData(ctx context.Context, data any, login string, id string, info string, dataType string) (string, error)
-	SaveFile(ctx context.Context, file *fl.File, login string, id string, meta string) (string, error)
+	SaveTextData(ctx context.Context, data any, login string, id string, info string, dataType string, compression string) (string, error)
+	SaveFile(ctx context.Context, reader io.Reader, login string, id string, meta string, compression string) (string, error)
+	NewMultipartUpload(ctx context.Context, login string, id string, info string, compression string) (string, error)
+	UploadPart(ctx context.Context, login string, id string, uploadID string, partNumber int, data []byte) error
+	CompleteMultipartUpload(ctx context.Context, login string, id string, uploadID string) (string, error)
+	AbortMultipartUpload(ctx context.Context, login string, id string, uploadID string) error
+	PresignedPostURL(ctx context.Context, login string, id string, maxSize int64, expiry time.Duration) (*models.PresignedURL, error)
+	CompleteFile(ctx context.Context, login string, id string, info string, checksum string, compression string) (*models.UploadResult, error)
+	DownloadFile(ctx context.Context, bucketName, objectName string, offset, length int64) (*models.FileObject, error)
+	NewBlob(ctx context.Context, login string, id string, checksum string) (*models.Blob, error)
+	CopyToBlob(ctx context.Context, login string, id string, blob *models.Blob) error
+	RemoveFile(ctx context.Context, login string, id string) error
+	RemoveBlob(ctx context.Context, login string, key string) error
+	RemoveTextData(ctx context.Context, login, id, dataType string) error
 }
 
 type UploadService struct {
-	dataRepository   DataRepository
-	accessRepository AccessRepository
+	dataRepository    DataRepository
+	accessRepository  AccessRepository
+	sessionRepository SessionRepository
+	blobRepository    BlobRepository
+	usageRepository   UsageRepository
+
+	quota models.Quota
+
+	// upload ids of sessions currently being streamed or finalized
+	activeUploads sync.Map
 }
 
-func New(ctx context.Context, dataRep DataRepository, accessRep AccessRepository) *UploadService {
-	return &UploadService{dataRepository: dataRep, accessRepository: accessRep}
+func New(
+	ctx context.Context,
+	dataRep DataRepository,
+	accessRep AccessRepository,
+	sessionRep SessionRepository,
+	blobRep BlobRepository,
+	usageRep UsageRepository,
+	quota models.Quota,
+) *UploadService {
+	return &UploadService{
+		dataRepository:    dataRep,
+		accessRepository:  accessRep,
+		sessionRepository: sessionRep,
+		blobRepository:    blobRep,
+		usageRepository:   usageRep,
+		quota:             quota,
+	}
 }
 
 func (f *UploadService) SaveBankData(ctx context.Context, data map[string]string, info string) (string, error) {
@@ -49,25 +92,25 @@ func (f *UploadService) SaveBankData(ctx context.Context, data map[string]string
 	if !ok {
 		logger.Error("metadata is not received from incoming context")
 
-		return "", errors.New("metada not received from md")
+		return "", storage.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metadata is empty")
 
-		return "", errors.New("md is empty")
+		return "", storage.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return "", errors.New("login is needed")
+		return "", storage.ErrLoginRequired
 	} else if len(data) == 0 {
 		logger.Error("bank data not provided")
 
-		return "", errors.New("bank details not provided")
+		return "", ErrEmptyData
 	} else if _, ok = md[id]; !ok {
 		logger.Error("item id not provided")
 
-		return "", errors.New("item id needed")
+		return "", storage.ErrIDRequired
 	}
 
 	login := md[login][0]
@@ -76,15 +119,7 @@ func (f *UploadService) SaveBankData(ctx context.Context, data map[string]string
 	// remove @ since this charac is not allowed for Minio bucket name
 	login = strings.Replace(login, "@", "", -1)
 
-	// Save information in storage about authorized user, which has right to access data.
-	err := f.accessRepository.SaveAccess(ctx, login, id)
-	if err != nil {
-		logger.Error("error saving access: ", zap.Error(err))
-
-		return "", fmt.Errorf("error saving access: %w", err)
-	}
-
-	etag, err := f.dataRepository.SaveTextData(ctx, data, login, id, info, bankData)
+	etag, err := f.saveData(ctx, data, login, id, info, bankData, compression.None)
 	if err != nil {
 		logger.Error("error saving bank data:", zap.Error(err))
 
@@ -94,26 +129,42 @@ func (f *UploadService) SaveBankData(ctx context.Context, data map[string]string
 	return etag, nil
 }
 
-func (f *UploadService) SaveText(ctx context.Context, text string, info string) (string, error) {
+// SaveText saves text, which may be compressed by the client with algo before encryption.
+// Algorithm is recorded in object metadata, so any client is able to decompress the text.
+func (f *UploadService) SaveText(ctx context.Context, text string, info string, algo string) (string, error) {
+	return f.saveText(ctx, text, info, textData, algo)
+}
+
+// SaveFolder saves manifest of the folder, listing files of the saved directory. Manifest is
+// stored as text of its own data type, so it is never mistaken for a file saved by the user.
+func (f *UploadService) SaveFolder(ctx context.Context, manifest string, info string, algo string) (string, error) {
+	return f.saveText(ctx, manifest, info, folderData, algo)
+}
+
+func (f *UploadService) saveText(ctx context.Context, text, info, dataType, algo string) (string, error) {
+	if err := compression.Validate(algo); err != nil {
+		return "", err
+	}
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metada is not received from incoming context")
 
-		return "", errors.New("metada not received from md")
+		return "", storage.ErrMetadataMissing
 	} else if md.Len() == 0 {
 		logger.Error("metada is emty")
 
-		return "", errors.New("md is empty")
+		return "", storage.ErrMetadataMissing
 	}
 
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return "", errors.New("login is needed")
+		return "", storage.ErrLoginRequired
 	} else if _, ok = md[id]; !ok {
 		logger.Error("item id not provided")
 
-		return "", errors.New("item id needed")
+		return "", storage.ErrIDRequired
 	}
 
 	login := md[login][0]
@@ -121,153 +172,226 @@ func (f *UploadService) SaveText(ctx context.Context, text string, info string)
 	// remove @ since this charac is not allowed for Minio bucket name
 	login = strings.Replace(login, "@", "", -1)
 
-	// Save information in storage about authorized user, which has right to access this data.
-	err := f.accessRepository.SaveAccess(ctx, login, id)
+	etag, err := f.saveData(ctx, text, login, id, info, dataType, algo)
 	if err != nil {
-		logger.Error("error saving access: ", zap.Error(err))
+		logger.Error("error saving text data: ", zap.Error(err))
 
-		return "", fmt.Errorf("error saving access: %w", err)
+		return "", fmt.Errorf("error saving text data: %w", err)
 	}
 
-	etag, err := f.dataRepository.SaveTextData(ctx, text, login, id, info, textData)
+	return etag, nil
+}
+
+func (f *UploadService) SaveLoginPassword(ctx context.Context, data map[string]string, info string) (string, error) {
+	login, id, err := dataFromContext(ctx)
 	if err != nil {
-		logger.Error("error saving text data: ", zap.Error(err))
+		return "", err
+	}
 
-		return "", fmt.Errorf("error saving text data: %w", err)
+	etag, err := f.saveData(ctx, data, login, id, info, loginPassword, compression.None)
+	if err != nil {
+		logger.Error("error saving credentials data", zap.Error(err))
+
+		return "", fmt.Errorf("error saving credentials data: %w", err)
 	}
 
 	return etag, nil
 }
 
-func (f *UploadService) SaveLoginPassword(ctx context.Context, data map[string]string, info string) (string, error) {
+// SaveCustomData saves arbitrary fields of the secret, which has no dedicated type.
+func (f *UploadService) SaveCustomData(ctx context.Context, data map[string]string, info string) (string, error) {
+	login, dataID, err := dataFromContext(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	etag, err := f.saveData(ctx, data, login, dataID, info, customData, compression.None)
+	if err != nil {
+		logger.Error("error saving custom data: ", zap.Error(err))
+
+		return "", fmt.Errorf("error saving custom data: %w", err)
+	}
+
+	return etag, nil
+}
+
+// SaveFile pipes received chunks straight into object storage, so the file is never written
+// to the server's disk and memory used per upload is bounded by storage part size.
+// File name provided by the client is only returned back in the response.
+func (f *UploadService) SaveFile(stream desc.UploadV1_UploadFileServer) error {
+	ctx := stream.Context()
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
 		logger.Error("metadata is not received from incoming context")
 
-		return "", errors.New("metadata not received from md")
+		return storage.ErrMetadataMissing
 	} else if md.Len() == 0 {
-		logger.Error("metadata is emty")
+		logger.Error("md is emty")
 
-		return "", errors.New("metadata is empty")
+		return storage.ErrMetadataMissing
 	}
-
 	if _, ok = md[login]; !ok {
 		logger.Error("login not provided")
 
-		return "", errors.New("login is needed")
+		return storage.ErrLoginRequired
+	} else if len(md[id]) == 0 {
+		logger.Error("item id not provided")
+
+		return storage.ErrIDRequired
 	}
 
 	login := md[login][0]
 	id := md[id][0]
+
 	// remove @ since this charac is not allowed for Minio bucket name
 	login = strings.Replace(login, "@", "", -1)
 
-	// Save information in storage about authorized user, which has right to access this data.
-	err := f.accessRepository.SaveAccess(ctx, login, id)
-	if err != nil {
-		logger.Error("error saving access: ", zap.Error(err))
+	if !interceptors.ScopeFromContext(ctx).Allows(id, binData) {
+		return storage.ErrOutOfScope
+	}
 
-		return "", fmt.Errorf("error saving access: %w", err)
+	if _, err := f.checkAccess(ctx, login, id); err != nil {
+		return err
 	}
 
-	etag, err := f.dataRepository.SaveTextData(ctx, data, login, id, info, loginPassword)
+	// size of the file is not known in advance, the upload is aborted once it exceeds the limit
+	limit, err := f.limit(ctx, login, id)
 	if err != nil {
-		logger.Error("error saving credentials data", zap.Error(err))
+		return err
+	}
 
-		return "", fmt.Errorf("error saving credentials data: %w", err)
+	// additional user info and file name are taken from the first message
+	req, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		logger.Error("error", zap.Error(err))
+
+		return fmt.Errorf("error receiveing the first request message from the client: %w", err)
 	}
+	fileName := filepath.Base(req.GetFileName())
+	info := req.GetMetadata()
 
-	return etag, nil
-}
+	algo := req.GetCompression()
+	if err := compression.Validate(algo); err != nil {
+		return err
+	}
 
-func (f *UploadService) SaveFile(stream desc.UploadV1_UploadFileServer) error {
-	file := fl.NewFile()
-	var fileSize uint32
-	fileSize = 0
-	defer func() {
-		if err := file.OutputFile.Close(); err != nil {
-			logger.Error("error:", zap.Error(err))
-		}
+	pr, pw := io.Pipe()
+
+	type result struct {
+		etag string
+		err  error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		// The upload ends when the pipe is closed, storage must not be canceled together with the stream,
+		// otherwise parts of the upload aborted on shutdown are left in storage.
+		etag, err := f.dataRepository.SaveFile(context.WithoutCancel(ctx), pr, login, id, info, algo)
+		// unblock the receiving loop if storage failed before reading everything
+		pr.CloseWithError(err)
+		done <- result{etag: etag, err: err}
 	}()
 
-	// get addtional user info regarding file received
-	var info string
-	for {
-		req, err := stream.Recv()
-		if file.FilePath == "" {
-			file.SetFile(req.GetFileName(), "client_files")
-		}
-		if err == io.EOF {
-			info = req.GetMetadata()
-			break
-		}
+	hasher := sha256.New()
+	var (
+		fileSize uint64
+		checksum string
+	)
+	for err != io.EOF {
+		chunk := req.GetChunk()
+		fileSize += uint64(len(chunk))
 
-		if err != nil {
-			logger.Error("error", zap.Error(err))
+		if fileSize > uint64(limit) {
+			err := f.exceeded(int64(fileSize))
+			logger.Info("upload aborted", zap.Error(err), zap.Uint64("size", fileSize))
 
-			return fmt.Errorf("error receiveing the next request message from the client: %w", err)
+			pw.CloseWithError(err)
+			<-done
+
+			return err
 		}
 
-		chunk := req.GetChunk()
-		fileSize += uint32(len(chunk))
+		hasher.Write(chunk)
+
+		// checksum calculated by the client is sent in the last message
+		if req.GetSha256() != "" {
+			checksum = req.GetSha256()
+		}
 
-		logger.Info("received a chunk with", zap.Any("size: ", fileSize))
+		logger.Debug("received a chunk with", zap.Uint64("size: ", fileSize))
 
-		if err := file.Write(chunk); err != nil {
-			logger.Error("error writing chunk to the file:", zap.Error(err))
+		// pipe fails only once storage stopped reading, its error is the cause
+		if _, err := pw.Write(chunk); err != nil {
+			res := <-done
+			if res.err == nil {
+				res.err = err
+			}
+			logger.Error("error uploading file to Minio: ", zap.Error(res.err))
 
-			return fmt.Errorf("error writing chunk to the file: %d", err)
+			return fmt.Errorf("error uploading file to Minio: %w", res.err)
 		}
-	}
 
-	md, ok := metadata.FromIncomingContext(stream.Context())
-	if !ok {
-		logger.Error("metadata is not received from incoming context")
+		req, err = stream.Recv()
+		if err != nil && err != io.EOF {
+			logger.Error("error", zap.Error(err))
 
-		return errors.New("metadata not received from md")
-	} else if md.Len() == 0 {
-		logger.Error("md is emty")
+			// storage aborts the upload once the pipe is closed with error
+			pw.CloseWithError(err)
+			<-done
 
-		return errors.New("md is empty")
+			return fmt.Errorf("error receiveing the next request message from the client: %w", err)
+		}
 	}
-	if _, ok = md[login]; !ok {
-		logger.Error("login not provided")
 
-		return errors.New("login is needed")
-	}
+	// Verify the content before the object is committed, closing the pipe with error aborts the upload.
+	sum := hex.EncodeToString(hasher.Sum(nil))
+	if checksum != "" && !strings.EqualFold(sum, checksum) {
+		logger.Error("checksum mismatch", zap.String("expected", checksum), zap.String("actual", sum))
 
-	login := md[login][0]
-	id := md[id][0]
+		pw.CloseWithError(ErrChecksumMismatch)
+		<-done
 
-	// remove @ since this charac is not allowed for Minio bucket name
-	login = strings.Replace(login, "@", "", -1)
+		return ErrChecksumMismatch
+	}
 
-	err := f.accessRepository.SaveAccess(stream.Context(), login, id)
+	refund, err := f.reserve(ctx, login, id, int64(fileSize))
 	if err != nil {
-		logger.Error("error saving access: ", zap.Error(err))
+		pw.CloseWithError(err)
+		<-done
 
-		return fmt.Errorf("error saving access: %w", err)
+		return err
 	}
+	pw.Close()
+
+	res := <-done
+	if res.err != nil {
+		logger.Error("error uploading file to Minio: ", zap.Error(res.err))
 
-	logger.Info("result:", zap.String("path", file.FilePath), zap.Any("size", fileSize))
-	fileName := filepath.Base(file.FilePath)
+		refund()
 
-	etag, err := f.dataRepository.SaveFile(context.TODO(), file, login, id, info)
+		return fmt.Errorf("error uploading file to Minio: %w", res.err)
+	}
+
+	ref, err := f.storeBlob(ctx, login, id, sum, info, algo)
 	if err != nil {
-		logger.Error("error uploading file to Minio: ", zap.Error(err))
+		refund()
 
-		return fmt.Errorf("error uploading file to Minio: %w", err)
+		return err
 	}
 
-	// Once file successfully uploaded to Minio storage, temp file in OC will be removed.
-	err = file.Remove()
+	err = f.accessRepository.SaveAccess(ctx, login, id)
 	if err != nil {
-		logger.Error("error deleting file: ", zap.Error(err))
+		logger.Error("error saving access: ", zap.Error(err))
+
+		refund()
 
-		return fmt.Errorf("error deleting file: %w", err)
+		return fmt.Errorf("error saving access: %w", err)
 	}
 
-	response := &desc.UploadFileResponse{FileName: fileName, Size: fileSize, Etag: etag}
+	logger.Info("result:", zap.String("file", fileName), zap.Uint64("size", fileSize))
+
+	response := &desc.UploadFileResponse{FileName: fileName, Size: fileSize, Etag: ref.ETag, Sha256: sum}
 
 	if err := stream.SendAndClose(response); err != nil {
 		return fmt.Errorf("failed to send and close stream: %w", err)
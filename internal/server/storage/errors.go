@@ -0,0 +1,50 @@
+package storage
+
+import "errors"
+
+// Kinds of domain errors. Every Error wraps one of them, so callers check the kind with errors.Is
+// and API maps it to grpc status code.
+var (
+	ErrNotFound         = errors.New("not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrConflict         = errors.New("conflict")
+	ErrInvalidArgument  = errors.New("invalid argument")
+	ErrQuotaExceeded    = errors.New("quota exceeded")
+	ErrUnauthenticated  = errors.New("unauthenticated")
+	// ErrFailedPrecondition is returned when the request can not be served in current state, e.g. of the user
+	ErrFailedPrecondition = errors.New("failed precondition")
+	// ErrAborted is returned when the request lost a race with concurrent one, e.g. stale version or offset,
+	// and may be retried once the client fetches current state
+	ErrAborted = errors.New("aborted")
+	// ErrOutOfRange is returned when the request reaches past the end of the data
+	ErrOutOfRange = errors.New("out of range")
+)
+
+// Error is a domain error of storages and services.
+// Reason is a stable code of the error, which is passed to clients in error details.
+type Error struct {
+	Kind   error
+	Reason string
+	Msg    string
+}
+
+// NewError creates domain error of the kind.
+func NewError(kind error, reason string, msg string) *Error {
+	return &Error{Kind: kind, Reason: reason, Msg: msg}
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Errors of request metadata, which carries login of the user and id of the item.
+var (
+	ErrMetadataMissing = NewError(ErrInvalidArgument, "METADATA_MISSING", "metadata not received")
+	ErrLoginRequired   = NewError(ErrInvalidArgument, "LOGIN_REQUIRED", "login is needed")
+	ErrIDRequired      = NewError(ErrInvalidArgument, "ID_REQUIRED", "item id needed")
+	ErrNotLoggedIn     = NewError(ErrUnauthenticated, "NOT_LOGGED_IN", "access token is required")
+)
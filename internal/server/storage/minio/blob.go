@@ -0,0 +1,153 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+const blob = "blob"
+
+// NewBlob returns blob for the content of the binary object with provided id, named after its checksum.
+// Every blob gets a unique name, so a blob being garbage collected is never overwritten by the same
+// content saved again. The content is not copied. Returns storage.ErrObjectNotFound if the file is not uploaded.
+func (d *DataRepository) NewBlob(ctx context.Context, login string, id string, checksum string) (*model.Blob, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	src, err := statObject(ctx, client, login, binData+"_"+id)
+	if err != nil {
+		return nil, err
+	}
+
+	key := blob + "_" + checksum + "_" + uuid.NewString()
+
+	return &model.Blob{Key: key, Login: login, Checksum: checksum, Size: src.Size, ETag: src.ETag}, nil
+}
+
+// CopyToBlob copies content of the binary object with provided id into the blob object.
+func (d *DataRepository) CopyToBlob(ctx context.Context, login string, id string, b *model.Blob) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	// Single copy request is limited to 5 GiB, compose copies larger objects part by part.
+	_, err = client.ComposeObject(ctx,
+		minio.CopyDestOptions{
+			Bucket:          login,
+			Object:          b.Key,
+			UserMetadata:    map[string]string{"datatype": blob, "sha256": b.Checksum},
+			ReplaceMetadata: true,
+		},
+		minio.CopySrcOptions{Bucket: login, Object: binData + "_" + id},
+	)
+	if err != nil {
+		logger.Error("error while copying file to blob: ", zap.Error(err))
+
+		return fmt.Errorf("error copying file to blob: %w", err)
+	}
+
+	return nil
+}
+
+// DownloadBlob opens blob object for reading starting from offset.
+// If length is zero, object is read till the end. The caller is responsible for closing the reader.
+func (d *DataRepository) DownloadBlob(ctx context.Context, login string, key string, offset, length int64) (*model.FileObject, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error creating minio client: ", zap.Error(err))
+
+		return nil, errors.New("error instantiating Minio client with options")
+	}
+
+	return openObject(ctx, client, login, key, offset, length)
+}
+
+// PresignedBlobURL returns url for downloading blob object directly from Minio.
+func (d *DataRepository) PresignedBlobURL(ctx context.Context, login string, key string, expiry time.Duration) (*model.PresignedURL, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	info, err := statObject(ctx, client, login, key)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := client.PresignedGetObject(ctx, login, key, expiry, nil)
+	if err != nil {
+		logger.Error("error while presigning download url: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error presigning download url: %w", err)
+	}
+
+	return &model.PresignedURL{
+		URL:       u.String(),
+		ExpiresAt: time.Now().Add(expiry),
+		Size:      info.Size,
+		Checksum:  userMetadata(info, "sha256"),
+	}, nil
+}
+
+// RemoveFile removes binary object with provided id.
+// Returns storage.ErrObjectNotFound if there is no such object.
+func (d *DataRepository) RemoveFile(ctx context.Context, login string, id string) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id
+	if _, err := statObject(ctx, client, login, objectName); err != nil {
+		return err
+	}
+
+	err = client.RemoveObject(ctx, login, objectName, minio.RemoveObjectOptions{})
+	if err != nil {
+		logger.Error("error while removing file: ", zap.Error(err))
+
+		return fmt.Errorf("error removing file: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveBlob removes blob object, which is not referenced by any file anymore.
+func (d *DataRepository) RemoveBlob(ctx context.Context, login string, key string) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	err = client.RemoveObject(ctx, login, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		logger.Error("error while removing blob: ", zap.Error(err))
+
+		return fmt.Errorf("error removing blob: %w", err)
+	}
+
+	logger.Info("Blob removed from Minio", zap.String("key", key))
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// RemoveBucket removes all objects of the user, including incomplete uploads, and the bucket itself.
+// Nothing is done if the user has no bucket.
+func (d *DataRepository) RemoveBucket(ctx context.Context, login string) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, login)
+	if err != nil {
+		return fmt.Errorf("error checking bucket: %w", err)
+	}
+
+	if !exists {
+		return nil
+	}
+
+	for upload := range client.ListIncompleteUploads(ctx, login, "", true) {
+		if upload.Err != nil {
+			return fmt.Errorf("error listing incomplete uploads: %w", upload.Err)
+		}
+
+		err = client.RemoveIncompleteUpload(ctx, login, upload.Key)
+		if err != nil {
+			return fmt.Errorf("error removing incomplete upload: %w", err)
+		}
+	}
+
+	objects := client.ListObjects(ctx, login, minio.ListObjectsOptions{Recursive: true})
+	for res := range client.RemoveObjects(ctx, login, objects, minio.RemoveObjectsOptions{}) {
+		if res.Err != nil {
+			logger.Error("error while removing object: ", zap.String("key", res.ObjectName), zap.Error(res.Err))
+
+			return fmt.Errorf("error removing object: %w", res.Err)
+		}
+	}
+
+	err = client.RemoveBucket(ctx, login)
+	if err != nil {
+		logger.Error("error while removing bucket: ", zap.Error(err))
+
+		return fmt.Errorf("error removing bucket: %w", err)
+	}
+
+	logger.Info("Bucket removed from Minio", zap.String("bucket", login))
+
+	return nil
+}
@@ -6,9 +6,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"net/http"
 
-	fl "github.com/igortoigildin/goph-keeper/pkg/file"
+	"github.com/igortoigildin/goph-keeper/internal/server/config"
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -16,24 +18,35 @@ import (
 )
 
 const (
-	accessKey = "minioaccesskey"
-	secretKey = "miniosecretkey"
-	useSSL    = false
-	endpoint  = "localhost:9000"
-	binData   = "bin_data"
+	binData = "bin_data"
+
+	// partSize is the size of parts used for streaming uploads of unknown size,
+	// Minio rejects parts smaller than 5 MiB.
+	partSize = 5 * 1024 * 1024
 )
 
-type DataRepository struct{}
+type DataRepository struct {
+	cfg config.MinioConfig
+}
+
+// NewRepository returns repository storing data of users in Minio available as configured by cfg.
+func NewRepository(cfg config.MinioConfig) *DataRepository {
+	return &DataRepository{cfg: cfg}
+}
 
-func NewRepository() *DataRepository {
-	return &DataRepository{}
+// options returns options of Minio clients.
+func (d *DataRepository) options() *minio.Options {
+	return &minio.Options{
+		Creds:  credentials.NewStaticV4(d.cfg.AccessKey, d.cfg.SecretKey, ""),
+		Secure: d.cfg.UseSSL,
+	}
 }
 
-func (d *DataRepository) SaveFile(ctx context.Context, file *fl.File, login string, id string, meta string) (string, error) {
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
+// SaveFile streams data from reader into the binary object with provided id.
+// Object size is not known in advance, so Minio uploads it in parts of partSize,
+// which bounds the memory used per upload.
+func (d *DataRepository) SaveFile(ctx context.Context, reader io.Reader, login string, id string, meta string, compression string) (string, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
 	if err != nil {
 		logger.Error("error while creating minio client: ", zap.Error(err))
 
@@ -57,27 +70,25 @@ func (d *DataRepository) SaveFile(ctx context.Context, file *fl.File, login stri
 	}
 
 	meatadata := map[string]string{
-		"meta":     meta,
-		"dataType": binData,
+		"info":     meta,
+		"datatype": binData,
 	}
-
-	// Open the file to upload
-	f, err := os.Open(file.FilePath)
-	if err != nil {
-		logger.Error("error opening targeted file: ", zap.Error(err))
-
-		return "", fmt.Errorf("error opening targeted file: %w", err)
+	if compression != "" {
+		meatadata["compression"] = compression
 	}
-	defer f.Close()
 
 	// Upload the file to MinIO
 	objectInfo, err := client.PutObject(
-		context.Background(),
+		ctx,
 		bucketName,
 		objectName,
-		f,
+		reader,
 		-1,
-		minio.PutObjectOptions{ContentType: "application/octet-stream", UserMetadata: meatadata},
+		minio.PutObjectOptions{
+			ContentType:  "application/octet-stream",
+			UserMetadata: meatadata,
+			PartSize:     partSize,
+		},
 	)
 	if err != nil {
 		logger.Error("error while uploading file to MinIO", zap.Error(err))
@@ -85,49 +96,132 @@ func (d *DataRepository) SaveFile(ctx context.Context, file *fl.File, login stri
 		return "", fmt.Errorf("error while uploading file to MinIO: %w", err)
 	}
 
-	logger.Info("File uploaded to Minio successfully", zap.String("id:", id))
+	logger.Info("File uploaded to Minio successfully", zap.String("id:", id), zap.Int64("size", objectInfo.Size))
 
 	return objectInfo.ETag, nil
 }
 
-func (d *DataRepository) DownloadFile(ctx context.Context, bucketName, objectName string) (*bytes.Buffer, string, error) {
+// updateMetadata merges updates into user metadata of the object by copying the object onto itself.
+func updateMetadata(
+	ctx context.Context,
+	client *minio.Client,
+	bucketName string,
+	info minio.ObjectInfo,
+	updates map[string]string,
+) (minio.UploadInfo, error) {
+	metadata := make(map[string]string, len(info.UserMetadata)+len(updates))
+	for k, v := range info.UserMetadata {
+		metadata[k] = v
+	}
+	for k, v := range updates {
+		metadata[k] = v
+	}
+
+	// Single copy request is limited to 5 GiB, compose copies larger objects part by part.
+	res, err := client.ComposeObject(ctx,
+		minio.CopyDestOptions{Bucket: bucketName, Object: info.Key, UserMetadata: metadata, ReplaceMetadata: true},
+		minio.CopySrcOptions{Bucket: bucketName, Object: info.Key},
+	)
+	if err != nil {
+		logger.Error("error while updating object metadata: ", zap.Error(err))
+
+		return minio.UploadInfo{}, fmt.Errorf("error updating object metadata: %w", err)
+	}
+
+	return res, nil
+}
+
+// DownloadFile opens binary object for reading starting from offset.
+// If length is zero, object is read till the end. The caller is responsible for closing the reader.
+func (d *DataRepository) DownloadFile(ctx context.Context, bucketName, objectName string, offset, length int64) (*model.FileObject, error) {
 	objectName = binData + "_" + objectName // The name for the object in MinIO
 
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
+	client, err := minio.New(d.cfg.Endpoint, d.options())
 	if err != nil {
 		logger.Error("error creating minio client: ", zap.Error(err))
 
-		return nil, "", errors.New("error instantiating Minio client with options")
+		return nil, errors.New("error instantiating Minio client with options")
 	}
 
-	obj, err := client.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
+	return openObject(ctx, client, bucketName, objectName, offset, length)
+}
+
+// openObject opens object for reading starting from offset, if length is zero, object is read till the end.
+// Attributes of the file are taken from object user metadata.
+func openObject(ctx context.Context, client *minio.Client, bucketName, objectName string, offset, length int64) (*model.FileObject, error) {
+	info, err := client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
 	if err != nil {
-		return nil, "", fmt.Errorf("error downloading object from Minio: %w", err)
+		logger.Error("error getting object metadata: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting object metadata: %w", err)
 	}
-	defer obj.Close()
 
-	// Read the object data into a byte buffer
-	buf := new(bytes.Buffer)
-	_, err = io.Copy(buf, obj)
-	if err != nil {
-		logger.Error("copy file error: ", zap.Error(err))
+	if offset < 0 || length < 0 || offset > info.Size {
+		return nil, storage.ErrInvalidRange
+	}
+
+	res := &model.FileObject{
+		Size:     info.Size,
+		Metadata: userMetadata(info, "info"),
+		Checksum: userMetadata(info, "sha256"),
 
-		return nil, "", fmt.Errorf("copy file error: %w", err)
+		Compression: userMetadata(info, "compression"),
 	}
 
-	info, err := client.StatObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
-	if err != nil {
-		logger.Error("error getting object metadata: ", zap.Error(err))
+	// Minio rejects ranges starting at the end of the object.
+	if offset == info.Size {
+		res.Reader = io.NopCloser(bytes.NewReader(nil))
+
+		return res, nil
+	}
 
-		return nil, "", fmt.Errorf("error getting object metadata: %w", err)
+	opts := minio.GetObjectOptions{}
+	if offset > 0 || length > 0 {
+		end := int64(0) // till the end of the object
+		if length > 0 {
+			end = min(offset+length, info.Size) - 1
+		}
+
+		if err := opts.SetRange(offset, end); err != nil {
+			return nil, fmt.Errorf("error setting range: %w", err)
+		}
+	}
+
+	obj, err := client.GetObject(ctx, bucketName, objectName, opts)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading object from Minio: %w", err)
 	}
+	res.Reader = obj
 
-	metadata := info.UserMetadata["info"]
+	logger.Info("Object opened for download:", zap.String("id:", objectName), zap.Int64("offset", offset))
 
-	logger.Info("Object downloaded successfully:", zap.String("id:", objectName))
+	return res, nil
+}
+
+// userMetadata returns value of user metadata key. Minio returns keys in canonical header form.
+func userMetadata(info minio.ObjectInfo, key string) string {
+	return info.UserMetadata[http.CanonicalHeaderKey(key)]
+}
+
+// StatFile returns attributes of the binary object with provided id without opening it.
+// Returns storage.ErrObjectNotFound if there is no such object.
+func (d *DataRepository) StatFile(ctx context.Context, login string, id string) (*model.FileObject, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error creating minio client: ", zap.Error(err))
+
+		return nil, errors.New("error instantiating Minio client with options")
+	}
+
+	info, err := statObject(ctx, client, login, binData+"_"+id)
+	if err != nil {
+		return nil, err
+	}
 
-	return buf, metadata, nil
+	return &model.FileObject{
+		Size:        info.Size,
+		Metadata:    userMetadata(info, "info"),
+		Checksum:    userMetadata(info, "sha256"),
+		Compression: userMetadata(info, "compression"),
+	}, nil
 }
@@ -9,15 +9,11 @@ import (
 	model "github.com/igortoigildin/goph-keeper/internal/server/models"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 	"go.uber.org/zap"
 )
 
 func (d *DataRepository) ListObjects(ctx context.Context, bucketName string) ([]model.ObjectInfo, error) {
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
+	client, err := minio.New(d.cfg.Endpoint, d.options())
 	if err != nil {
 		logger.Error("error while creating minio client: ", zap.Error(err))
 
@@ -81,3 +77,26 @@ func (d *DataRepository) ListObjects(ctx context.Context, bucketName string) ([]
 
 	return allObjects, nil
 }
+
+// StatObject returns info of the object with provided key, storage.ErrObjectNotFound is returned if it does not exist.
+func (d *DataRepository) StatObject(ctx context.Context, bucketName string, key string) (*model.ObjectInfo, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	object, err := statObject(ctx, client, bucketName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ObjectInfo{
+		Key:          key,
+		Size:         object.Size,
+		LastModified: object.LastModified,
+		ETag:         object.ETag,
+		Datatype:     userMetadata(object, "datatype"),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package minio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// NewMultipartUpload starts a multipart upload of the binary object with provided id
+// and returns multipart upload id assigned by Minio.
+func (d *DataRepository) NewMultipartUpload(ctx context.Context, login string, id string, info string, compression string) (string, error) {
+	core, err := minio.NewCore(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return "", fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id // The name for the object in MinIO
+	bucketName := login              // Bucket name in MinIO
+
+	// Ensure the bucket exists (or create it)
+	err = core.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
+	if err != nil {
+		if exists, errBucketExists := core.BucketExists(ctx, bucketName); errBucketExists == nil && exists {
+			logger.Info("Bucket already exists")
+		} else {
+			logger.Info("Failed to create bucket:", zap.Error(err))
+
+			return "", fmt.Errorf("Minio error: %w", err)
+		}
+	}
+
+	metadata := map[string]string{
+		"info":     info,
+		"datatype": binData,
+	}
+	if compression != "" {
+		metadata["compression"] = compression
+	}
+
+	uploadID, err := core.NewMultipartUpload(ctx, bucketName, objectName,
+		minio.PutObjectOptions{ContentType: "application/octet-stream", UserMetadata: metadata})
+	if err != nil {
+		logger.Error("error while starting multipart upload: ", zap.Error(err))
+
+		return "", fmt.Errorf("error starting multipart upload: %w", err)
+	}
+
+	return uploadID, nil
+}
+
+// UploadPart uploads a single part of the multipart upload.
+func (d *DataRepository) UploadPart(ctx context.Context, login string, id string, uploadID string, partNumber int, data []byte) error {
+	core, err := minio.NewCore(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id
+
+	_, err = core.PutObjectPart(ctx, login, objectName, uploadID, partNumber,
+		bytes.NewReader(data), int64(len(data)), minio.PutObjectPartOptions{})
+	if err != nil {
+		logger.Error("error while uploading part: ", zap.Error(err), zap.Int("part", partNumber))
+
+		return fmt.Errorf("error uploading part %d: %w", partNumber, err)
+	}
+
+	return nil
+}
+
+// CompleteMultipartUpload concatenates all uploaded parts into the final object and returns its etag.
+func (d *DataRepository) CompleteMultipartUpload(ctx context.Context, login string, id string, uploadID string) (string, error) {
+	core, err := minio.NewCore(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return "", fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id
+
+	var parts []minio.CompletePart
+	marker := 0
+	for {
+		res, err := core.ListObjectParts(ctx, login, objectName, uploadID, marker, 0)
+		if err != nil {
+			logger.Error("error while listing uploaded parts: ", zap.Error(err))
+
+			return "", fmt.Errorf("error listing uploaded parts: %w", err)
+		}
+
+		for _, part := range res.ObjectParts {
+			parts = append(parts, minio.CompletePart{PartNumber: part.PartNumber, ETag: part.ETag})
+		}
+
+		if !res.IsTruncated {
+			break
+		}
+		marker = res.NextPartNumberMarker
+	}
+
+	info, err := core.CompleteMultipartUpload(ctx, login, objectName, uploadID, parts, minio.PutObjectOptions{})
+	if err != nil {
+		logger.Error("error while completing multipart upload: ", zap.Error(err))
+
+		return "", fmt.Errorf("error completing multipart upload: %w", err)
+	}
+
+	logger.Info("File uploaded to Minio successfully", zap.String("id:", id))
+
+	return info.ETag, nil
+}
+
+// AbortMultipartUpload removes all parts uploaded so far.
+func (d *DataRepository) AbortMultipartUpload(ctx context.Context, login string, id string, uploadID string) error {
+	core, err := minio.NewCore(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	err = core.AbortMultipartUpload(ctx, login, binData+"_"+id, uploadID)
+	if err != nil {
+		logger.Error("error while aborting multipart upload: ", zap.Error(err))
+
+		return fmt.Errorf("error aborting multipart upload: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package minio
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// Ping checks that object storage is reachable and accepts credentials of the server.
+func (d *DataRepository) Ping(ctx context.Context) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	if _, err := client.ListBuckets(ctx); err != nil {
+		return fmt.Errorf("error reaching Minio: %w", err)
+	}
+
+	return nil
+}
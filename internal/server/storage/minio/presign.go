@@ -0,0 +1,163 @@
+package minio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/minio/minio-go/v7"
+	"go.uber.org/zap"
+)
+
+// PresignedPostURL returns url and form fields of the POST policy for uploading binary object
+// with provided id directly to Minio, storage rejects objects larger than maxSize.
+// The bucket is created beforehand, since presigned requests can not create it.
+func (d *DataRepository) PresignedPostURL(
+	ctx context.Context,
+	login string,
+	id string,
+	maxSize int64,
+	expiry time.Duration,
+) (*model.PresignedURL, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id // The name for the object in MinIO
+	bucketName := login              // Bucket name in MinIO
+
+	// Ensure the bucket exists (or create it)
+	err = client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
+	if err != nil {
+		if exists, errBucketExists := client.BucketExists(ctx, bucketName); errBucketExists == nil && exists {
+			logger.Info("Bucket already exists")
+		} else {
+			logger.Info("Failed to create bucket:", zap.Error(err))
+
+			return nil, fmt.Errorf("Minio error: %w", err)
+		}
+	}
+
+	expiresAt := time.Now().Add(expiry)
+
+	// presigned PUT url does not limit size of the object, POST policy does
+	policy := minio.NewPostPolicy()
+	if err := errors.Join(
+		policy.SetBucket(bucketName),
+		policy.SetKey(objectName),
+		policy.SetExpires(expiresAt),
+		policy.SetContentLengthRange(0, maxSize),
+	); err != nil {
+		return nil, fmt.Errorf("error setting upload policy: %w", err)
+	}
+
+	u, formData, err := client.PresignedPostPolicy(ctx, policy)
+	if err != nil {
+		logger.Error("error while presigning upload url: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error presigning upload url: %w", err)
+	}
+
+	return &model.PresignedURL{URL: u.String(), ExpiresAt: expiresAt, FormData: formData}, nil
+}
+
+// PresignedGetURL returns url for downloading binary object with provided id directly from Minio
+// together with attributes of the object, so the client is able to resume and verify the download.
+func (d *DataRepository) PresignedGetURL(ctx context.Context, login string, id string, expiry time.Duration) (*model.PresignedURL, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectName := binData + "_" + id
+
+	info, err := statObject(ctx, client, login, objectName)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := client.PresignedGetObject(ctx, login, objectName, expiry, nil)
+	if err != nil {
+		logger.Error("error while presigning download url: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error presigning download url: %w", err)
+	}
+
+	return &model.PresignedURL{
+		URL:         u.String(),
+		ExpiresAt:   time.Now().Add(expiry),
+		Size:        info.Size,
+		Metadata:    userMetadata(info, "info"),
+		Checksum:    userMetadata(info, "sha256"),
+		Compression: userMetadata(info, "compression"),
+	}, nil
+}
+
+// CompleteFile records additional info, checksum and compression of the binary object
+// uploaded through presigned url. Returns storage.ErrObjectNotFound if the object was not uploaded.
+func (d *DataRepository) CompleteFile(
+	ctx context.Context,
+	login string,
+	id string,
+	info string,
+	checksum string,
+	compression string,
+) (*model.UploadResult, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
+	}
+
+	objectInfo, err := statObject(ctx, client, login, binData+"_"+id)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{
+		"info":     info,
+		"datatype": binData,
+	}
+	if checksum != "" {
+		metadata["sha256"] = checksum
+	}
+	if compression != "" {
+		metadata["compression"] = compression
+	}
+
+	res, err := updateMetadata(ctx, client, login, objectInfo, metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("File uploaded through presigned url completed", zap.String("id:", id), zap.Int64("size", objectInfo.Size))
+
+	return &model.UploadResult{Size: objectInfo.Size, ETag: res.ETag, Checksum: checksum}, nil
+}
+
+// statObject returns object info, storage.ErrObjectNotFound is returned if the object does not exist.
+func statObject(ctx context.Context, client *minio.Client, bucketName, objectName string) (minio.ObjectInfo, error) {
+	info, err := client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return minio.ObjectInfo{}, storage.ErrObjectNotFound
+		}
+
+		logger.Error("error getting object metadata: ", zap.Error(err))
+
+		return minio.ObjectInfo{}, fmt.Errorf("error getting object metadata: %w", err)
+	}
+
+	return info, nil
+}
@@ -6,18 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
 	"github.com/igortoigildin/goph-keeper/pkg/logger"
 	"github.com/minio/minio-go/v7"
-	"github.com/minio/minio-go/v7/pkg/credentials"
 	"go.uber.org/zap"
 )
 
-func (d *DataRepository) SaveTextData(ctx context.Context, data any, login string, id string, info string, datatype string) (string, error) {
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
+func (d *DataRepository) SaveTextData(ctx context.Context, data any, login string, id string, info string, datatype string, compression string) (string, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
 	if err != nil {
 		logger.Error("error while creating minio client: ", zap.Error(err))
 
@@ -56,6 +55,9 @@ func (d *DataRepository) SaveTextData(ctx context.Context, data any, login strin
 		"info":     info,
 		"datatype": datatype,
 	}
+	if compression != "" {
+		metadata["compression"] = compression
+	}
 
 	objInfo, err := client.PutObject(ctx, bucketName, objectName, buf,
 		int64(buf.Len()),
@@ -72,14 +74,13 @@ func (d *DataRepository) SaveTextData(ctx context.Context, data any, login strin
 	return objInfo.ETag, nil
 }
 
-func (d *DataRepository) DownloadTextData(ctx context.Context, bucketName, objectName, dataType string) ([]byte, string, error) {
-	client, err := minio.New(endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
-		Secure: useSSL,
-	})
+// DownloadTextData returns stored data together with additional info and compression
+// saved in object metadata. Returns storage.ErrObjectNotFound if there is no such object.
+func (d *DataRepository) DownloadTextData(ctx context.Context, bucketName, objectName, dataType string) (*model.TextObject, error) {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
 	if err != nil {
 		logger.Error("error while creating minio client: ", zap.Error(err))
-		return nil, "", fmt.Errorf("error instantiating Minio client with options: %w", err)
+		return nil, fmt.Errorf("error instantiating Minio client with options: %w", err)
 	}
 
 	objectName = dataType + "_" + objectName
@@ -90,19 +91,56 @@ func (d *DataRepository) DownloadTextData(ctx context.Context, bucketName, objec
 	obj, err := client.GetObject(ctx, bucketName, objectName, minio.GetObjectOptions{})
 	if err != nil {
 		logger.Error("error opening targeted file: ", zap.Error(err))
-		return nil, "", fmt.Errorf("error opening targeted file: %w", err)
+		return nil, fmt.Errorf("error opening targeted file: %w", err)
 	}
 	defer obj.Close()
 
+	info, err := obj.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).StatusCode == http.StatusNotFound {
+			return nil, storage.ErrObjectNotFound
+		}
+
+		logger.Error("error getting object metadata: ", zap.Error(err))
+		return nil, fmt.Errorf("error getting object metadata: %w", err)
+	}
+
 	// Read the object data into a byte buffer
 	buf := new(bytes.Buffer)
 	_, err = io.Copy(buf, obj)
 	if err != nil {
 		logger.Error("error copying targeted file: ", zap.Error(err))
-		return nil, "", fmt.Errorf("error copying targeted file: %w", err)
+		return nil, fmt.Errorf("error copying targeted file: %w", err)
+	}
+
+	return &model.TextObject{
+		Data:        buf.Bytes(),
+		Metadata:    userMetadata(info, "info"),
+		Compression: userMetadata(info, "compression"),
+	}, nil
+}
+
+// RemoveTextData removes object of provided data type with id.
+// Returns storage.ErrObjectNotFound if there is no such object.
+func (d *DataRepository) RemoveTextData(ctx context.Context, login, id, dataType string) error {
+	client, err := minio.New(d.cfg.Endpoint, d.options())
+	if err != nil {
+		logger.Error("error while creating minio client: ", zap.Error(err))
+
+		return fmt.Errorf("error instantiating Minio client with options: %w", err)
 	}
 
-	res := buf.Bytes()
+	objectName := dataType + "_" + id
+	if _, err := statObject(ctx, client, login, objectName); err != nil {
+		return err
+	}
+
+	err = client.RemoveObject(ctx, login, objectName, minio.RemoveObjectOptions{})
+	if err != nil {
+		logger.Error("error while removing object: ", zap.Error(err))
+
+		return fmt.Errorf("error removing object: %w", err)
+	}
 
-	return res, "", nil
+	return nil
 }
@@ -5,7 +5,9 @@ import (
 	"fmt"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
 
 	"github.com/igortoigildin/goph-keeper/internal/client/db"
 )
@@ -47,6 +49,10 @@ func (rep *AccessRepository) GetAccess(ctx context.Context, login string, id str
 	var file models.FileInfo
 	err = rep.db.DB().ScanOneContext(ctx, &file, qr, args...)
 	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrAccessNotFound
+		}
+
 		return nil, fmt.Errorf("error retrieving info about specified user: %w", err)
 	}
 
@@ -57,7 +63,9 @@ func (rep *AccessRepository) SaveAccess(ctx context.Context, login string, id st
 	builder := sq.Insert(tableName).
 		PlaceholderFormat(sq.Dollar).
 		Columns(loginColumn, fileIdColumn).
-		Values(login, id)
+		Values(login, id).
+		// data saved again under the same id keeps its owner
+		Suffix("ON CONFLICT DO NOTHING")
 
 	query, args, err := builder.ToSql()
 	if err != nil {
@@ -69,10 +77,33 @@ func (rep *AccessRepository) SaveAccess(ctx context.Context, login string, id st
 		QueryRaw: query,
 	}
 
-	_, err = rep.db.DB().QueryContext(ctx, qr, args...)
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func (rep *AccessRepository) DeleteAccess(ctx context.Context, login string, id string) error {
+	builder := sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{loginColumn: login, fileIdColumn: id})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "access_repository.DeleteAccess",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error deleting access: %w", err)
+	}
+
+	return nil
+}
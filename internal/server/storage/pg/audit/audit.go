@@ -0,0 +1,121 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+)
+
+const (
+	tableName = "audit_events"
+
+	idColumn         = "id"
+	occurredAtColumn = "occurred_at"
+	actorColumn      = "actor"
+	actionColumn     = "action"
+	objectIDColumn   = "object_id"
+	methodColumn     = "method"
+	peerColumn       = "peer"
+	requestIDColumn  = "request_id"
+	resultColumn     = "result"
+)
+
+var columns = []string{idColumn, occurredAtColumn, actorColumn, actionColumn, objectIDColumn, methodColumn,
+	peerColumn, requestIDColumn, resultColumn}
+
+type AuditRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *AuditRepository {
+	return &AuditRepository{
+		db: db,
+	}
+}
+
+// SaveEvents appends events to the audit log in one statement.
+func (rep *AuditRepository) SaveEvents(ctx context.Context, events []models.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	builder := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns(occurredAtColumn, actorColumn, actionColumn, objectIDColumn, methodColumn, peerColumn,
+			requestIDColumn, resultColumn)
+
+	for _, e := range events {
+		builder = builder.Values(e.Time, e.Actor, e.Action, e.ObjectID, e.Method, e.Peer, e.RequestID, e.Result)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "audit_repository.SaveEvents",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error saving audit events: %w", err)
+	}
+
+	return nil
+}
+
+// QueryEvents returns events matching the filter, newest first.
+func (rep *AuditRepository) QueryEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy(idColumn + " DESC").
+		Limit(filter.Limit)
+
+	if !filter.From.IsZero() {
+		builder = builder.Where(sq.GtOrEq{occurredAtColumn: filter.From})
+	}
+
+	if !filter.To.IsZero() {
+		builder = builder.Where(sq.Lt{occurredAtColumn: filter.To})
+	}
+
+	if filter.Actor != "" {
+		builder = builder.Where(sq.Eq{actorColumn: filter.Actor})
+	}
+
+	if filter.ObjectID != "" {
+		builder = builder.Where(sq.Eq{objectIDColumn: filter.ObjectID})
+	}
+
+	if filter.Action != "" {
+		builder = builder.Where(sq.Eq{actionColumn: filter.Action})
+	}
+
+	if filter.BeforeID != 0 {
+		builder = builder.Where(sq.Lt{idColumn: filter.BeforeID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "audit_repository.QueryEvents",
+		QueryRaw: query,
+	}
+
+	var res []models.AuditEvent
+	err = rep.db.DB().ScanAllContext(ctx, &res, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving audit events: %w", err)
+	}
+
+	return res, nil
+}
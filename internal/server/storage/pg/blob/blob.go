@@ -0,0 +1,246 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	pgx "github.com/jackc/pgx/v4"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+)
+
+const (
+	blobsTable = "blobs"
+	refsTable  = "file_refs"
+
+	objectKeyColumn   = "object_key"
+	loginColumn       = "login"
+	checksumColumn    = "sha256"
+	sizeColumn        = "size"
+	etagColumn        = "etag"
+	refCountColumn    = "ref_count"
+	dataIDColumn      = "data_id"
+	metadataColumn    = "metadata"
+	compressionColumn = "compression"
+	updatedAtColumn   = "updated_at"
+)
+
+type BlobRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *BlobRepository {
+	return &BlobRepository{
+		db: db,
+	}
+}
+
+// AcquireBlob adds reference to the blob of the user with the same checksum.
+// If there is no such blob yet, provided blob is saved with a single reference.
+// Returns the blob, which holds the content from now on.
+func (rep *BlobRepository) AcquireBlob(ctx context.Context, blob *models.Blob) (*models.Blob, error) {
+	builder := sq.Insert(blobsTable).
+		PlaceholderFormat(sq.Dollar).
+		Columns(objectKeyColumn, loginColumn, checksumColumn, sizeColumn, etagColumn, refCountColumn).
+		Values(blob.Key, blob.Login, blob.Checksum, blob.Size, blob.ETag, 1).
+		Suffix("ON CONFLICT (login, sha256) DO UPDATE SET ref_count = blobs.ref_count + 1 " +
+			"RETURNING object_key, login, sha256, size, etag, ref_count")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.AcquireBlob",
+		QueryRaw: query,
+	}
+
+	var res models.Blob
+	err = rep.db.DB().ScanOneContext(ctx, &res, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring blob: %w", err)
+	}
+
+	return &res, nil
+}
+
+// ReleaseBlob drops a reference to the blob. Blob is deleted once it is not referenced anymore,
+// in which case true is returned and the caller is responsible for removing its content from storage.
+func (rep *BlobRepository) ReleaseBlob(ctx context.Context, key string) (bool, error) {
+	update := sq.Update(blobsTable).
+		PlaceholderFormat(sq.Dollar).
+		Set(refCountColumn, sq.Expr(refCountColumn+" - 1")).
+		Where(sq.Eq{objectKeyColumn: key})
+
+	query, args, err := update.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.ReleaseBlob",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return false, fmt.Errorf("error releasing blob: %w", err)
+	}
+
+	// Blob acquired again in the meantime keeps positive reference count and is not deleted.
+	del := sq.Delete(blobsTable).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{objectKeyColumn: key}).
+		Where(sq.LtOrEq{refCountColumn: 0})
+
+	query, args, err = del.ToSql()
+	if err != nil {
+		return false, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr = db.Query{
+		Name:     "blob_repository.DeleteBlob",
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return false, fmt.Errorf("error deleting blob: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// SaveRef points file with provided id to the blob. Returns key of the blob
+// the file referenced before, or empty string if the file is saved for the first time.
+// storage.ErrRefConflict is returned if the file with the id belongs to another user.
+func (rep *BlobRepository) SaveRef(ctx context.Context, ref *models.FileRef) (string, error) {
+	builder := sq.Insert(refsTable).
+		PlaceholderFormat(sq.Dollar).
+		Prefix("WITH prev AS (SELECT object_key FROM file_refs WHERE data_id = ?)", ref.DataID).
+		Columns(dataIDColumn, loginColumn, objectKeyColumn, checksumColumn, sizeColumn, etagColumn,
+			metadataColumn, compressionColumn).
+		Values(ref.DataID, ref.Login, ref.BlobKey, ref.Checksum, ref.Size, ref.ETag, ref.Metadata, ref.Compression).
+		Suffix("ON CONFLICT (data_id) DO UPDATE SET object_key = EXCLUDED.object_key, sha256 = EXCLUDED.sha256, " +
+			"size = EXCLUDED.size, etag = EXCLUDED.etag, metadata = EXCLUDED.metadata, " +
+			"compression = EXCLUDED.compression, updated_at = now() " +
+			"WHERE file_refs.login = EXCLUDED.login " +
+			"RETURNING COALESCE((SELECT object_key FROM prev), '')")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return "", fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.SaveRef",
+		QueryRaw: query,
+	}
+
+	var prev string
+	err = rep.db.DB().QueryRowContext(ctx, qr, args...).Scan(&prev)
+	if err != nil {
+		// nothing is returned when the file with the id belongs to another user
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", storage.ErrRefConflict
+		}
+
+		return "", fmt.Errorf("error saving file reference: %w", err)
+	}
+
+	return prev, nil
+}
+
+func (rep *BlobRepository) GetRef(ctx context.Context, id string) (*models.FileRef, error) {
+	builder := sq.Select(dataIDColumn, loginColumn, objectKeyColumn, checksumColumn, sizeColumn, etagColumn,
+		metadataColumn, compressionColumn, updatedAtColumn).
+		PlaceholderFormat(sq.Dollar).
+		From(refsTable).
+		Where(sq.Eq{dataIDColumn: id}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.GetRef",
+		QueryRaw: query,
+	}
+
+	var ref models.FileRef
+	err = rep.db.DB().ScanOneContext(ctx, &ref, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrRefNotFound
+		}
+
+		return nil, fmt.Errorf("error retrieving file reference: %w", err)
+	}
+
+	return &ref, nil
+}
+
+// DeleteRef deletes reference of the file with provided id and returns it,
+// so the caller is able to release the blob.
+func (rep *BlobRepository) DeleteRef(ctx context.Context, id string) (*models.FileRef, error) {
+	builder := sq.Delete(refsTable).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{dataIDColumn: id}).
+		Suffix("RETURNING data_id, login, object_key, sha256, size, etag, metadata, compression, updated_at")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.DeleteRef",
+		QueryRaw: query,
+	}
+
+	var ref models.FileRef
+	err = rep.db.DB().ScanOneContext(ctx, &ref, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrRefNotFound
+		}
+
+		return nil, fmt.Errorf("error deleting file reference: %w", err)
+	}
+
+	return &ref, nil
+}
+
+func (rep *BlobRepository) ListRefs(ctx context.Context, login string) ([]models.FileRef, error) {
+	builder := sq.Select(dataIDColumn, loginColumn, objectKeyColumn, checksumColumn, sizeColumn, etagColumn,
+		metadataColumn, compressionColumn, updatedAtColumn).
+		PlaceholderFormat(sq.Dollar).
+		From(refsTable).
+		Where(sq.Eq{loginColumn: login})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "blob_repository.ListRefs",
+		QueryRaw: query,
+	}
+
+	var refs []models.FileRef
+	err = rep.db.DB().ScanAllContext(ctx, &refs, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing file references: %w", err)
+	}
+
+	return refs, nil
+}
@@ -0,0 +1,159 @@
+package cert
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+)
+
+const (
+	tableName = "cert_bindings"
+
+	identityColumn  = "identity"
+	loginColumn     = "login"
+	createdAtColumn = "created_at"
+)
+
+var columns = []string{identityColumn, loginColumn, createdAtColumn}
+
+type CertRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *CertRepository {
+	return &CertRepository{
+		db: db,
+	}
+}
+
+// BindCert binds certificates with the identity to the user. Returns storage.ErrCertBound,
+// if the identity is already bound.
+func (rep *CertRepository) BindCert(ctx context.Context, identity string, login string) error {
+	builder := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns(identityColumn, loginColumn).
+		Values(identity, login).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "cert_repository.BindCert",
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error binding certificate: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrCertBound
+	}
+
+	return nil
+}
+
+// UnbindCert removes binding of the identity, storage.ErrCertNotBound is returned if it is not bound.
+func (rep *CertRepository) UnbindCert(ctx context.Context, identity string) error {
+	builder := sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{identityColumn: identity})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "cert_repository.UnbindCert",
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error unbinding certificate: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrCertNotBound
+	}
+
+	return nil
+}
+
+// FindBinding returns binding of the first identity in the list, which is bound to a user.
+// storage.ErrCertNotBound is returned if none of them is bound.
+func (rep *CertRepository) FindBinding(ctx context.Context, identities []string) (*models.CertBinding, error) {
+	if len(identities) == 0 {
+		return nil, storage.ErrCertNotBound
+	}
+
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{identityColumn: identities})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "cert_repository.FindBinding",
+		QueryRaw: query,
+	}
+
+	var bindings []models.CertBinding
+	err = rep.db.DB().ScanAllContext(ctx, &bindings, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving certificate bindings: %w", err)
+	}
+
+	for _, identity := range identities {
+		for i := range bindings {
+			if bindings[i].Identity == identity {
+				return &bindings[i], nil
+			}
+		}
+	}
+
+	return nil, storage.ErrCertNotBound
+}
+
+// ListCerts returns identities bound to the user, all bindings are returned if login is empty.
+func (rep *CertRepository) ListCerts(ctx context.Context, login string) ([]models.CertBinding, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		OrderBy(loginColumn, identityColumn)
+
+	if login != "" {
+		builder = builder.Where(sq.Eq{loginColumn: login})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "cert_repository.ListCerts",
+		QueryRaw: query,
+	}
+
+	var bindings []models.CertBinding
+	err = rep.db.DB().ScanAllContext(ctx, &bindings, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing certificate bindings: %w", err)
+	}
+
+	return bindings, nil
+}
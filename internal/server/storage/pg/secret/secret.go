@@ -0,0 +1,215 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+)
+
+const (
+	tableName = "secrets"
+
+	idColumn        = "data_id"
+	loginColumn     = "login"
+	typeColumn      = "type"
+	nameColumn      = "name"
+	tagsColumn      = "tags"
+	versionColumn   = "version"
+	etagColumn      = "etag"
+	createdAtColumn = "created_at"
+	updatedAtColumn = "updated_at"
+)
+
+var columns = []string{idColumn, loginColumn, typeColumn, nameColumn, tagsColumn, versionColumn, etagColumn,
+	createdAtColumn, updatedAtColumn}
+
+type SecretRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *SecretRepository {
+	return &SecretRepository{
+		db: db,
+	}
+}
+
+// CreateSecret saves the secret with the first version. Returns storage.ErrSecretExists if the id is taken.
+func (rep *SecretRepository) CreateSecret(ctx context.Context, secret *models.SecretInfo) (*models.SecretInfo, error) {
+	tags := secret.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	builder := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns(idColumn, loginColumn, typeColumn, nameColumn, tagsColumn, etagColumn).
+		Values(secret.ID, secret.Login, secret.Type, secret.Name, tags, secret.ETag).
+		Suffix("ON CONFLICT DO NOTHING RETURNING data_id, login, type, name, tags, version, etag, created_at, updated_at")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "secret_repository.CreateSecret",
+		QueryRaw: query,
+	}
+
+	var res models.SecretInfo
+	err = rep.db.DB().ScanOneContext(ctx, &res, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrSecretExists
+		}
+
+		return nil, fmt.Errorf("error saving secret: %w", err)
+	}
+
+	return &res, nil
+}
+
+func (rep *SecretRepository) GetSecret(ctx context.Context, id string) (*models.SecretInfo, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{idColumn: id}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "secret_repository.GetSecret",
+		QueryRaw: query,
+	}
+
+	var res models.SecretInfo
+	err = rep.db.DB().ScanOneContext(ctx, &res, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrSecretNotFound
+		}
+
+		return nil, fmt.Errorf("error retrieving secret: %w", err)
+	}
+
+	return &res, nil
+}
+
+// UpdateSecret saves name, tags and etag of the secret and increments its version.
+// If version is not zero, the secret is updated only if its current version equals it,
+// otherwise storage.ErrVersionMismatch is returned.
+func (rep *SecretRepository) UpdateSecret(ctx context.Context, secret *models.SecretInfo, version int64) (*models.SecretInfo, error) {
+	tags := secret.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	builder := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set(nameColumn, secret.Name).
+		Set(tagsColumn, tags).
+		Set(etagColumn, secret.ETag).
+		Set(versionColumn, sq.Expr(versionColumn+" + 1")).
+		Set(updatedAtColumn, sq.Expr("now()")).
+		Where(sq.Eq{idColumn: secret.ID}).
+		Suffix("RETURNING data_id, login, type, name, tags, version, etag, created_at, updated_at")
+
+	if version != 0 {
+		builder = builder.Where(sq.Eq{versionColumn: version})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "secret_repository.UpdateSecret",
+		QueryRaw: query,
+	}
+
+	var res models.SecretInfo
+	err = rep.db.DB().ScanOneContext(ctx, &res, qr, args...)
+	if err != nil {
+		if !pgxscan.NotFound(err) {
+			return nil, fmt.Errorf("error updating secret: %w", err)
+		}
+
+		// tell missing secret from the one changed concurrently
+		if _, err := rep.GetSecret(ctx, secret.ID); err != nil {
+			return nil, err
+		}
+
+		return nil, storage.ErrVersionMismatch
+	}
+
+	return &res, nil
+}
+
+func (rep *SecretRepository) DeleteSecret(ctx context.Context, id string) error {
+	builder := sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{idColumn: id})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "secret_repository.DeleteSecret",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error deleting secret: %w", err)
+	}
+
+	return nil
+}
+
+// ListSecrets returns secrets of the user matching the filter ordered by creation time.
+func (rep *SecretRepository) ListSecrets(ctx context.Context, login string, filter models.SecretFilter) ([]models.SecretInfo, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{loginColumn: login}).
+		OrderBy(createdAtColumn)
+
+	if filter.Type != "" {
+		builder = builder.Where(sq.Eq{typeColumn: filter.Type})
+	}
+
+	if filter.Tag != "" {
+		builder = builder.Where(sq.Expr("? = ANY("+tagsColumn+")", filter.Tag))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "secret_repository.ListSecrets",
+		QueryRaw: query,
+	}
+
+	var res []models.SecretInfo
+	err = rep.db.DB().ScanAllContext(ctx, &res, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing secrets: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,174 @@
+package token
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+)
+
+const (
+	tableName = "api_tokens"
+
+	idColumn        = "id"
+	loginColumn     = "login"
+	nameColumn      = "name"
+	tokenHashColumn = "token_hash"
+	readOnlyColumn  = "read_only"
+	secretIDsColumn = "secret_ids"
+	dataTypesColumn = "data_types"
+	createdAtColumn = "created_at"
+	expiresAtColumn = "expires_at"
+	revokedAtColumn = "revoked_at"
+)
+
+var columns = []string{idColumn, loginColumn, nameColumn, tokenHashColumn, readOnlyColumn, secretIDsColumn,
+	dataTypesColumn, createdAtColumn, expiresAtColumn}
+
+type TokenRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *TokenRepository {
+	return &TokenRepository{
+		db: db,
+	}
+}
+
+// SaveToken saves the token and returns it as stored. Returns storage.ErrTokenExists,
+// if the user has the token with the same name, which is not revoked.
+func (rep *TokenRepository) SaveToken(ctx context.Context, token *models.APIToken) (*models.APIToken, error) {
+	builder := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns(idColumn, loginColumn, nameColumn, tokenHashColumn, readOnlyColumn, secretIDsColumn, dataTypesColumn,
+			expiresAtColumn).
+		Values(token.ID, token.Login, token.Name, token.Hash, token.ReadOnly, nonNil(token.SecretIDs),
+			nonNil(token.DataTypes), token.ExpiresAt).
+		Suffix("ON CONFLICT DO NOTHING RETURNING " + strings.Join(columns, ", "))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "token_repository.SaveToken",
+		QueryRaw: query,
+	}
+
+	var res models.APIToken
+	err = rep.db.DB().ScanOneContext(ctx, &res, qr, args...)
+	if err != nil {
+		// nothing is returned when insert conflicts with the name of existing token
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrTokenExists
+		}
+
+		return nil, fmt.Errorf("error saving token: %w", err)
+	}
+
+	return &res, nil
+}
+
+// GetToken returns the token with the hash, storage.ErrTokenNotFound is returned if there is no such token
+// or it is revoked. Expired tokens are returned.
+func (rep *TokenRepository) GetToken(ctx context.Context, tokenHash string) (*models.APIToken, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{tokenHashColumn: tokenHash, revokedAtColumn: nil}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "token_repository.GetToken",
+		QueryRaw: query,
+	}
+
+	var token models.APIToken
+	err = rep.db.DB().ScanOneContext(ctx, &token, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrTokenNotFound
+		}
+
+		return nil, fmt.Errorf("error retrieving token: %w", err)
+	}
+
+	return &token, nil
+}
+
+// ListTokens returns tokens of the user, which are not revoked, newest first.
+func (rep *TokenRepository) ListTokens(ctx context.Context, login string) ([]models.APIToken, error) {
+	builder := sq.Select(columns...).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{loginColumn: login, revokedAtColumn: nil}).
+		OrderBy(createdAtColumn + " DESC")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "token_repository.ListTokens",
+		QueryRaw: query,
+	}
+
+	var tokens []models.APIToken
+	err = rep.db.DB().ScanAllContext(ctx, &tokens, qr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeToken revokes the token of the user with id, storage.ErrTokenNotFound is returned
+// if the user has no such token or it is already revoked.
+func (rep *TokenRepository) RevokeToken(ctx context.Context, login string, id string) error {
+	builder := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set(revokedAtColumn, sq.Expr("now()")).
+		Where(sq.Eq{idColumn: id, loginColumn: login, revokedAtColumn: nil})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "token_repository.RevokeToken",
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error revoking token: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrTokenNotFound
+	}
+
+	return nil
+}
+
+// nonNil returns empty list instead of nil, which would be saved as NULL.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+
+	return list
+}
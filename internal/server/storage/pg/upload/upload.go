@@ -0,0 +1,147 @@
+package upload
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+)
+
+const (
+	tableName = "upload_sessions"
+
+	uploadIDColumn        = "upload_id"
+	loginColumn           = "login"
+	dataIDColumn          = "data_id"
+	fileNameColumn        = "file_name"
+	metadataColumn        = "metadata"
+	compressionColumn     = "compression"
+	storageUploadIDColumn = "storage_upload_id"
+	sizeColumn            = "size"
+	committedOffsetColumn = "committed_offset"
+	partsCountColumn      = "parts_count"
+	hashStateColumn       = "hash_state"
+	createdAtColumn       = "created_at"
+)
+
+type SessionRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+func (rep *SessionRepository) CreateSession(ctx context.Context, session *models.UploadSession) error {
+	builder := sq.Insert(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Columns(uploadIDColumn, loginColumn, dataIDColumn, fileNameColumn, metadataColumn, compressionColumn,
+			storageUploadIDColumn, sizeColumn, committedOffsetColumn, partsCountColumn, hashStateColumn).
+		Values(session.UploadID, session.Login, session.DataID, session.FileName, session.Metadata, session.Compression,
+			session.StorageUploadID, session.Size, session.CommittedOffset, session.PartsCount, session.HashState)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "upload_session_repository.CreateSession",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error saving upload session: %w", err)
+	}
+
+	return nil
+}
+
+func (rep *SessionRepository) GetSession(ctx context.Context, uploadID string) (*models.UploadSession, error) {
+	builder := sq.Select(uploadIDColumn, loginColumn, dataIDColumn, fileNameColumn, metadataColumn, compressionColumn,
+		storageUploadIDColumn, sizeColumn, committedOffsetColumn, partsCountColumn, hashStateColumn, createdAtColumn).
+		PlaceholderFormat(sq.Dollar).
+		From(tableName).
+		Where(sq.Eq{uploadIDColumn: uploadID}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "upload_session_repository.GetSession",
+		QueryRaw: query,
+	}
+
+	var session models.UploadSession
+	err = rep.db.DB().ScanOneContext(ctx, &session, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrUploadNotFound
+		}
+
+		return nil, fmt.Errorf("error retrieving upload session: %w", err)
+	}
+
+	return &session, nil
+}
+
+// UpdateProgress stores the offset, parts count and hash state reached after a part has been committed.
+func (rep *SessionRepository) UpdateProgress(ctx context.Context, uploadID string, committedOffset int64, partsCount int, hashState []byte) error {
+	builder := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Set(committedOffsetColumn, committedOffset).
+		Set(partsCountColumn, partsCount).
+		Set(hashStateColumn, hashState).
+		Where(sq.Eq{uploadIDColumn: uploadID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "upload_session_repository.UpdateProgress",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error updating upload session: %w", err)
+	}
+
+	return nil
+}
+
+func (rep *SessionRepository) DeleteSession(ctx context.Context, uploadID string) error {
+	builder := sq.Delete(tableName).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{uploadIDColumn: uploadID})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "upload_session_repository.DeleteSession",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error deleting upload session: %w", err)
+	}
+
+	return nil
+}
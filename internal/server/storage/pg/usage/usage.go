@@ -0,0 +1,192 @@
+package usage
+
+import (
+	"context"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+)
+
+const (
+	usageTable   = "user_usage"
+	objectsTable = "object_usage"
+
+	loginColumn   = "login"
+	bytesColumn   = "bytes"
+	objectsColumn = "objects"
+	dataIDColumn  = "data_id"
+	sizeColumn    = "size"
+)
+
+// chargeQuery sets size of the object and adjusts usage of the user by the difference with the size
+// charged before, unless the user exceeds the quota. Quota is checked against the locked row of the user,
+// so concurrent charges can not exceed it together. Objects shrinking or saved again are always charged.
+const chargeQuery = `
+WITH prev AS (
+    SELECT size FROM object_usage WHERE login = $1 AND data_id = $2
+), delta AS (
+    SELECT $3::BIGINT - COALESCE((SELECT size FROM prev), 0) AS bytes,
+        CASE WHEN EXISTS (SELECT 1 FROM prev) THEN 0 ELSE 1 END AS objects
+), charged AS (
+    UPDATE user_usage u SET bytes = u.bytes + d.bytes, objects = u.objects + d.objects, updated_at = now()
+    FROM delta d
+    WHERE u.login = $1
+        AND (d.bytes <= 0 OR $4::BIGINT = 0 OR u.bytes + d.bytes <= $4::BIGINT)
+        AND (d.objects = 0 OR $5::BIGINT = 0 OR u.objects + d.objects <= $5::BIGINT)
+    RETURNING u.login
+), saved AS (
+    INSERT INTO object_usage (data_id, login, size)
+    SELECT $2, login, $3::BIGINT FROM charged
+    ON CONFLICT (login, data_id) DO UPDATE SET size = EXCLUDED.size
+)
+SELECT EXISTS (SELECT 1 FROM charged), (SELECT size FROM prev)`
+
+// releaseQuery deletes the object and subtracts its size from usage of the user.
+const releaseQuery = `
+WITH released AS (
+    DELETE FROM object_usage WHERE login = $1 AND data_id = $2 RETURNING login, size
+)
+UPDATE user_usage u SET bytes = u.bytes - r.size, objects = u.objects - 1, updated_at = now()
+FROM released r
+WHERE u.login = r.login`
+
+type UsageRepository struct {
+	db db.Client
+}
+
+func NewRepository(db db.Client) *UsageRepository {
+	return &UsageRepository{
+		db: db,
+	}
+}
+
+// GetUsage returns storage used by the user, usage of the user, who saved nothing, is zero.
+func (rep *UsageRepository) GetUsage(ctx context.Context, login string) (*models.Usage, error) {
+	builder := sq.Select(loginColumn, bytesColumn, objectsColumn).
+		PlaceholderFormat(sq.Dollar).
+		From(usageTable).
+		Where(sq.Eq{loginColumn: login}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "usage_repository.GetUsage",
+		QueryRaw: query,
+	}
+
+	var usage models.Usage
+	err = rep.db.DB().ScanOneContext(ctx, &usage, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return &models.Usage{Login: login}, nil
+		}
+
+		return nil, fmt.Errorf("error retrieving usage: %w", err)
+	}
+
+	return &usage, nil
+}
+
+// ObjectSize returns size charged to the user for the object with id and whether it is charged.
+func (rep *UsageRepository) ObjectSize(ctx context.Context, login string, id string) (int64, bool, error) {
+	builder := sq.Select(sizeColumn).
+		PlaceholderFormat(sq.Dollar).
+		From(objectsTable).
+		Where(sq.Eq{loginColumn: login, dataIDColumn: id}).
+		Limit(1)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, false, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "usage_repository.ObjectSize",
+		QueryRaw: query,
+	}
+
+	var size int64
+	err = rep.db.DB().ScanOneContext(ctx, &size, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return 0, false, nil
+		}
+
+		return 0, false, fmt.Errorf("error retrieving object size: %w", err)
+	}
+
+	return size, true, nil
+}
+
+// Charge charges size of the object with id to usage of the user. Returns size charged for the object
+// before and whether it was charged, so the caller is able to restore it. ErrStorageQuotaExceeded
+// is returned, if the user would exceed the quota, zero quota fields are not checked.
+func (rep *UsageRepository) Charge(ctx context.Context, login string, id string, size int64, quota models.Quota) (int64, bool, error) {
+	builder := sq.Insert(usageTable).
+		PlaceholderFormat(sq.Dollar).
+		Columns(loginColumn).
+		Values(login).
+		Suffix("ON CONFLICT DO NOTHING")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, false, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "usage_repository.CreateUsage",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return 0, false, fmt.Errorf("error creating usage: %w", err)
+	}
+
+	qr = db.Query{
+		Name:     "usage_repository.Charge",
+		QueryRaw: chargeQuery,
+	}
+
+	var (
+		charged bool
+		prev    *int64
+	)
+	err = rep.db.DB().QueryRowContext(ctx, qr, login, id, size, quota.MaxBytes, quota.MaxObjects).Scan(&charged, &prev)
+	if err != nil {
+		return 0, false, fmt.Errorf("error charging usage: %w", err)
+	}
+
+	if !charged {
+		return 0, false, storage.ErrStorageQuotaExceeded
+	}
+
+	if prev == nil {
+		return 0, false, nil
+	}
+
+	return *prev, true, nil
+}
+
+// Release subtracts size of the object with id from usage of the user, unknown objects are ignored.
+func (rep *UsageRepository) Release(ctx context.Context, login string, id string) error {
+	qr := db.Query{
+		Name:     "usage_repository.Release",
+		QueryRaw: releaseQuery,
+	}
+
+	_, err := rep.db.DB().ExecContect(ctx, qr, login, id)
+	if err != nil {
+		return fmt.Errorf("error releasing usage: %w", err)
+	}
+
+	return nil
+}
@@ -2,21 +2,77 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	pgx "github.com/jackc/pgx/v4"
 
 	"github.com/igortoigildin/goph-keeper/internal/client/db"
 )
 
 const (
-	tableName = "users"
+	tableName  = "users"
+	resetTable = "password_resets"
+	loginTable = "login_sessions"
 
-	loginColumn        = "login"
-	passwordHashColumn = "password_hash"
+	loginColumn            = "login"
+	passwordHashColumn     = "password_hash"
+	srpSaltColumn          = "srp_salt"
+	srpVerifierColumn      = "srp_verifier"
+	roleColumn             = "role"
+	disabledColumn         = "disabled"
+	tokensValidAfterColumn = "tokens_valid_after"
+	createdAtColumn        = "created_at"
+	tokenHashColumn        = "token_hash"
+	expiresAtColumn        = "expires_at"
+	idColumn               = "id"
+	clientProofColumn      = "client_proof"
+	serverProofColumn      = "server_proof"
 )
 
+var columns = []string{loginColumn, passwordHashColumn, srpSaltColumn, srpVerifierColumn, roleColumn, disabledColumn,
+	tokensValidAfterColumn, createdAtColumn}
+
+var loginColumns = []string{idColumn, loginColumn, clientProofColumn, serverProofColumn, expiresAtColumn}
+
+// saveLoginQuery saves started login and deletes logins, which were never finished.
+const saveLoginQuery = `
+WITH expired AS (
+    DELETE FROM login_sessions WHERE expires_at <= now()
+)
+INSERT INTO login_sessions (id, login, client_proof, server_proof, expires_at) VALUES ($1, $2, $3, $4, $5)`
+
+// listQuery returns users with storage used by them, usage is kept under the login without @.
+const listQuery = `
+SELECT users.login, users.role, users.disabled, users.created_at,
+    COALESCE(user_usage.bytes, 0) AS bytes, COALESCE(user_usage.objects, 0) AS objects
+FROM users LEFT JOIN user_usage ON user_usage.login = replace(users.login, '@', '')
+ORDER BY users.login`
+
+// deleteDataQuery deletes records of all data saved under the login in one statement.
+const deleteDataQuery = `
+WITH access AS (
+    DELETE FROM access WHERE login = $1
+), refs AS (
+    DELETE FROM file_refs WHERE login = $1
+), blobs AS (
+    DELETE FROM blobs WHERE login = $1
+), secrets AS (
+    DELETE FROM secrets WHERE login = $1
+), uploads AS (
+    DELETE FROM upload_sessions WHERE login = $1
+), objects AS (
+    DELETE FROM object_usage WHERE login = $1
+)
+DELETE FROM user_usage WHERE login = $1`
+
 type UserRepository struct {
 	db db.Client
 }
@@ -27,11 +83,12 @@ func NewRepository(db db.Client) *UserRepository {
 	}
 }
 
-func (rep *UserRepository) SaveUser(ctx context.Context, login string, passHash []byte) (int64, error) {
+// SaveUser saves new user with salt and verifier of the password.
+func (rep *UserRepository) SaveUser(ctx context.Context, login string, salt, verifier []byte) (int64, error) {
 	builder := sq.Insert(tableName).
 		PlaceholderFormat(sq.Dollar).
-		Columns(loginColumn, passwordHashColumn).
-		Values(login, passHash).
+		Columns(loginColumn, srpSaltColumn, srpVerifierColumn).
+		Values(login, salt, verifier).
 		Suffix("ON CONFLICT DO NOTHING RETURNING user_id")
 
 	query, args, err := builder.ToSql()
@@ -47,6 +104,11 @@ func (rep *UserRepository) SaveUser(ctx context.Context, login string, passHash
 	var id int64
 	err = rep.db.DB().QueryRowContext(ctx, qr, args...).Scan(&id)
 	if err != nil {
+		// nothing is returned when insert conflicts with existing login
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, storage.ErrUserExists
+		}
+
 		return 0, err
 	}
 
@@ -54,7 +116,7 @@ func (rep *UserRepository) SaveUser(ctx context.Context, login string, passHash
 }
 
 func (rep *UserRepository) GetUser(ctx context.Context, login string) (*models.UserInfo, error) {
-	builder := sq.Select(loginColumn, passwordHashColumn).
+	builder := sq.Select(columns...).
 		PlaceholderFormat(sq.Dollar).
 		From(tableName).
 		Where(sq.Eq{loginColumn: login}).
@@ -73,8 +135,214 @@ func (rep *UserRepository) GetUser(ctx context.Context, login string) (*models.U
 	var user models.UserInfo
 	err = rep.db.DB().ScanOneContext(ctx, &user, qr, args...)
 	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrUserNotFound
+		}
+
 		return nil, fmt.Errorf("error retrieving info about sepcified user: %w", err)
 	}
 
 	return &user, nil
 }
+
+// ListUsers returns all users ordered by login.
+func (rep *UserRepository) ListUsers(ctx context.Context) ([]models.UserSummary, error) {
+	qr := db.Query{
+		Name:     "user_repository.ListUsers",
+		QueryRaw: listQuery,
+	}
+
+	var users []models.UserSummary
+	err := rep.db.DB().ScanAllContext(ctx, &users, qr)
+	if err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+
+	return users, nil
+}
+
+// SetRole changes role of the user.
+func (rep *UserRepository) SetRole(ctx context.Context, login string, role string) error {
+	return rep.update(ctx, "user_repository.SetRole", login, map[string]any{roleColumn: role})
+}
+
+// SetDisabled disables or enables the user.
+func (rep *UserRepository) SetDisabled(ctx context.Context, login string, disabled bool) error {
+	return rep.update(ctx, "user_repository.SetDisabled", login, map[string]any{disabledColumn: disabled})
+}
+
+// RevokeTokens rejects access tokens issued to the user so far.
+func (rep *UserRepository) RevokeTokens(ctx context.Context, login string) error {
+	return rep.update(ctx, "user_repository.RevokeTokens", login, map[string]any{tokensValidAfterColumn: sq.Expr("now()")})
+}
+
+// SetPassword changes salt and verifier of the password and revokes access tokens issued with the old password.
+func (rep *UserRepository) SetPassword(ctx context.Context, login string, salt, verifier []byte) error {
+	return rep.update(ctx, "user_repository.SetPassword", login, map[string]any{
+		srpSaltColumn:          salt,
+		srpVerifierColumn:      verifier,
+		passwordHashColumn:     nil,
+		tokensValidAfterColumn: sq.Expr("now()"),
+	})
+}
+
+// MigratePassword replaces password hash of the user with salt and verifier of the same password.
+func (rep *UserRepository) MigratePassword(ctx context.Context, login string, salt, verifier []byte) error {
+	return rep.update(ctx, "user_repository.MigratePassword", login, map[string]any{
+		srpSaltColumn:      salt,
+		srpVerifierColumn:  verifier,
+		passwordHashColumn: nil,
+	})
+}
+
+func (rep *UserRepository) update(ctx context.Context, name string, login string, values map[string]any) error {
+	builder := sq.Update(tableName).
+		PlaceholderFormat(sq.Dollar).
+		SetMap(values).
+		Where(sq.Eq{loginColumn: login})
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     name,
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error updating user: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// SaveResetToken saves hash of one-time token, which lets the user set new password until expiresAt.
+func (rep *UserRepository) SaveResetToken(ctx context.Context, login string, tokenHash string, expiresAt time.Time) error {
+	builder := sq.Insert(resetTable).
+		PlaceholderFormat(sq.Dollar).
+		Columns(tokenHashColumn, loginColumn, expiresAtColumn).
+		Values(tokenHash, login, expiresAt)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "user_repository.SaveResetToken",
+		QueryRaw: query,
+	}
+
+	_, err = rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error saving reset token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeResetToken deletes reset token of the user with the hash, ErrResetTokenInvalid is returned
+// if there is no such token or it has expired.
+func (rep *UserRepository) ConsumeResetToken(ctx context.Context, login string, tokenHash string) error {
+	builder := sq.Delete(resetTable).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{tokenHashColumn: tokenHash, loginColumn: login}).
+		Where(sq.Expr(expiresAtColumn + " > now()"))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "user_repository.ConsumeResetToken",
+		QueryRaw: query,
+	}
+
+	tag, err := rep.db.DB().ExecContect(ctx, qr, args...)
+	if err != nil {
+		return fmt.Errorf("error consuming reset token: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return storage.ErrResetTokenInvalid
+	}
+
+	return nil
+}
+
+// DeleteData deletes records of all data saved under the data login of the user,
+// content of the data is removed from object storage by the caller.
+func (rep *UserRepository) DeleteData(ctx context.Context, login string) error {
+	qr := db.Query{
+		Name:     "user_repository.DeleteData",
+		QueryRaw: deleteDataQuery,
+	}
+
+	_, err := rep.db.DB().ExecContect(ctx, qr, login)
+	if err != nil {
+		return fmt.Errorf("error deleting user data: %w", err)
+	}
+
+	return nil
+}
+
+// SaveLoginSession saves started password authenticated login.
+func (rep *UserRepository) SaveLoginSession(ctx context.Context, session *models.LoginSession) error {
+	qr := db.Query{
+		Name:     "user_repository.SaveLoginSession",
+		QueryRaw: saveLoginQuery,
+	}
+
+	_, err := rep.db.DB().ExecContect(ctx, qr, session.ID, session.Login, session.ClientProof, session.ServerProof,
+		session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("error saving login session: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeLoginSession deletes started login and returns it, so that it can be finished only once.
+// storage.ErrLoginSessionInvalid is returned if there is no such login or it has expired.
+func (rep *UserRepository) ConsumeLoginSession(ctx context.Context, id string) (*models.LoginSession, error) {
+	// malformed id can not match any login, the query on uuid column would fail with internal error instead
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, storage.ErrLoginSessionInvalid
+	}
+
+	builder := sq.Delete(loginTable).
+		PlaceholderFormat(sq.Dollar).
+		Where(sq.Eq{idColumn: id}).
+		Where(sq.Expr(expiresAtColumn + " > now()")).
+		Suffix("RETURNING " + strings.Join(loginColumns, ", "))
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("error building SQL query: %w", err)
+	}
+
+	qr := db.Query{
+		Name:     "user_repository.ConsumeLoginSession",
+		QueryRaw: query,
+	}
+
+	var session models.LoginSession
+	err = rep.db.DB().ScanOneContext(ctx, &session, qr, args...)
+	if err != nil {
+		if pgxscan.NotFound(err) {
+			return nil, storage.ErrLoginSessionInvalid
+		}
+
+		return nil, fmt.Errorf("error consuming login session: %w", err)
+	}
+
+	return &session, nil
+}
@@ -1,29 +1,131 @@
 package storage
 
 import (
-	"bytes"
 	"context"
-	"errors"
+	"io"
+	"time"
 
 	model "github.com/igortoigildin/goph-keeper/internal/server/models"
 	models "github.com/igortoigildin/goph-keeper/internal/server/models"
-	fl "github.com/igortoigildin/goph-keeper/pkg/file"
 )
 
 var (
-	ErrUserExists   = errors.New("user already exists")
-	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = NewError(ErrConflict, "USER_EXISTS", "user already exists")
+	ErrUserNotFound = NewError(ErrNotFound, "USER_NOT_FOUND", "user not found")
+
+	ErrResetTokenInvalid   = NewError(ErrPermissionDenied, "RESET_TOKEN_INVALID", "reset token is invalid or expired")
+	ErrLoginSessionInvalid = NewError(ErrUnauthenticated, "LOGIN_SESSION_INVALID", "login session is invalid or expired")
+
+	ErrTokenExists   = NewError(ErrConflict, "TOKEN_EXISTS", "token with the name already exists")
+	ErrTokenNotFound = NewError(ErrNotFound, "TOKEN_NOT_FOUND", "token not found")
+	ErrOutOfScope    = NewError(ErrPermissionDenied, "TOKEN_SCOPE_EXCEEDED", "token does not grant access to the data")
+
+	ErrCertBound    = NewError(ErrConflict, "CERT_BOUND", "certificate identity is already bound to a user")
+	ErrCertNotBound = NewError(ErrUnauthenticated, "CERT_NOT_BOUND", "certificate is not bound to a user")
+
+	ErrUploadNotFound = NewError(ErrNotFound, "UPLOAD_NOT_FOUND", "upload session not found")
+	ErrInvalidRange   = NewError(ErrOutOfRange, "INVALID_RANGE", "requested range is not satisfiable")
+
+	ErrAccessNotFound = NewError(ErrNotFound, "ACCESS_NOT_FOUND", "access not found")
+	ErrObjectNotFound = NewError(ErrNotFound, "OBJECT_NOT_FOUND", "object not found")
+
+	ErrRefNotFound = NewError(ErrNotFound, "REF_NOT_FOUND", "file reference not found")
+	ErrRefConflict = NewError(ErrConflict, "REF_CONFLICT", "file with the id belongs to another user")
+
+	ErrSecretExists    = NewError(ErrConflict, "SECRET_EXISTS", "secret already exists")
+	ErrSecretNotFound  = NewError(ErrNotFound, "SECRET_NOT_FOUND", "secret not found")
+	ErrVersionMismatch = NewError(ErrAborted, "VERSION_MISMATCH", "secret version does not match")
+
+	ErrStorageQuotaExceeded = NewError(ErrQuotaExceeded, "STORAGE_QUOTA_EXCEEDED", "storage quota exceeded")
 )
 
 type UserRepository interface {
 	GetUser(ctx context.Context, email string) (*models.UserInfo, error)
-	SaveUser(ctx context.Context, email string, passHash []byte) (uid int64, err error)
+	SaveUser(ctx context.Context, email string, salt, verifier []byte) (uid int64, err error)
+	ListUsers(ctx context.Context) ([]models.UserSummary, error)
+	SetRole(ctx context.Context, login string, role string) error
+	SetDisabled(ctx context.Context, login string, disabled bool) error
+	RevokeTokens(ctx context.Context, login string) error
+	SetPassword(ctx context.Context, login string, salt, verifier []byte) error
+	MigratePassword(ctx context.Context, login string, salt, verifier []byte) error
+	SaveResetToken(ctx context.Context, login string, tokenHash string, expiresAt time.Time) error
+	ConsumeResetToken(ctx context.Context, login string, tokenHash string) error
+	SaveLoginSession(ctx context.Context, session *models.LoginSession) error
+	ConsumeLoginSession(ctx context.Context, id string) (*models.LoginSession, error)
+	DeleteData(ctx context.Context, login string) error
+}
+
+type TokenRepository interface {
+	SaveToken(ctx context.Context, token *models.APIToken) (*models.APIToken, error)
+	GetToken(ctx context.Context, tokenHash string) (*models.APIToken, error)
+	ListTokens(ctx context.Context, login string) ([]models.APIToken, error)
+	RevokeToken(ctx context.Context, login string, id string) error
+}
+
+type CertRepository interface {
+	BindCert(ctx context.Context, identity string, login string) error
+	UnbindCert(ctx context.Context, identity string) error
+	FindBinding(ctx context.Context, identities []string) (*models.CertBinding, error)
+	ListCerts(ctx context.Context, login string) ([]models.CertBinding, error)
+}
+
+type AccessRepository interface {
+	GetAccess(ctx context.Context, login string, id string) (*models.FileInfo, error)
+	SaveAccess(ctx context.Context, login string, id string) error
+	DeleteAccess(ctx context.Context, login string, id string) error
 }
 
 type DataRepository interface {
-	SaveFile(ctx context.Context, file *fl.File, login string, id string, meta string) (string, error)
-	DownloadFile(ctx context.Context, bucketName, objectName string) (*bytes.Buffer, string, error)
-	SaveTextData(ctx context.Context, data any, login string, id string, info string, dataType string) (string, error)
-	DownloadTextData(ctx context.Context, bucketName, objectName, dataType string) ([]byte, string, error)
+	Ping(ctx context.Context) error
+	SaveFile(ctx context.Context, reader io.Reader, login string, id string, meta string, compression string) (string, error)
+	DownloadFile(ctx context.Context, bucketName, objectName string, offset, length int64) (*model.FileObject, error)
+	SaveTextData(ctx context.Context, data any, login string, id string, info string, dataType string, compression string) (string, error)
+	DownloadTextData(ctx context.Context, bucketName, objectName, dataType string) (*model.TextObject, error)
 	ListObjects(ctx context.Context, login string) ([]model.ObjectInfo, error)
+	StatObject(ctx context.Context, login string, key string) (*model.ObjectInfo, error)
+	NewMultipartUpload(ctx context.Context, login string, id string, info string, compression string) (string, error)
+	UploadPart(ctx context.Context, login string, id string, uploadID string, partNumber int, data []byte) error
+	CompleteMultipartUpload(ctx context.Context, login string, id string, uploadID string) (string, error)
+	AbortMultipartUpload(ctx context.Context, login string, id string, uploadID string) error
+	PresignedPostURL(ctx context.Context, login string, id string, maxSize int64, expiry time.Duration) (*model.PresignedURL, error)
+	PresignedGetURL(ctx context.Context, login string, id string, expiry time.Duration) (*model.PresignedURL, error)
+	CompleteFile(ctx context.Context, login string, id string, info string, checksum string, compression string) (*model.UploadResult, error)
+	RemoveFile(ctx context.Context, login string, id string) error
+	StatFile(ctx context.Context, login string, id string) (*model.FileObject, error)
+	RemoveTextData(ctx context.Context, login, id, dataType string) error
+	NewBlob(ctx context.Context, login string, id string, checksum string) (*model.Blob, error)
+	CopyToBlob(ctx context.Context, login string, id string, blob *model.Blob) error
+	DownloadBlob(ctx context.Context, login string, key string, offset, length int64) (*model.FileObject, error)
+	PresignedBlobURL(ctx context.Context, login string, key string, expiry time.Duration) (*model.PresignedURL, error)
+	RemoveBlob(ctx context.Context, login string, key string) error
+	RemoveBucket(ctx context.Context, login string) error
+}
+
+type BlobRepository interface {
+	AcquireBlob(ctx context.Context, blob *models.Blob) (*models.Blob, error)
+	ReleaseBlob(ctx context.Context, key string) (bool, error)
+	SaveRef(ctx context.Context, ref *models.FileRef) (string, error)
+	GetRef(ctx context.Context, id string) (*models.FileRef, error)
+	DeleteRef(ctx context.Context, id string) (*models.FileRef, error)
+	ListRefs(ctx context.Context, login string) ([]models.FileRef, error)
+}
+
+type SecretRepository interface {
+	CreateSecret(ctx context.Context, secret *models.SecretInfo) (*models.SecretInfo, error)
+	GetSecret(ctx context.Context, id string) (*models.SecretInfo, error)
+	UpdateSecret(ctx context.Context, secret *models.SecretInfo, version int64) (*models.SecretInfo, error)
+	DeleteSecret(ctx context.Context, id string) error
+	ListSecrets(ctx context.Context, login string, filter models.SecretFilter) ([]models.SecretInfo, error)
+}
+
+type AuditRepository interface {
+	SaveEvents(ctx context.Context, events []models.AuditEvent) error
+	QueryEvents(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+}
+
+type UsageRepository interface {
+	GetUsage(ctx context.Context, login string) (*models.Usage, error)
+	ObjectSize(ctx context.Context, login string, id string) (int64, bool, error)
+	Charge(ctx context.Context, login string, id string, size int64, quota models.Quota) (int64, bool, error)
+	Release(ctx context.Context, login string, id string) error
 }
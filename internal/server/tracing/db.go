@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBClient wraps db client, so every query made through it is traced in a span named by db.Query.Name.
+func DBClient(client db.Client) db.Client {
+	return &dbClient{Client: client, db: &tracedDB{DB: client.DB()}}
+}
+
+type dbClient struct {
+	db.Client
+	db db.DB
+}
+
+func (c *dbClient) DB() db.DB {
+	return c.db
+}
+
+type tracedDB struct {
+	db.DB
+}
+
+func (d *tracedDB) ScanOneContext(ctx context.Context, dest interface{}, q db.Query, args ...interface{}) error {
+	ctx, span := startQuery(ctx, q)
+
+	err := d.DB.ScanOneContext(ctx, dest, q, args...)
+	end(span, err)
+
+	return err
+}
+
+func (d *tracedDB) ScanAllContext(ctx context.Context, dest interface{}, q db.Query, args ...interface{}) error {
+	ctx, span := startQuery(ctx, q)
+
+	err := d.DB.ScanAllContext(ctx, dest, q, args...)
+	end(span, err)
+
+	return err
+}
+
+func (d *tracedDB) ExecContect(ctx context.Context, q db.Query, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, span := startQuery(ctx, q)
+
+	tag, err := d.DB.ExecContect(ctx, q, args...)
+	end(span, err)
+
+	return tag, err
+}
+
+func (d *tracedDB) QueryContext(ctx context.Context, q db.Query, args ...interface{}) (pgx.Rows, error) {
+	ctx, span := startQuery(ctx, q)
+
+	rows, err := d.DB.QueryContext(ctx, q, args...)
+	end(span, err)
+
+	return rows, err
+}
+
+// QueryRowContext ends the span once the row is scanned, since the query error is returned by Scan.
+func (d *tracedDB) QueryRowContext(ctx context.Context, q db.Query, args ...interface{}) pgx.Row {
+	ctx, span := startQuery(ctx, q)
+
+	return &tracedRow{Row: d.DB.QueryRowContext(ctx, q, args...), span: span}
+}
+
+type tracedRow struct {
+	pgx.Row
+	span trace.Span
+}
+
+func (r *tracedRow) Scan(dest ...interface{}) error {
+	err := r.Row.Scan(dest...)
+	end(r.span, err)
+
+	return err
+}
+
+func startQuery(ctx context.Context, q db.Query) (context.Context, trace.Span) {
+	return tracer.Start(ctx, q.Name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", q.QueryRaw),
+		),
+	)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows) || pgxscan.NotFound(err)
+}
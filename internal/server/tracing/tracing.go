@@ -0,0 +1,23 @@
+package tracing
+
+import (
+	"errors"
+
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/igortoigildin/goph-keeper/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = tracing.Tracer("github.com/igortoigildin/goph-keeper/internal/server/tracing")
+
+// end records the error of the call in the span and ends it. Missing objects and rows
+// are valid results, so they are not recorded as errors.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, storage.ErrObjectNotFound) && !isNoRows(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,168 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
+	"github.com/igortoigildin/goph-keeper/internal/server/storage"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var recorder = tracetest.NewSpanRecorder()
+
+func TestMain(m *testing.M) {
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+
+	code := m.Run()
+	provider.Shutdown(context.Background())
+
+	os.Exit(code)
+}
+
+func TestDBClient_SpanNamedByQuery(t *testing.T) {
+	client := DBClient(&fakeClient{db: &fakeDB{}})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	q := db.Query{Name: "user_repository.GetUser", QueryRaw: "SELECT 1"}
+
+	_, err := client.DB().ExecContect(ctx, q)
+	require.NoError(t, err)
+	parent.End()
+
+	span := lastSpan(t, "user_repository.GetUser")
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	assert.Contains(t, span.Attributes(), attribute.String("db.statement", "SELECT 1"))
+	assert.Equal(t, codes.Unset, span.Status().Code)
+}
+
+func TestDBClient_RecordsErrors(t *testing.T) {
+	errQuery := errors.New("connection reset")
+	client := DBClient(&fakeClient{db: &fakeDB{err: errQuery}})
+
+	_, err := client.DB().ExecContect(context.Background(), db.Query{Name: "failing"})
+	require.ErrorIs(t, err, errQuery)
+
+	span := lastSpan(t, "failing")
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Equal(t, errQuery.Error(), span.Status().Description)
+
+	// missing row is a valid result
+	client = DBClient(&fakeClient{db: &fakeDB{err: pgx.ErrNoRows}})
+
+	var n int
+	err = client.DB().QueryRowContext(context.Background(), db.Query{Name: "missing"}).Scan(&n)
+	require.ErrorIs(t, err, pgx.ErrNoRows)
+
+	assert.Equal(t, codes.Unset, lastSpan(t, "missing").Status().Code)
+}
+
+func TestDBClient_RowSpanEndsOnScan(t *testing.T) {
+	client := DBClient(&fakeClient{db: &fakeDB{}})
+
+	row := client.DB().QueryRowContext(context.Background(), db.Query{Name: "row"})
+	assert.Nil(t, findSpan("row"))
+
+	var n int
+	require.NoError(t, row.Scan(&n))
+	assert.NotNil(t, findSpan("row"))
+}
+
+func TestDataRepository_Spans(t *testing.T) {
+	errSave := errors.New("bucket is unavailable")
+	repo := DataRepository(&fakeDataRepository{saveErr: errSave, downloadErr: storage.ErrObjectNotFound})
+
+	_, err := repo.SaveFile(context.Background(), nil, "login", "id", "", "")
+	require.ErrorIs(t, err, errSave)
+
+	span := lastSpan(t, "minio.SaveFile")
+	assert.Equal(t, codes.Error, span.Status().Code)
+	assert.Contains(t, span.Attributes(), attribute.String("storage.system", "minio"))
+
+	// missing object is a valid result
+	_, err = repo.DownloadFile(context.Background(), "bucket", "object", 0, 0)
+	require.ErrorIs(t, err, storage.ErrObjectNotFound)
+
+	assert.Equal(t, codes.Unset, lastSpan(t, "minio.DownloadFile").Status().Code)
+}
+
+// lastSpan returns the last ended span with the name.
+func lastSpan(t *testing.T, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+
+	span := findSpan(name)
+	require.NotNil(t, span, "span %q is not recorded", name)
+
+	return span
+}
+
+func findSpan(name string) sdktrace.ReadOnlySpan {
+	spans := recorder.Ended()
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i].Name() == name {
+			return spans[i]
+		}
+	}
+
+	return nil
+}
+
+type fakeClient struct {
+	db.Client
+	db db.DB
+}
+
+func (c *fakeClient) DB() db.DB {
+	return c.db
+}
+
+// fakeDB fails every query with err.
+type fakeDB struct {
+	db.DB
+	err error
+}
+
+func (d *fakeDB) ExecContect(context.Context, db.Query, ...interface{}) (pgconn.CommandTag, error) {
+	return nil, d.err
+}
+
+func (d *fakeDB) QueryRowContext(context.Context, db.Query, ...interface{}) pgx.Row {
+	return fakeRow{err: d.err}
+}
+
+type fakeRow struct {
+	err error
+}
+
+func (r fakeRow) Scan(...interface{}) error {
+	return r.err
+}
+
+type fakeDataRepository struct {
+	storage.DataRepository
+	saveErr     error
+	downloadErr error
+}
+
+func (r *fakeDataRepository) SaveFile(context.Context, io.Reader, string, string, string, string) (string, error) {
+	return "", r.saveErr
+}
+
+func (r *fakeDataRepository) DownloadFile(context.Context, string, string, int64, int64) (*model.FileObject, error) {
+	return nil, r.downloadErr
+}
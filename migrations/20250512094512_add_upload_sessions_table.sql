@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS upload_sessions (
+    upload_id TEXT PRIMARY KEY,
+    login TEXT NOT NULL,
+    data_id TEXT NOT NULL,
+    file_name TEXT NOT NULL,
+    metadata TEXT NOT NULL DEFAULT '',
+    storage_upload_id TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    committed_offset BIGINT NOT NULL DEFAULT 0,
+    parts_count INTEGER NOT NULL DEFAULT 0,
+    hash_state bytea,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE upload_sessions;
+-- +goose StatementEnd
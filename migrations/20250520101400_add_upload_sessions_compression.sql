@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE upload_sessions ADD COLUMN IF NOT EXISTS compression TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE upload_sessions DROP COLUMN compression;
+-- +goose StatementEnd
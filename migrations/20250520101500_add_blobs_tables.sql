@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS blobs (
+    object_key TEXT PRIMARY KEY,
+    login TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    etag TEXT NOT NULL,
+    ref_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (login, sha256)
+);
+
+CREATE TABLE IF NOT EXISTS file_refs (
+    data_id TEXT PRIMARY KEY,
+    login TEXT NOT NULL,
+    object_key TEXT NOT NULL,
+    sha256 TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    etag TEXT NOT NULL,
+    metadata TEXT NOT NULL DEFAULT '',
+    compression TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS file_refs_login_idx ON file_refs (login);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE file_refs;
+DROP TABLE blobs;
+-- +goose StatementEnd
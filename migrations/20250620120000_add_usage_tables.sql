@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS user_usage (
+    login TEXT PRIMARY KEY,
+    bytes BIGINT NOT NULL DEFAULT 0,
+    objects BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS object_usage (
+    data_id TEXT PRIMARY KEY,
+    login TEXT NOT NULL,
+    size BIGINT NOT NULL
+);
+
+-- Files saved before usage was tracked are charged by size of their content,
+-- other data is charged by its size once it is saved again.
+INSERT INTO object_usage (data_id, login, size)
+SELECT access.data_id, access.login, COALESCE(file_refs.size, 0)
+FROM access LEFT JOIN file_refs ON file_refs.data_id = access.data_id
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_usage (login, bytes, objects)
+SELECT login, SUM(size), COUNT(*) FROM object_usage GROUP BY login;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE object_usage;
+DROP TABLE user_usage;
+-- +goose StatementEnd
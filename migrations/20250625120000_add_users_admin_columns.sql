@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS role TEXT NOT NULL DEFAULT 'user',
+    ADD COLUMN IF NOT EXISTS disabled BOOLEAN NOT NULL DEFAULT false,
+    -- access tokens issued before the time are rejected, so the user is logged out everywhere
+    ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMPTZ NOT NULL DEFAULT 'epoch',
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'admin'));
+
+CREATE TABLE IF NOT EXISTS password_resets (
+    token_hash TEXT PRIMARY KEY,
+    login TEXT NOT NULL REFERENCES users (login) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_resets_login_idx ON password_resets (login);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE password_resets;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_role_check,
+    DROP COLUMN created_at,
+    DROP COLUMN tokens_valid_after,
+    DROP COLUMN disabled,
+    DROP COLUMN role;
+-- +goose StatementEnd
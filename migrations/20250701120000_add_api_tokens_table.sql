@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    login TEXT NOT NULL REFERENCES users (login) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    read_only BOOLEAN NOT NULL DEFAULT false,
+    -- empty lists do not restrict access
+    secret_ids TEXT[] NOT NULL DEFAULT '{}',
+    data_types TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ
+);
+
+-- names of tokens in use are unique, so they can be told apart in the list
+CREATE UNIQUE INDEX IF NOT EXISTS api_tokens_login_name_idx ON api_tokens (login, name) WHERE revoked_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE api_tokens;
+-- +goose StatementEnd
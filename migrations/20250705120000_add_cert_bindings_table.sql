@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS cert_bindings (
+    -- subject or subject alternative name of client certificates, e.g. email:user@example.com
+    identity TEXT PRIMARY KEY,
+    login TEXT NOT NULL REFERENCES users (login) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS cert_bindings_login_idx ON cert_bindings (login);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cert_bindings;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    -- salt and verifier of SRP-6a, password_hash is kept only for users, who have not logged in since
+    ADD COLUMN IF NOT EXISTS srp_salt BYTEA,
+    ADD COLUMN IF NOT EXISTS srp_verifier BYTEA,
+    ALTER COLUMN password_hash DROP NOT NULL;
+
+ALTER TABLE users ADD CONSTRAINT users_credentials_check
+    CHECK (password_hash IS NOT NULL OR (srp_salt IS NOT NULL AND srp_verifier IS NOT NULL));
+
+-- started password authenticated logins, each of them can be finished once
+CREATE TABLE IF NOT EXISTS login_sessions (
+    id UUID PRIMARY KEY,
+    login TEXT NOT NULL,
+    -- proof expected from the client and proof of the server returned to it
+    client_proof BYTEA NOT NULL,
+    server_proof BYTEA NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_sessions_expires_at_idx ON login_sessions (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_sessions;
+
+-- password_hash stays nullable, users without it have to reset their password
+ALTER TABLE users
+    DROP CONSTRAINT users_credentials_check,
+    DROP COLUMN srp_verifier,
+    DROP COLUMN srp_salt;
+-- +goose StatementEnd
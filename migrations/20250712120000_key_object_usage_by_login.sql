@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- usage of an object is kept per user, so saving under an id of another user never moves it between users
+ALTER TABLE object_usage DROP CONSTRAINT object_usage_pkey;
+ALTER TABLE object_usage ADD PRIMARY KEY (login, data_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE object_usage DROP CONSTRAINT object_usage_pkey;
+ALTER TABLE object_usage ADD PRIMARY KEY (data_id);
+-- +goose StatementEnd
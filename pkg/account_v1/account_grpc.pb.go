@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: account.proto
+
+package account_v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AccountV1_GetUsage_FullMethodName = "/account_v1.AccountV1/GetUsage"
+)
+
+// AccountV1Client is the client API for AccountV1 service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AccountV1 gives access to the account of the caller.
+type AccountV1Client interface {
+	// GetUsage returns storage used by the caller and limits applied to it.
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
+}
+
+type accountV1Client struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccountV1Client(cc grpc.ClientConnInterface) AccountV1Client {
+	return &accountV1Client{cc}
+}
+
+func (c *accountV1Client) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, AccountV1_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccountV1Server is the server API for AccountV1 service.
+// All implementations must embed UnimplementedAccountV1Server
+// for forward compatibility.
+//
+// AccountV1 gives access to the account of the caller.
+type AccountV1Server interface {
+	// GetUsage returns storage used by the caller and limits applied to it.
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
+	mustEmbedUnimplementedAccountV1Server()
+}
+
+// UnimplementedAccountV1Server must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccountV1Server struct{}
+
+func (UnimplementedAccountV1Server) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedAccountV1Server) mustEmbedUnimplementedAccountV1Server() {}
+func (UnimplementedAccountV1Server) testEmbeddedByValue()                   {}
+
+// UnsafeAccountV1Server may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccountV1Server will
+// result in compilation errors.
+type UnsafeAccountV1Server interface {
+	mustEmbedUnimplementedAccountV1Server()
+}
+
+func RegisterAccountV1Server(s grpc.ServiceRegistrar, srv AccountV1Server) {
+	// If the following call pancis, it indicates UnimplementedAccountV1Server was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AccountV1_ServiceDesc, srv)
+}
+
+func _AccountV1_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccountV1Server).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccountV1_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccountV1Server).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccountV1_ServiceDesc is the grpc.ServiceDesc for AccountV1 service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccountV1_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "account_v1.AccountV1",
+	HandlerType: (*AccountV1Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetUsage",
+			Handler:    _AccountV1_GetUsage_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "account.proto",
+}
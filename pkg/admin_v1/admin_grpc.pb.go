@@ -0,0 +1,326 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: admin.proto
+
+package admin_v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AdminV1_ListUsers_FullMethodName       = "/admin_v1.AdminV1/ListUsers"
+	AdminV1_DisableUser_FullMethodName     = "/admin_v1.AdminV1/DisableUser"
+	AdminV1_EnableUser_FullMethodName      = "/admin_v1.AdminV1/EnableUser"
+	AdminV1_LogoutUser_FullMethodName      = "/admin_v1.AdminV1/LogoutUser"
+	AdminV1_IssueResetToken_FullMethodName = "/admin_v1.AdminV1/IssueResetToken"
+	AdminV1_DeleteUserData_FullMethodName  = "/admin_v1.AdminV1/DeleteUserData"
+)
+
+// AdminV1Client is the client API for AdminV1 service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AdminV1 manages users of the server, it is available to administrators only.
+type AdminV1Client interface {
+	// ListUsers returns all users with storage used by them.
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// DisableUser rejects login and access tokens of the user until it is enabled again.
+	DisableUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	EnableUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// LogoutUser revokes all access tokens issued to the user.
+	LogoutUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// IssueResetToken returns one-time token, the user sets new password with by AuthV1.ResetPassword.
+	IssueResetToken(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*IssueResetTokenResponse, error)
+	// DeleteUserData removes all secrets and files of the user, the account itself is kept.
+	DeleteUserData(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type adminV1Client struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminV1Client(cc grpc.ClientConnInterface) AdminV1Client {
+	return &adminV1Client{cc}
+}
+
+func (c *adminV1Client) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, AdminV1_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminV1Client) DisableUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdminV1_DisableUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminV1Client) EnableUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdminV1_EnableUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminV1Client) LogoutUser(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdminV1_LogoutUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminV1Client) IssueResetToken(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*IssueResetTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IssueResetTokenResponse)
+	err := c.cc.Invoke(ctx, AdminV1_IssueResetToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminV1Client) DeleteUserData(ctx context.Context, in *UserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AdminV1_DeleteUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminV1Server is the server API for AdminV1 service.
+// All implementations must embed UnimplementedAdminV1Server
+// for forward compatibility.
+//
+// AdminV1 manages users of the server, it is available to administrators only.
+type AdminV1Server interface {
+	// ListUsers returns all users with storage used by them.
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	// DisableUser rejects login and access tokens of the user until it is enabled again.
+	DisableUser(context.Context, *UserRequest) (*emptypb.Empty, error)
+	EnableUser(context.Context, *UserRequest) (*emptypb.Empty, error)
+	// LogoutUser revokes all access tokens issued to the user.
+	LogoutUser(context.Context, *UserRequest) (*emptypb.Empty, error)
+	// IssueResetToken returns one-time token, the user sets new password with by AuthV1.ResetPassword.
+	IssueResetToken(context.Context, *UserRequest) (*IssueResetTokenResponse, error)
+	// DeleteUserData removes all secrets and files of the user, the account itself is kept.
+	DeleteUserData(context.Context, *UserRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedAdminV1Server()
+}
+
+// UnimplementedAdminV1Server must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminV1Server struct{}
+
+func (UnimplementedAdminV1Server) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedAdminV1Server) DisableUser(context.Context, *UserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableUser not implemented")
+}
+func (UnimplementedAdminV1Server) EnableUser(context.Context, *UserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableUser not implemented")
+}
+func (UnimplementedAdminV1Server) LogoutUser(context.Context, *UserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogoutUser not implemented")
+}
+func (UnimplementedAdminV1Server) IssueResetToken(context.Context, *UserRequest) (*IssueResetTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IssueResetToken not implemented")
+}
+func (UnimplementedAdminV1Server) DeleteUserData(context.Context, *UserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserData not implemented")
+}
+func (UnimplementedAdminV1Server) mustEmbedUnimplementedAdminV1Server() {}
+func (UnimplementedAdminV1Server) testEmbeddedByValue()                 {}
+
+// UnsafeAdminV1Server may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminV1Server will
+// result in compilation errors.
+type UnsafeAdminV1Server interface {
+	mustEmbedUnimplementedAdminV1Server()
+}
+
+func RegisterAdminV1Server(s grpc.ServiceRegistrar, srv AdminV1Server) {
+	// If the following call pancis, it indicates UnimplementedAdminV1Server was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AdminV1_ServiceDesc, srv)
+}
+
+func _AdminV1_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminV1_DisableUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).DisableUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_DisableUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).DisableUser(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminV1_EnableUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).EnableUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_EnableUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).EnableUser(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminV1_LogoutUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).LogoutUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_LogoutUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).LogoutUser(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminV1_IssueResetToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).IssueResetToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_IssueResetToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).IssueResetToken(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AdminV1_DeleteUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminV1Server).DeleteUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AdminV1_DeleteUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminV1Server).DeleteUserData(ctx, req.(*UserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AdminV1_ServiceDesc is the grpc.ServiceDesc for AdminV1 service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AdminV1_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "admin_v1.AdminV1",
+	HandlerType: (*AdminV1Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUsers",
+			Handler:    _AdminV1_ListUsers_Handler,
+		},
+		{
+			MethodName: "DisableUser",
+			Handler:    _AdminV1_DisableUser_Handler,
+		},
+		{
+			MethodName: "EnableUser",
+			Handler:    _AdminV1_EnableUser_Handler,
+		},
+		{
+			MethodName: "LogoutUser",
+			Handler:    _AdminV1_LogoutUser_Handler,
+		},
+		{
+			MethodName: "IssueResetToken",
+			Handler:    _AdminV1_IssueResetToken_Handler,
+		},
+		{
+			MethodName: "DeleteUserData",
+			Handler:    _AdminV1_DeleteUserData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: audit.proto
+
+package audit_v1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	AuditV1_Query_FullMethodName = "/audit_v1.AuditV1/Query"
+)
+
+// AuditV1Client is the client API for AuditV1 service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AuditV1 gives access to the log of secret accesses and account events.
+type AuditV1Client interface {
+	// Query returns events of the caller matching the filter, newest first.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error)
+}
+
+type auditV1Client struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAuditV1Client(cc grpc.ClientConnInterface) AuditV1Client {
+	return &auditV1Client{cc}
+}
+
+func (c *auditV1Client) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (*QueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryResponse)
+	err := c.cc.Invoke(ctx, AuditV1_Query_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuditV1Server is the server API for AuditV1 service.
+// All implementations must embed UnimplementedAuditV1Server
+// for forward compatibility.
+//
+// AuditV1 gives access to the log of secret accesses and account events.
+type AuditV1Server interface {
+	// Query returns events of the caller matching the filter, newest first.
+	Query(context.Context, *QueryRequest) (*QueryResponse, error)
+	mustEmbedUnimplementedAuditV1Server()
+}
+
+// UnimplementedAuditV1Server must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAuditV1Server struct{}
+
+func (UnimplementedAuditV1Server) Query(context.Context, *QueryRequest) (*QueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Query not implemented")
+}
+func (UnimplementedAuditV1Server) mustEmbedUnimplementedAuditV1Server() {}
+func (UnimplementedAuditV1Server) testEmbeddedByValue()                 {}
+
+// UnsafeAuditV1Server may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AuditV1Server will
+// result in compilation errors.
+type UnsafeAuditV1Server interface {
+	mustEmbedUnimplementedAuditV1Server()
+}
+
+func RegisterAuditV1Server(s grpc.ServiceRegistrar, srv AuditV1Server) {
+	// If the following call pancis, it indicates UnimplementedAuditV1Server was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AuditV1_ServiceDesc, srv)
+}
+
+func _AuditV1_Query_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuditV1Server).Query(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuditV1_Query_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuditV1Server).Query(ctx, req.(*QueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AuditV1_ServiceDesc is the grpc.ServiceDesc for AuditV1 service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AuditV1_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "audit_v1.AuditV1",
+	HandlerType: (*AuditV1Server)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Query",
+			Handler:    _AuditV1_Query_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "audit.proto",
+}
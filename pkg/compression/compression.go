@@ -0,0 +1,104 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	None = ""
+	Zstd = "zstd"
+
+	// MinSize is the payload size below which compression is skipped,
+	// since the gain on small payloads does not pay for zstd frame overhead.
+	MinSize = 1024
+)
+
+var ErrUnsupported = errors.New("unsupported compression")
+
+// Validate returns ErrUnsupported if payloads compressed with algo can not be stored.
+func Validate(algo string) error {
+	switch algo {
+	case None, Zstd:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupported, algo)
+	}
+}
+
+// Compress compresses data with zstd and returns compressed data with the name of the algorithm.
+// Data smaller than MinSize or not reduced by compression is returned as is with None.
+func Compress(data []byte) ([]byte, string, error) {
+	if len(data) < MinSize {
+		return data, None, nil
+	}
+
+	enc, err := zstd.NewWriter(nil)
+	if err != nil {
+		return nil, None, fmt.Errorf("error creating zstd encoder: %w", err)
+	}
+	defer enc.Close()
+
+	compressed := enc.EncodeAll(data, make([]byte, 0, len(data)))
+	if len(compressed) >= len(data) {
+		return data, None, nil
+	}
+
+	return compressed, Zstd, nil
+}
+
+// Decompress reverts Compress for data compressed with algo.
+func Decompress(data []byte, algo string) ([]byte, error) {
+	if err := Validate(algo); err != nil {
+		return nil, err
+	}
+
+	if algo == None {
+		return data, nil
+	}
+
+	dec, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating zstd decoder: %w", err)
+	}
+	defer dec.Close()
+
+	res, err := dec.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing data: %w", err)
+	}
+
+	return res, nil
+}
+
+// NewWriter returns writer compressing data written to w with zstd.
+// Closing the writer flushes compressed data, but does not close w.
+func NewWriter(w io.Writer) (io.WriteCloser, error) {
+	enc, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("error creating zstd encoder: %w", err)
+	}
+
+	return enc, nil
+}
+
+// NewReader returns reader decompressing data read from r with algo.
+func NewReader(r io.Reader, algo string) (io.ReadCloser, error) {
+	if err := Validate(algo); err != nil {
+		return nil, err
+	}
+
+	if algo == None {
+		return io.NopCloser(r), nil
+	}
+
+	dec, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error creating zstd decoder: %w", err)
+	}
+
+	return dec.IOReadCloser(), nil
+}
@@ -23,6 +23,7 @@ const (
 	DownloadV1_DownloadText_FullMethodName     = "/download_v1.DownloadV1/DownloadText"
 	DownloadV1_DownloadFile_FullMethodName     = "/download_v1.DownloadV1/DownloadFile"
 	DownloadV1_DownloadBankData_FullMethodName = "/download_v1.DownloadV1/DownloadBankData"
+	DownloadV1_GetDownloadURL_FullMethodName   = "/download_v1.DownloadV1/GetDownloadURL"
 )
 
 // DownloadV1Client is the client API for DownloadV1 service.
@@ -33,6 +34,8 @@ type DownloadV1Client interface {
 	DownloadText(ctx context.Context, in *DownloadTextRequest, opts ...grpc.CallOption) (*DownloadTextResponse, error)
 	DownloadFile(ctx context.Context, in *DownloadFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadFileResponse], error)
 	DownloadBankData(ctx context.Context, in *DownloadBankDataRequest, opts ...grpc.CallOption) (*DownloadBankDataResponse, error)
+	// Direct download from object storage through presigned url.
+	GetDownloadURL(ctx context.Context, in *GetDownloadURLRequest, opts ...grpc.CallOption) (*GetDownloadURLResponse, error)
 }
 
 type downloadV1Client struct {
@@ -92,6 +95,16 @@ func (c *downloadV1Client) DownloadBankData(ctx context.Context, in *DownloadBan
 	return out, nil
 }
 
+func (c *downloadV1Client) GetDownloadURL(ctx context.Context, in *GetDownloadURLRequest, opts ...grpc.CallOption) (*GetDownloadURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDownloadURLResponse)
+	err := c.cc.Invoke(ctx, DownloadV1_GetDownloadURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DownloadV1Server is the server API for DownloadV1 service.
 // All implementations must embed UnimplementedDownloadV1Server
 // for forward compatibility.
@@ -100,6 +113,8 @@ type DownloadV1Server interface {
 	DownloadText(context.Context, *DownloadTextRequest) (*DownloadTextResponse, error)
 	DownloadFile(*DownloadFileRequest, grpc.ServerStreamingServer[DownloadFileResponse]) error
 	DownloadBankData(context.Context, *DownloadBankDataRequest) (*DownloadBankDataResponse, error)
+	// Direct download from object storage through presigned url.
+	GetDownloadURL(context.Context, *GetDownloadURLRequest) (*GetDownloadURLResponse, error)
 	mustEmbedUnimplementedDownloadV1Server()
 }
 
@@ -122,6 +137,9 @@ func (UnimplementedDownloadV1Server) DownloadFile(*DownloadFileRequest, grpc.Ser
 func (UnimplementedDownloadV1Server) DownloadBankData(context.Context, *DownloadBankDataRequest) (*DownloadBankDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DownloadBankData not implemented")
 }
+func (UnimplementedDownloadV1Server) GetDownloadURL(context.Context, *GetDownloadURLRequest) (*GetDownloadURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDownloadURL not implemented")
+}
 func (UnimplementedDownloadV1Server) mustEmbedUnimplementedDownloadV1Server() {}
 func (UnimplementedDownloadV1Server) testEmbeddedByValue()                    {}
 
@@ -208,6 +226,24 @@ func _DownloadV1_DownloadBankData_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DownloadV1_GetDownloadURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDownloadURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownloadV1Server).GetDownloadURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DownloadV1_GetDownloadURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownloadV1Server).GetDownloadURL(ctx, req.(*GetDownloadURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DownloadV1_ServiceDesc is the grpc.ServiceDesc for DownloadV1 service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -227,6 +263,10 @@ var DownloadV1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DownloadBankData",
 			Handler:    _DownloadV1_DownloadBankData_Handler,
 		},
+		{
+			MethodName: "GetDownloadURL",
+			Handler:    _DownloadV1_GetDownloadURL_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -97,3 +97,19 @@ func Decrypt(encoded string, key []byte) (string, error) {
 
 	return string(plaintext), nil
 }
+
+// NewAEAD returns AES-GCM cipher for the base64 encoded key, so callers encrypting
+// many messages with the same key create the cipher once.
+func NewAEAD(key []byte) (cipher.AEAD, error) {
+	decodedKey, err := base64.StdEncoding.DecodeString(string(key))
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(decodedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,23 @@
+package interceptors
+
+import "google.golang.org/grpc"
+
+// UnaryChain returns the interceptor stack of the server for unary RPCs: request id, access log,
+// observers, panic recovery, JWT check and request validation, in this order. Observers (metrics) run outside of recovery,
+// so they see recovered panics as Internal errors. Requests are authenticated as configured by auth.
+func UnaryChain(auth Auth, observers ...grpc.UnaryServerInterceptor) grpc.ServerOption {
+	chain := []grpc.UnaryServerInterceptor{RequestIDUnaryInterceptor(), LoggingUnaryInterceptor()}
+	chain = append(chain, observers...)
+	chain = append(chain, RecoveryUnaryInterceptor(), JwtUnaryInterceptor(auth), ValidationUnaryInterceptor())
+
+	return grpc.ChainUnaryInterceptor(chain...)
+}
+
+// StreamChain is UnaryChain for streaming RPCs.
+func StreamChain(auth Auth, observers ...grpc.StreamServerInterceptor) grpc.ServerOption {
+	chain := []grpc.StreamServerInterceptor{RequestIDStreamInterceptor(), LoggingStreamInterceptor()}
+	chain = append(chain, observers...)
+	chain = append(chain, RecoveryStreamInterceptor(), JwtStreamInterceptor(auth), ValidationStreamInterceptor())
+
+	return grpc.ChainStreamInterceptor(chain...)
+}
@@ -2,18 +2,118 @@ package interceptors
 
 import (
 	"context"
+	"crypto/x509"
 	"encoding/base64"
-	"errors"
 	"fmt"
-	"os"
 	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt"
+	model "github.com/igortoigildin/goph-keeper/internal/server/models"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
-func JwtUnaryInterceptor() grpc.UnaryServerInterceptor {
+// SessionCheck rejects valid access token of the user issued at issuedAt, e.g. when the user is disabled
+// or logged out after the token was issued. Returned error is passed to the client.
+type SessionCheck func(ctx context.Context, login string, issuedAt time.Time) error
+
+// TokenCheck returns claims of the user, who created API token, restricted by the scope of the token.
+// Returned error is passed to the client.
+type TokenCheck func(ctx context.Context, token string) (*model.UserClaims, error)
+
+// CertCheck returns claims of the user, whom client certificate verified by TLS handshake is bound to.
+// Returned error is passed to the client.
+type CertCheck func(ctx context.Context, cert *x509.Certificate) (*model.UserClaims, error)
+
+// Auth configures authentication of requests by JWT interceptors.
+type Auth struct {
+	// JWTSecret is base64 encoded key access tokens are signed with.
+	JWTSecret string
+	// Sessions additionally checks access tokens, if it is not nil.
+	Sessions SessionCheck
+	// Tokens verifies API tokens, which are rejected if it is nil.
+	Tokens TokenCheck
+	// Certs authenticates users by client certificates, which are ignored if it is nil.
+	Certs CertCheck
+	// RequireCert requires client certificate bound to the user together with access or API token.
+	RequireCert bool
+}
+
+type claimsKey struct{}
+
+type callerKey struct{}
+
+// publicMethods are called without access token. Methods are matched by full name,
+// so that methods of other services with the same name are not exposed.
+var publicMethods = map[string]bool{
+	"/auth_v1.AuthV1/Login":         true,
+	"/auth_v1.AuthV1/LoginStart":    true,
+	"/auth_v1.AuthV1/LoginFinish":   true,
+	"/auth_v1.AuthV1/Register":      true,
+	"/auth_v1.AuthV1/ResetPassword": true,
+}
+
+// readOnlyMethods are available to read-only API tokens.
+var readOnlyMethods = map[string]bool{
+	"/download_v1.DownloadV1/DownloadPassword": true,
+	"/download_v1.DownloadV1/DownloadText":     true,
+	"/download_v1.DownloadV1/DownloadFile":     true,
+	"/download_v1.DownloadV1/DownloadBankData": true,
+	"/download_v1.DownloadV1/GetDownloadURL":   true,
+	"/sync_v1.SyncV1/GetObjectList":            true,
+	"/vault_v2.VaultV2/GetSecret":              true,
+	"/vault_v2.VaultV2/ListSecrets":            true,
+	"/upload_v1.UploadV1/GetUploadStatus":      true,
+	"/audit_v1.AuditV1/Query":                  true,
+	"/account_v1.AccountV1/GetUsage":           true,
+}
+
+// ClaimsFromContext returns claims of the access token verified by JWT interceptors.
+func ClaimsFromContext(ctx context.Context) (*model.UserClaims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*model.UserClaims)
+
+	return claims, ok
+}
+
+// ScopeFromContext returns scope of API token the request is authenticated by,
+// it is nil for access tokens, which grant access to all data of the user.
+func ScopeFromContext(ctx context.Context) *model.TokenScope {
+	if claims, ok := ClaimsFromContext(ctx); ok {
+		return claims.Scope
+	}
+
+	return nil
+}
+
+// WithCaller returns context recording login of the caller authenticated further down the chain,
+// so interceptors running before authentication, e.g. audit log, learn who made the request.
+func WithCaller(ctx context.Context) context.Context {
+	return context.WithValue(ctx, callerKey{}, new(string))
+}
+
+// Caller returns login recorded in the context returned by WithCaller, it is empty if the request
+// is not authenticated.
+func Caller(ctx context.Context) string {
+	if login, ok := ctx.Value(callerKey{}).(*string); ok {
+		return *login
+	}
+
+	return ""
+}
+
+// JwtUnaryInterceptor rejects requests without valid access token, API token or client certificate
+// bound to a user as configured by auth, except for login, registration, password reset and health checks.
+// Claims of the user are available to handlers with ClaimsFromContext, and login metadata is set
+// to login of the authenticated user.
+func JwtUnaryInterceptor(auth Auth) grpc.UnaryServerInterceptor {
+	// Decode the base64 secret key
+	secretKey, keyErr := base64.StdEncoding.DecodeString(auth.JWTSecret)
+
 	return func(
 		ctx context.Context,
 		req interface{},
@@ -21,39 +121,17 @@ func JwtUnaryInterceptor() grpc.UnaryServerInterceptor {
 		handler grpc.UnaryHandler,
 	) (interface{}, error) {
 
-		if strings.HasSuffix(info.FullMethod, "/Login") || strings.HasSuffix(info.FullMethod, "/Register") {
+		if publicMethods[info.FullMethod] || isHealthCheck(info.FullMethod) {
 			return handler(ctx, req)
 		}
 
-		md, ok := metadata.FromIncomingContext(ctx)
-		if !ok {
-			return nil, errors.New("missing metadata")
-		}
-
-		tokens := md.Get("authorization")
-		if len(tokens) == 0 {
-			return nil, errors.New("authorization token not provided")
+		if keyErr != nil {
+			return nil, status.Errorf(codes.Internal, "invalid secret key format: %v", keyErr)
 		}
 
-		tokenStr := strings.TrimPrefix(tokens[0], "Bearer ")
-
-		jwtSecret := os.Getenv("JWT_SECRET")
-		// Decode the base64 secret key
-		secretKey, err := base64.StdEncoding.DecodeString(jwtSecret)
+		ctx, err := authenticate(ctx, info.FullMethod, secretKey, auth)
 		if err != nil {
-			return nil, fmt.Errorf("invalid secret key format: %v", err)
-		}
-
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-
-			return secretKey, nil
-		})
-
-		if err != nil || !token.Valid {
-			return nil, fmt.Errorf("invalid token: %v", err)
+			return nil, err
 		}
 
 		return handler(ctx, req)
@@ -61,7 +139,10 @@ func JwtUnaryInterceptor() grpc.UnaryServerInterceptor {
 }
 
 // Интерсептор для проверки JWT в стриминговых запросах
-func JwtStreamInterceptor() grpc.StreamServerInterceptor {
+func JwtStreamInterceptor(auth Auth) grpc.StreamServerInterceptor {
+	// Decode the base64 secret key
+	secretKey, keyErr := base64.StdEncoding.DecodeString(auth.JWTSecret)
+
 	return func(
 		srv interface{},
 		ss grpc.ServerStream,
@@ -70,40 +151,146 @@ func JwtStreamInterceptor() grpc.StreamServerInterceptor {
 	) error {
 
 		// Пропускаем проверку токена для метода Login
-		if strings.HasSuffix(info.FullMethod, "/Login") {
+		if publicMethods[info.FullMethod] || isHealthCheck(info.FullMethod) {
 			return handler(srv, ss)
 		}
 
-		md, ok := metadata.FromIncomingContext(ss.Context())
-		if !ok {
-			return fmt.Errorf("missing metadata")
+		if keyErr != nil {
+			return status.Errorf(codes.Internal, "invalid secret key format: %v", keyErr)
 		}
 
-		tokens := md.Get("authorization")
-		if len(tokens) == 0 {
-			return fmt.Errorf("authorization token not provided")
+		ctx, err := authenticate(ss.Context(), info.FullMethod, secretKey, auth)
+		if err != nil {
+			return err
 		}
 
-		tokenStr := strings.TrimPrefix(tokens[0], "Bearer ")
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
 
-		jwtSecret := os.Getenv("JWT_SECRET")
-		// Decode the base64 secret key
-		secretKey, err := base64.StdEncoding.DecodeString(jwtSecret)
-		if err != nil {
-			return fmt.Errorf("invalid secret key format: %v", err)
+// authenticate verifies access or API token passed in authorization metadata and client certificate,
+// and returns context carrying claims of the user, where login metadata is replaced by login of the user.
+// Client certificate bound to a user authenticates the user without token, unless it is required together
+// with token, and the token of another user is rejected.
+func authenticate(ctx context.Context, method string, secretKey []byte, auth Auth) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	// clients authenticated by certificate may send empty token
+	var tokenStr string
+	if values := md.Get("authorization"); len(values) > 0 {
+		tokenStr = strings.TrimPrefix(values[0], "Bearer ")
+	}
+
+	certClaims, err := authenticateCert(ctx, auth.Certs)
+	switch {
+	case err != nil && (auth.RequireCert || tokenStr == ""):
+		return nil, err
+	case certClaims == nil && auth.RequireCert:
+		return nil, status.Error(codes.Unauthenticated, "client certificate not provided")
+	}
+
+	var claims *model.UserClaims
+	switch {
+	case tokenStr == "" && (certClaims == nil || auth.RequireCert):
+		return nil, status.Error(codes.Unauthenticated, "authorization token not provided")
+	case tokenStr == "":
+		claims = certClaims
+	case strings.HasPrefix(tokenStr, model.APITokenPrefix):
+		claims, err = authenticateAPIToken(ctx, method, tokenStr, auth.Tokens)
+	default:
+		claims, err = authenticateJWT(ctx, tokenStr, secretKey, auth.Sessions)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if certClaims != nil && certClaims.Login != claims.Login {
+		return nil, status.Error(codes.PermissionDenied, "client certificate is bound to another user")
+	}
+
+	// services take login of the user from metadata, so it can not differ from the token
+	md = md.Copy()
+	md.Set("login", claims.Login)
+	ctx = metadata.NewIncomingContext(ctx, md)
+
+	if caller, ok := ctx.Value(callerKey{}).(*string); ok {
+		*caller = claims.Login
+	}
+
+	return context.WithValue(ctx, claimsKey{}, claims), nil
+}
+
+func authenticateJWT(ctx context.Context, tokenStr string, secretKey []byte, check SessionCheck) (*model.UserClaims, error) {
+	claims := &model.UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return secretKey, nil
-		})
+		return secretKey, nil
+	})
+
+	if err != nil || !token.Valid {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
 
-		if err != nil || !token.Valid {
-			return fmt.Errorf("invalid token: %v", err)
+	if check != nil {
+		if err := check(ctx, claims.Login, time.Unix(claims.IssuedAt, 0)); err != nil {
+			return nil, err
 		}
+	}
 
-		return handler(srv, ss)
+	return claims, nil
+}
+
+// authenticateAPIToken returns claims of API token, if the token may be used to call the method.
+// API tokens can not manage tokens or users, so a leaked token can not be used to create others.
+func authenticateAPIToken(ctx context.Context, method, tokenStr string, tokens TokenCheck) (*model.UserClaims, error) {
+	if tokens == nil {
+		return nil, status.Error(codes.Unauthenticated, "API tokens are not accepted")
+	}
+
+	claims, err := tokens(ctx, tokenStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(method, "/auth_v1.AuthV1/") || strings.HasPrefix(method, "/admin_v1.AdminV1/") {
+		return nil, status.Error(codes.PermissionDenied, "method is not available with API token")
+	}
+
+	if claims.Scope != nil && claims.Scope.ReadOnly && !readOnlyMethods[method] {
+		return nil, status.Error(codes.PermissionDenied, "API token is read-only")
 	}
+
+	return claims, nil
+}
+
+// authenticateCert returns claims of the user, whom client certificate verified by TLS handshake is bound to.
+// Nil claims are returned if the client has not presented certificate or certificates are not checked.
+func authenticateCert(ctx context.Context, certs CertCheck) (*model.UserClaims, error) {
+	if certs == nil {
+		return nil, nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil, nil
+	}
+
+	return certs(ctx, info.State.VerifiedChains[0][0])
+}
+
+// isHealthCheck reports whether the method belongs to grpc health service, which load balancers call without token.
+func isHealthCheck(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.Health/")
 }
@@ -0,0 +1,84 @@
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// LoggingUnaryInterceptor writes access log record for every unary RPC.
+func LoggingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		res, err := handler(ctx, req)
+
+		sent := 0
+		if err == nil {
+			sent = messageSize(res)
+		}
+		logRPC(ctx, info.FullMethod, start, messageSize(req), sent, err)
+
+		return res, err
+	}
+}
+
+// LoggingStreamInterceptor writes access log record for every streaming RPC,
+// sizes are totals of all messages of the stream.
+func LoggingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+
+		stream := &serverStream{ServerStream: ss}
+		err := handler(srv, stream)
+		logRPC(ss.Context(), info.FullMethod, start, stream.received, stream.sent, err)
+
+		return err
+	}
+}
+
+func logRPC(ctx context.Context, method string, start time.Time, received, sent int, err error) {
+	code := status.Code(err)
+
+	fields := []zap.Field{
+		zap.String("request_id", RequestID(ctx)),
+		zap.String("method", method),
+		zap.String("user", userFromContext(ctx)),
+		zap.String("code", code.String()),
+		zap.Duration("duration", time.Since(start)),
+		zap.Int("received_bytes", received),
+		zap.Int("sent_bytes", sent),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+
+	switch code {
+	case codes.OK:
+		logger.Info("rpc", fields...)
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unavailable, codes.DeadlineExceeded, codes.Unimplemented:
+		logger.Error("rpc", fields...)
+	default:
+		logger.Warn("rpc", fields...)
+	}
+}
+
+// userFromContext returns login of the caller passed in metadata, empty for unauthenticated calls.
+func userFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	if logins := md.Get("login"); len(logins) > 0 {
+		return logins[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"runtime/debug"
+
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RecoveryUnaryInterceptor turns panic of the handler into Internal error instead of crashing the server.
+func RecoveryUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (res any, err error) {
+		defer recoverPanic(ctx, info.FullMethod, &err)
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStreamInterceptor is RecoveryUnaryInterceptor for streaming RPCs.
+func RecoveryStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer recoverPanic(ss.Context(), info.FullMethod, &err)
+
+		return handler(srv, ss)
+	}
+}
+
+func recoverPanic(ctx context.Context, method string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	logger.Error("panic while handling rpc",
+		zap.String("request_id", RequestID(ctx)),
+		zap.String("method", method),
+		zap.Any("panic", r),
+		zap.ByteString("stack", debug.Stack()),
+	)
+
+	*err = status.Errorf(codes.Internal, "internal error, request id: %s", RequestID(ctx))
+}
@@ -0,0 +1,75 @@
+package interceptors
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDKey is the metadata key, which carries id of the request from the caller and back in response headers.
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLen limits length of request id accepted from the caller, longer ids are replaced.
+const maxRequestIDLen = 128
+
+type requestIDCtxKey struct{}
+
+// RequestID returns id of the request assigned by RequestIDUnaryInterceptor or RequestIDStreamInterceptor.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+
+	return id
+}
+
+// RequestIDUnaryInterceptor takes request id from incoming metadata or assigns a new one,
+// puts it in the context and sends it back in response headers.
+func RequestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, id := withRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+
+		return handler(ctx, req)
+	}
+}
+
+// RequestIDStreamInterceptor is RequestIDUnaryInterceptor for streaming RPCs.
+func RequestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDKey, id))
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withRequestID(ctx context.Context) (context.Context, string) {
+	id := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(RequestIDKey); len(ids) > 0 && validRequestID(ids[0]) {
+			id = ids[0]
+		}
+	}
+
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	return context.WithValue(ctx, requestIDCtxKey{}, id), id
+}
+
+// validRequestID accepts only short printable ids, so that caller can not inject anything into logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,51 @@
+package interceptors
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// serverStream replaces context of the stream and counts size of messages sent and received.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+
+	sent     int
+	received int
+}
+
+func (s *serverStream) Context() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+
+	return s.ServerStream.Context()
+}
+
+func (s *serverStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent += messageSize(m)
+	}
+
+	return err
+}
+
+func (s *serverStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.received += messageSize(m)
+	}
+
+	return err
+}
+
+func messageSize(m any) int {
+	if msg, ok := m.(proto.Message); ok {
+		return proto.Size(msg)
+	}
+
+	return 0
+}
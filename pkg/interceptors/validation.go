@@ -0,0 +1,156 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"buf.build/go/protovalidate"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// idKey is the metadata key carrying id of the item v1 services work with.
+const idKey = "id"
+
+// requiredRule is id of protovalidate rule violated by missing required field.
+const requiredRule = "required"
+
+// ValidationUnaryInterceptor validates request by rules declared in its proto and id of the item passed
+// in metadata before the handler runs. Invalid requests are rejected with InvalidArgument,
+// violations of every field are returned in google.rpc.BadRequest details.
+func ValidationUnaryInterceptor() grpc.UnaryServerInterceptor {
+	validator := mustValidator()
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := validateID(ctx); err != nil {
+			return nil, err
+		}
+
+		if err := validateMessage(validator, req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// ValidationStreamInterceptor is ValidationUnaryInterceptor for streaming RPCs, every received message is validated.
+func ValidationStreamInterceptor() grpc.StreamServerInterceptor {
+	validator := mustValidator()
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := validateID(ss.Context()); err != nil {
+			return err
+		}
+
+		return handler(srv, &validatedStream{ServerStream: ss, validator: validator})
+	}
+}
+
+type validatedStream struct {
+	grpc.ServerStream
+	validator protovalidate.Validator
+}
+
+func (s *validatedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return validateMessage(s.validator, m)
+}
+
+func mustValidator() protovalidate.Validator {
+	validator, err := protovalidate.New()
+	if err != nil {
+		panic(fmt.Sprintf("failed to create validator: %v", err))
+	}
+
+	return validator
+}
+
+// validateID checks that id of the item passed in metadata is a UUID, the id is not required by all methods.
+func validateID(ctx context.Context) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	ids := md.Get(idKey)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := uuid.Validate(ids[0]); err != nil {
+		return invalidArgument([]*errdetails.BadRequest_FieldViolation{{
+			Field:       "metadata." + idKey,
+			Description: "value must be a valid UUID",
+			Reason:      "string.uuid",
+		}})
+	}
+
+	return nil
+}
+
+func validateMessage(validator protovalidate.Validator, m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	err := validator.Validate(msg)
+	if err == nil {
+		return nil
+	}
+
+	valErr, ok := err.(*protovalidate.ValidationError)
+	if !ok {
+		// rules could not be evaluated, the request is not at fault
+		return status.Errorf(codes.Internal, "failed to validate request: %v", err)
+	}
+
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(valErr.Violations))
+	for _, v := range valErr.Violations {
+		field := protovalidate.FieldPathString(v.Proto.GetField())
+
+		description := v.Proto.GetMessage()
+		if v.Proto.GetRuleId() == requiredRule {
+			description = "is required"
+		}
+
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{
+			Field:       field,
+			Description: description,
+			Reason:      v.Proto.GetRuleId(),
+		})
+	}
+
+	return invalidArgument(violations)
+}
+
+// invalidArgument returns InvalidArgument status listing violations in message and details.
+func invalidArgument(violations []*errdetails.BadRequest_FieldViolation) error {
+	msgs := make([]string, 0, len(violations))
+	for _, v := range violations {
+		if v.GetReason() == requiredRule {
+			msgs = append(msgs, v.GetField()+" "+v.GetDescription())
+		} else {
+			msgs = append(msgs, v.GetField()+": "+v.GetDescription())
+		}
+	}
+
+	st := status.New(codes.InvalidArgument, strings.Join(msgs, "; "))
+
+	withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
@@ -2,6 +2,7 @@ package session
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -9,7 +10,16 @@ import (
 	utils "github.com/igortoigildin/goph-keeper/pkg/utils"
 )
 
-const sessionFile = "session.json"
+const (
+	sessionFile = "session.json"
+
+	// TokenEnv is environment variable with api token, which is used instead of the session
+	// saved by login, e.g. in CI jobs.
+	TokenEnv = "GOPH_KEEPER_TOKEN"
+	// ClientCertEnv is environment variable with client certificate, the user it is bound to
+	// is authenticated by the server without login.
+	ClientCertEnv = "TLS_CLIENT_CERT_FILE"
+)
 
 type Session struct {
 	Login     string    `json:"email"`
@@ -31,8 +41,18 @@ func IsSessionValid(tokenSectet string) bool {
 	return time.Now().Before(session.ExpiresAt)
 }
 
+// LoadSession returns session saved by login, unless api token is provided in environment.
+// Login of the api token owner is not known to the client and is set by the server. Empty session
+// is returned to the client with certificate, which has not logged in.
 func LoadSession() (*Session, error) {
+	if token := os.Getenv(TokenEnv); token != "" {
+		return &Session{Token: token}, nil
+	}
+
 	file, err := os.Open(sessionFile)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv(ClientCertEnv) != "" {
+		return &Session{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("session file does not exist of could not be opened: %w", err)
 	}
@@ -0,0 +1,272 @@
+// Package srp implements SRP-6a password authenticated key exchange (RFC 5054) with SHA-256.
+//
+// The server keeps only salt and verifier of the password, the password itself never leaves the client.
+// Password is stretched with argon2id before it is used, so leaked verifiers are expensive to brute force.
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// SaltSize is the number of random bytes of password salt.
+const SaltSize = 16
+
+// Parameters of argon2id stretching the password.
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	argonKeyLen  = 32
+)
+
+// keySize is the number of random bytes of private ephemeral keys.
+const keySize = 32
+
+// 2048-bit group of RFC 5054, appendix A.
+const primeHex = "AC6BDB41324A9A9BF166DE5E1389582FAF72B6651987EE07FC3192943DB56050A37329CBB4" +
+	"A099ED8193E0757767A13DD52312AB4B03310DCD7F48A9DA04FD50E8083969EDB767B0CF60" +
+	"95179A163AB3661A05FBD5FAAAE82918A9962F0B93B855F97993EC975EEAA80D740ADBF4FF" +
+	"747359D041D5C33EA71D281E446B14773BCA97B43A23FB801676BD207A436C6481F1D2B907" +
+	"8717461A5B9D32E688F87748544523B524B0D57D5EA77A2775D2ECFA032CFBDBF52FB37861" +
+	"60279004E57AE6AF874E7303CE53299CCC041C7BC308D82A5698F3A8D0C38271AE35F8E9DB" +
+	"FBB694B5C803D89F7AE435DE236D525F54759B65E372FCD68EF20FA7111F9E4AFF73"
+
+var (
+	groupN = mustParseHex(primeHex)
+	groupG = big.NewInt(2)
+	// multiplier k = H(N | PAD(g))
+	groupK = new(big.Int).SetBytes(hash(groupN.Bytes(), pad(groupG)))
+)
+
+var (
+	// ErrInvalidPublicKey is returned when public ephemeral key of the peer is not in range 0 < key < N,
+	// which would let the peer authenticate without knowing the password.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrInvalidVerifier is returned when verifier stored for the user is malformed.
+	ErrInvalidVerifier = errors.New("invalid verifier")
+)
+
+// NewVerifier returns random salt and verifier of the password, which are saved by the server.
+func NewVerifier(login, password string) (salt, verifier []byte, err error) {
+	salt = make([]byte, SaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	return salt, ComputeVerifier(login, password, salt), nil
+}
+
+// ComputeVerifier returns verifier v = g^x of the password with the salt.
+func ComputeVerifier(login, password string, salt []byte) []byte {
+	return pad(new(big.Int).Exp(groupG, privateKey(login, password, salt), groupN))
+}
+
+// CheckPassword reports whether the password matches the verifier computed with the salt.
+// It lets the server check password sent in plaintext by clients, which do not support SRP.
+func CheckPassword(login, password string, salt, verifier []byte) bool {
+	return subtle.ConstantTimeCompare(ComputeVerifier(login, password, salt), verifier) == 1
+}
+
+// VerifierFromKey returns verifier of the private key x derived by other means than password,
+// e.g. made up for unknown users, so that responses to them do not differ from responses to existing ones.
+func VerifierFromKey(x []byte) []byte {
+	return pad(new(big.Int).Exp(groupG, new(big.Int).SetBytes(x), groupN))
+}
+
+// ValidVerifier reports whether the verifier, e.g. sent by the client on registration, is in range 0 < v < N.
+func ValidVerifier(verifier []byte) bool {
+	return validPublicKey(new(big.Int).SetBytes(verifier))
+}
+
+// Client is the side of the exchange, which knows the password.
+type Client struct {
+	login     string
+	password  string
+	a         *big.Int
+	publicKey *big.Int
+	// serverProof is the proof expected from the server, once client proof is computed
+	serverProof []byte
+}
+
+// NewClient returns client with new private ephemeral key.
+func NewClient(login, password string) (*Client, error) {
+	a, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		login:     login,
+		password:  password,
+		a:         a,
+		publicKey: new(big.Int).Exp(groupG, a, groupN),
+	}, nil
+}
+
+// PublicKey returns public ephemeral key A sent to the server.
+func (c *Client) PublicKey() []byte {
+	return pad(c.publicKey)
+}
+
+// Proof returns proof of the password M1 computed from salt and public ephemeral key B of the server.
+func (c *Client) Proof(salt, serverKey []byte) ([]byte, error) {
+	b := new(big.Int).SetBytes(serverKey)
+	if !validPublicKey(b) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	u := scramble(c.publicKey, b)
+	if u.Sign() == 0 {
+		return nil, ErrInvalidPublicKey
+	}
+
+	x := privateKey(c.login, c.password, salt)
+
+	// S = (B - k * g^x) ^ (a + u * x)
+	base := new(big.Int).Exp(groupG, x, groupN)
+	base.Mul(base, groupK)
+	base.Sub(b, base)
+	base.Mod(base, groupN)
+
+	exp := new(big.Int).Mul(u, x)
+	exp.Add(exp, c.a)
+
+	secret := new(big.Int).Exp(base, exp, groupN)
+
+	proof, serverProof := proofs(c.publicKey, b, secret)
+	c.serverProof = serverProof
+
+	return proof, nil
+}
+
+// VerifyServer reports whether the server proved knowledge of the verifier with M2.
+func (c *Client) VerifyServer(serverProof []byte) bool {
+	return c.serverProof != nil && subtle.ConstantTimeCompare(c.serverProof, serverProof) == 1
+}
+
+// Server is the side of the exchange, which knows the verifier.
+type Server struct {
+	verifier  *big.Int
+	b         *big.Int
+	publicKey *big.Int
+}
+
+// NewServer returns server with new private ephemeral key for the verifier.
+func NewServer(verifier []byte) (*Server, error) {
+	if !ValidVerifier(verifier) {
+		return nil, ErrInvalidVerifier
+	}
+
+	v := new(big.Int).SetBytes(verifier)
+
+	b, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+
+	// B = k * v + g^b
+	publicKey := new(big.Int).Mul(groupK, v)
+	publicKey.Add(publicKey, new(big.Int).Exp(groupG, b, groupN))
+	publicKey.Mod(publicKey, groupN)
+
+	return &Server{
+		verifier:  v,
+		b:         b,
+		publicKey: publicKey,
+	}, nil
+}
+
+// PublicKey returns public ephemeral key B sent to the client.
+func (s *Server) PublicKey() []byte {
+	return pad(s.publicKey)
+}
+
+// Proofs returns proof M1 expected from the client with public ephemeral key A
+// and proof M2 of the server returned to the client, once M1 is checked.
+func (s *Server) Proofs(clientKey []byte) (clientProof, serverProof []byte, err error) {
+	a := new(big.Int).SetBytes(clientKey)
+	if !validPublicKey(a) {
+		return nil, nil, ErrInvalidPublicKey
+	}
+
+	u := scramble(a, s.publicKey)
+	if u.Sign() == 0 {
+		return nil, nil, ErrInvalidPublicKey
+	}
+
+	// S = (A * v^u) ^ b
+	base := new(big.Int).Exp(s.verifier, u, groupN)
+	base.Mul(base, a)
+	base.Mod(base, groupN)
+
+	secret := new(big.Int).Exp(base, s.b, groupN)
+
+	clientProof, serverProof = proofs(a, s.publicKey, secret)
+
+	return clientProof, serverProof, nil
+}
+
+// privateKey returns x = H(salt | argon2id(login ":" password, salt)).
+func privateKey(login, password string, salt []byte) *big.Int {
+	stretched := argon2.IDKey([]byte(login+":"+password), salt, argonTime, argonMemory, argonThreads, argonKeyLen)
+
+	return new(big.Int).SetBytes(hash(salt, stretched))
+}
+
+// scramble returns u = H(PAD(A) | PAD(B)).
+func scramble(a, b *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hash(pad(a), pad(b)))
+}
+
+// proofs returns M1 = H(PAD(A) | PAD(B) | K) and M2 = H(PAD(A) | M1 | K), where K = H(S).
+func proofs(a, b, secret *big.Int) (clientProof, serverProof []byte) {
+	key := hash(pad(secret))
+	clientProof = hash(pad(a), pad(b), key)
+	serverProof = hash(pad(a), clientProof, key)
+
+	return clientProof, serverProof
+}
+
+// validPublicKey reports whether the key is in range 0 < key < N, so that it can not force the shared secret.
+func validPublicKey(key *big.Int) bool {
+	return key.Sign() > 0 && key.Cmp(groupN) < 0
+}
+
+func randomKey() (*big.Int, error) {
+	b := make([]byte, keySize)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate ephemeral key: %w", err)
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
+
+func hash(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+
+	return h.Sum(nil)
+}
+
+// pad returns big-endian bytes of n left padded with zeros to the length of N.
+func pad(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, (groupN.BitLen()+7)/8))
+}
+
+func mustParseHex(s string) *big.Int {
+	n, ok := new(big.Int).SetString(s, 16)
+	if !ok {
+		panic("srp: invalid group prime")
+	}
+
+	return n
+}
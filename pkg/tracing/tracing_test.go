@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestInit_PropagatesTraceContext(t *testing.T) {
+	ctx := context.Background()
+
+	shutdown, err := Init(ctx, "test", Config{Exporter: ExporterNone})
+	require.NoError(t, err)
+	defer shutdown(ctx)
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	defer provider.Shutdown(ctx)
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(prev)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer(ServerOption())
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()), ClientOption())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx, span := Tracer("test").Start(ctx, "command")
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	span.End()
+
+	// the span of the server continues the trace of the client
+	var serverSpan sdktrace.ReadOnlySpan
+	for _, s := range recorder.Ended() {
+		if s.SpanKind() == trace.SpanKindServer {
+			serverSpan = s
+		}
+	}
+	require.NotNil(t, serverSpan)
+	assert.Equal(t, span.SpanContext().TraceID(), serverSpan.SpanContext().TraceID())
+	assert.True(t, serverSpan.Parent().IsRemote())
+}
+
+func TestInit_FileExporter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "traces.json")
+
+	prev := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(prev)
+
+	shutdown, err := Init(ctx, "test", Config{Exporter: ExporterFile, File: path, SampleRatio: 1})
+	require.NoError(t, err)
+
+	_, span := Tracer("test").Start(ctx, "exported")
+	span.End()
+
+	// remaining spans are flushed on shutdown
+	require.NoError(t, shutdown(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"exported"`)
+}
+
+func TestInit_UnknownExporter(t *testing.T) {
+	_, err := Init(context.Background(), "test", Config{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,10 +20,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	UploadV1_UploadPassword_FullMethodName = "/upload_v1.UploadV1/UploadPassword"
-	UploadV1_UploadText_FullMethodName     = "/upload_v1.UploadV1/UploadText"
-	UploadV1_UploadFile_FullMethodName     = "/upload_v1.UploadV1/UploadFile"
-	UploadV1_UploadBankData_FullMethodName = "/upload_v1.UploadV1/UploadBankData"
+	UploadV1_UploadPassword_FullMethodName  = "/upload_v1.UploadV1/UploadPassword"
+	UploadV1_UploadText_FullMethodName      = "/upload_v1.UploadV1/UploadText"
+	UploadV1_UploadFile_FullMethodName      = "/upload_v1.UploadV1/UploadFile"
+	UploadV1_UploadBankData_FullMethodName  = "/upload_v1.UploadV1/UploadBankData"
+	UploadV1_InitUpload_FullMethodName      = "/upload_v1.UploadV1/InitUpload"
+	UploadV1_UploadChunk_FullMethodName     = "/upload_v1.UploadV1/UploadChunk"
+	UploadV1_GetUploadStatus_FullMethodName = "/upload_v1.UploadV1/GetUploadStatus"
+	UploadV1_FinalizeUpload_FullMethodName  = "/upload_v1.UploadV1/FinalizeUpload"
+	UploadV1_GetUploadURL_FullMethodName    = "/upload_v1.UploadV1/GetUploadURL"
+	UploadV1_CompleteUpload_FullMethodName  = "/upload_v1.UploadV1/CompleteUpload"
+	UploadV1_DeleteFile_FullMethodName      = "/upload_v1.UploadV1/DeleteFile"
 )
 
 // UploadV1Client is the client API for UploadV1 service.
@@ -33,6 +41,16 @@ type UploadV1Client interface {
 	UploadText(ctx context.Context, in *UploadTextRequest, opts ...grpc.CallOption) (*UploadTextResponse, error)
 	UploadFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadFileRequest, UploadFileResponse], error)
 	UploadBankData(ctx context.Context, in *UploadBankDataRequest, opts ...grpc.CallOption) (*UploadBankDataResponse, error)
+	// Resumable upload session protocol.
+	InitUpload(ctx context.Context, in *InitUploadRequest, opts ...grpc.CallOption) (*InitUploadResponse, error)
+	UploadChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadChunkRequest, UploadChunkResponse], error)
+	GetUploadStatus(ctx context.Context, in *GetUploadStatusRequest, opts ...grpc.CallOption) (*GetUploadStatusResponse, error)
+	FinalizeUpload(ctx context.Context, in *FinalizeUploadRequest, opts ...grpc.CallOption) (*FinalizeUploadResponse, error)
+	// Direct upload to object storage through presigned url.
+	GetUploadURL(ctx context.Context, in *GetUploadURLRequest, opts ...grpc.CallOption) (*GetUploadURLResponse, error)
+	CompleteUpload(ctx context.Context, in *CompleteUploadRequest, opts ...grpc.CallOption) (*CompleteUploadResponse, error)
+	// Deletes the file with id provided in metadata.
+	DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type uploadV1Client struct {
@@ -86,6 +104,79 @@ func (c *uploadV1Client) UploadBankData(ctx context.Context, in *UploadBankDataR
 	return out, nil
 }
 
+func (c *uploadV1Client) InitUpload(ctx context.Context, in *InitUploadRequest, opts ...grpc.CallOption) (*InitUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InitUploadResponse)
+	err := c.cc.Invoke(ctx, UploadV1_InitUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadV1Client) UploadChunk(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadChunkRequest, UploadChunkResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UploadV1_ServiceDesc.Streams[1], UploadV1_UploadChunk_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadChunkRequest, UploadChunkResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UploadV1_UploadChunkClient = grpc.ClientStreamingClient[UploadChunkRequest, UploadChunkResponse]
+
+func (c *uploadV1Client) GetUploadStatus(ctx context.Context, in *GetUploadStatusRequest, opts ...grpc.CallOption) (*GetUploadStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUploadStatusResponse)
+	err := c.cc.Invoke(ctx, UploadV1_GetUploadStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadV1Client) FinalizeUpload(ctx context.Context, in *FinalizeUploadRequest, opts ...grpc.CallOption) (*FinalizeUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FinalizeUploadResponse)
+	err := c.cc.Invoke(ctx, UploadV1_FinalizeUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadV1Client) GetUploadURL(ctx context.Context, in *GetUploadURLRequest, opts ...grpc.CallOption) (*GetUploadURLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUploadURLResponse)
+	err := c.cc.Invoke(ctx, UploadV1_GetUploadURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadV1Client) CompleteUpload(ctx context.Context, in *CompleteUploadRequest, opts ...grpc.CallOption) (*CompleteUploadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompleteUploadResponse)
+	err := c.cc.Invoke(ctx, UploadV1_CompleteUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uploadV1Client) DeleteFile(ctx context.Context, in *DeleteFileRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, UploadV1_DeleteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UploadV1Server is the server API for UploadV1 service.
 // All implementations must embed UnimplementedUploadV1Server
 // for forward compatibility.
@@ -94,6 +185,16 @@ type UploadV1Server interface {
 	UploadText(context.Context, *UploadTextRequest) (*UploadTextResponse, error)
 	UploadFile(grpc.ClientStreamingServer[UploadFileRequest, UploadFileResponse]) error
 	UploadBankData(context.Context, *UploadBankDataRequest) (*UploadBankDataResponse, error)
+	// Resumable upload session protocol.
+	InitUpload(context.Context, *InitUploadRequest) (*InitUploadResponse, error)
+	UploadChunk(grpc.ClientStreamingServer[UploadChunkRequest, UploadChunkResponse]) error
+	GetUploadStatus(context.Context, *GetUploadStatusRequest) (*GetUploadStatusResponse, error)
+	FinalizeUpload(context.Context, *FinalizeUploadRequest) (*FinalizeUploadResponse, error)
+	// Direct upload to object storage through presigned url.
+	GetUploadURL(context.Context, *GetUploadURLRequest) (*GetUploadURLResponse, error)
+	CompleteUpload(context.Context, *CompleteUploadRequest) (*CompleteUploadResponse, error)
+	// Deletes the file with id provided in metadata.
+	DeleteFile(context.Context, *DeleteFileRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUploadV1Server()
 }
 
@@ -116,6 +217,27 @@ func (UnimplementedUploadV1Server) UploadFile(grpc.ClientStreamingServer[UploadF
 func (UnimplementedUploadV1Server) UploadBankData(context.Context, *UploadBankDataRequest) (*UploadBankDataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UploadBankData not implemented")
 }
+func (UnimplementedUploadV1Server) InitUpload(context.Context, *InitUploadRequest) (*InitUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitUpload not implemented")
+}
+func (UnimplementedUploadV1Server) UploadChunk(grpc.ClientStreamingServer[UploadChunkRequest, UploadChunkResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadChunk not implemented")
+}
+func (UnimplementedUploadV1Server) GetUploadStatus(context.Context, *GetUploadStatusRequest) (*GetUploadStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadStatus not implemented")
+}
+func (UnimplementedUploadV1Server) FinalizeUpload(context.Context, *FinalizeUploadRequest) (*FinalizeUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FinalizeUpload not implemented")
+}
+func (UnimplementedUploadV1Server) GetUploadURL(context.Context, *GetUploadURLRequest) (*GetUploadURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUploadURL not implemented")
+}
+func (UnimplementedUploadV1Server) CompleteUpload(context.Context, *CompleteUploadRequest) (*CompleteUploadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteUpload not implemented")
+}
+func (UnimplementedUploadV1Server) DeleteFile(context.Context, *DeleteFileRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFile not implemented")
+}
 func (UnimplementedUploadV1Server) mustEmbedUnimplementedUploadV1Server() {}
 func (UnimplementedUploadV1Server) testEmbeddedByValue()                  {}
 
@@ -198,6 +320,121 @@ func _UploadV1_UploadBankData_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UploadV1_InitUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InitUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).InitUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_InitUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).InitUpload(ctx, req.(*InitUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadV1_UploadChunk_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UploadV1Server).UploadChunk(&grpc.GenericServerStream[UploadChunkRequest, UploadChunkResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type UploadV1_UploadChunkServer = grpc.ClientStreamingServer[UploadChunkRequest, UploadChunkResponse]
+
+func _UploadV1_GetUploadStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).GetUploadStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_GetUploadStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).GetUploadStatus(ctx, req.(*GetUploadStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadV1_FinalizeUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FinalizeUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).FinalizeUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_FinalizeUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).FinalizeUpload(ctx, req.(*FinalizeUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadV1_GetUploadURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUploadURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).GetUploadURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_GetUploadURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).GetUploadURL(ctx, req.(*GetUploadURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadV1_CompleteUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).CompleteUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_CompleteUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).CompleteUpload(ctx, req.(*CompleteUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UploadV1_DeleteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UploadV1Server).DeleteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UploadV1_DeleteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UploadV1Server).DeleteFile(ctx, req.(*DeleteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UploadV1_ServiceDesc is the grpc.ServiceDesc for UploadV1 service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -217,6 +454,30 @@ var UploadV1_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UploadBankData",
 			Handler:    _UploadV1_UploadBankData_Handler,
 		},
+		{
+			MethodName: "InitUpload",
+			Handler:    _UploadV1_InitUpload_Handler,
+		},
+		{
+			MethodName: "GetUploadStatus",
+			Handler:    _UploadV1_GetUploadStatus_Handler,
+		},
+		{
+			MethodName: "FinalizeUpload",
+			Handler:    _UploadV1_FinalizeUpload_Handler,
+		},
+		{
+			MethodName: "GetUploadURL",
+			Handler:    _UploadV1_GetUploadURL_Handler,
+		},
+		{
+			MethodName: "CompleteUpload",
+			Handler:    _UploadV1_CompleteUpload_Handler,
+		},
+		{
+			MethodName: "DeleteFile",
+			Handler:    _UploadV1_DeleteFile_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -224,6 +485,11 @@ var UploadV1_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _UploadV1_UploadFile_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "UploadChunk",
+			Handler:       _UploadV1_UploadChunk_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "upload.proto",
 }
@@ -18,6 +18,7 @@ func GenerateToken(info model.UserInfo, secretKey []byte, duration time.Duration
 
 	claims := model.UserClaims{
 		StandardClaims: jwt.StandardClaims{
+			IssuedAt:  time.Now().Unix(),
 			ExpiresAt: time.Now().Add(duration).Unix(),
 		},
 		Login: info.Login,
@@ -1,6 +1,17 @@
 package jwt
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// randomTokenSize is the number of random bytes of password reset and API tokens.
+const randomTokenSize = 32
 
 func VerifyPassword(hashedPassword string, candidatePassword string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(candidatePassword))
@@ -11,3 +22,20 @@ func HashPassword(password string) (string, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(hash), err
 }
+
+// NewRandomToken returns random token, e.g. one-time token, which lets the user set new password.
+func NewRandomToken() (string, error) {
+	b := make([]byte, randomTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns hash of random token, under which it is stored. Random tokens do not need a slow hash.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
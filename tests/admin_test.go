@@ -0,0 +1,157 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/client/db/pg"
+	models "github.com/igortoigildin/goph-keeper/internal/server/models"
+	adminService "github.com/igortoigildin/goph-keeper/internal/server/service/admin"
+	dataRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/minio"
+	certRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/cert"
+	userRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/user"
+	"github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdmin_ManagesUsers(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	adminLogin, adminPass := gofakeit.Email(), randomFakePassword()
+	createAdmin(t, ctx, st, adminLogin, adminPass)
+	adminCtx := loginContext(t, ctx, st, adminLogin, adminPass)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+	userCtx := loginContext(t, ctx, st, login, pass)
+
+	users, err := st.AdminClient.ListUsers(adminCtx, &admin_v1.ListUsersRequest{})
+	require.NoError(t, err)
+	roles := map[string]string{}
+	for _, u := range users.GetUsers() {
+		roles[u.GetLogin()] = u.GetRole()
+	}
+	assert.Equal(t, models.RoleAdmin, roles[adminLogin])
+	assert.Equal(t, models.RoleUser, roles[login])
+
+	// administrators query events of any user
+	_, err = st.AuditClient.Query(adminCtx, &audit_v1.QueryRequest{Actor: login})
+	require.NoError(t, err)
+
+	// access tokens issued before logout are rejected
+	_, err = st.AdminClient.LogoutUser(adminCtx, &admin_v1.UserRequest{Login: login})
+	require.NoError(t, err)
+	_, err = st.AccountClient.GetUsage(userCtx, &account_v1.GetUsageRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AdminClient.DisableUser(adminCtx, &admin_v1.UserRequest{Login: login})
+	require.NoError(t, err)
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.EnableUser(adminCtx, &admin_v1.UserRequest{Login: login})
+	require.NoError(t, err)
+
+	// the reset token is valid once and revokes sessions with the old password
+	reset, err := st.AdminClient.IssueResetToken(adminCtx, &admin_v1.UserRequest{Login: login})
+	require.NoError(t, err)
+	assert.True(t, reset.GetExpiresAt().AsTime().After(time.Now()))
+
+	newPass := randomFakePassword()
+	req := &auth_v1.ResetPasswordRequest{Login: login, ResetToken: reset.GetResetToken(), NewPassword: newPass}
+	_, err = st.AuthClient.ResetPassword(ctx, req)
+	require.NoError(t, err)
+	_, err = st.AuthClient.ResetPassword(ctx, req)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.Error(t, err)
+
+	// tokens are issued with precision of a second, so the one issued within the second of reset is revoked too
+	var token string
+	require.Eventually(t, func() bool {
+		resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: newPass})
+		require.NoError(t, err)
+		token = resp.GetToken()
+
+		_, err = st.AccountClient.GetUsage(bearerContext(login, token), &account_v1.GetUsageRequest{})
+
+		return err == nil
+	}, 5*time.Second, 500*time.Millisecond)
+
+	uploadCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+token))
+	_, err = st.UploadClient.UploadText(uploadCtx, &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, getUsage(t, st, login, token).GetObjects())
+
+	_, err = st.AdminClient.DeleteUserData(adminCtx, &admin_v1.UserRequest{Login: login})
+	require.NoError(t, err)
+	assert.Zero(t, getUsage(t, st, login, token).GetObjects())
+}
+
+func TestAdmin_RequiresAdminRole(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+	userCtx := loginContext(t, ctx, st, login, pass)
+
+	_, err = st.AdminClient.ListUsers(userCtx, &admin_v1.ListUsersRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.IssueResetToken(userCtx, &admin_v1.UserRequest{Login: login})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AdminClient.ListUsers(ctx, &admin_v1.ListUsersRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.ResetPassword(ctx, &auth_v1.ResetPasswordRequest{
+		Login: login, ResetToken: gofakeit.LetterN(43), NewPassword: randomFakePassword(),
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+// createAdmin creates the administrator directly in the database, as the admin command of the server does.
+func createAdmin(t *testing.T, ctx context.Context, st *suite.Suite, login, pass string) {
+	t.Helper()
+
+	logger.Initialize("error")
+
+	dbClient, err := pg.New(ctx, st.Cfg.PG.DSN)
+	require.NoError(t, err)
+	defer dbClient.Close()
+
+	svc := adminService.New(userRepository.NewRepository(dbClient), dataRepository.NewRepository(st.Cfg.Minio),
+		certRepository.NewRepository(dbClient), st.Cfg.Auth.ResetTokenTTL)
+	require.NoError(t, svc.CreateUser(ctx, login, pass, models.RoleAdmin))
+}
+
+func loginContext(t *testing.T, ctx context.Context, st *suite.Suite, login, pass string) context.Context {
+	t.Helper()
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	return bearerContext(login, resp.GetToken())
+}
+
+func bearerContext(login, token string) context.Context {
+	return metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "authorization", "Bearer "+token))
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/audit_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestAudit_RecordsAccesses(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: randomFakePassword()})
+	require.Error(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	authCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+resp.GetToken()))
+
+	_, err = st.UploadClient.UploadText(authCtx, &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+	require.NoError(t, err)
+
+	_, err = st.DownloadClient.DownloadText(authCtx, &download_v1.DownloadTextRequest{Uuid: id})
+	require.NoError(t, err)
+
+	// events are saved asynchronously
+	var events []*audit_v1.Event
+	require.Eventually(t, func() bool {
+		res, err := st.AuditClient.Query(authCtx, &audit_v1.QueryRequest{ObjectId: id})
+		require.NoError(t, err)
+		events = res.GetEvents()
+
+		return len(events) == 2
+	}, 10*time.Second, 200*time.Millisecond)
+
+	// newest first
+	assert.Equal(t, "download", events[0].GetAction())
+	assert.Equal(t, "upload", events[1].GetAction())
+	for _, e := range events {
+		assert.Equal(t, login, e.GetActor())
+		assert.Equal(t, "OK", e.GetResult())
+		assert.NotEmpty(t, e.GetRequestId())
+		assert.NotEmpty(t, e.GetPeer())
+	}
+
+	res, err := st.AuditClient.Query(authCtx, &audit_v1.QueryRequest{Action: "login", PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, res.GetEvents(), 1)
+	assert.Equal(t, "OK", res.GetEvents()[0].GetResult())
+	require.NotEmpty(t, res.GetNextPageToken())
+
+	res, err = st.AuditClient.Query(authCtx, &audit_v1.QueryRequest{Action: "login", PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, res.GetEvents(), 1)
+	assert.Equal(t, codes.Unauthenticated.String(), res.GetEvents()[0].GetResult())
+	assert.Empty(t, res.GetNextPageToken())
+
+	// events of other users are not available
+	_, err = st.AuditClient.Query(authCtx, &audit_v1.QueryRequest{Actor: gofakeit.Email()})
+	require.Error(t, err)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
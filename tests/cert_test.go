@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/igortoigildin/goph-keeper/internal/client/db/pg"
+	adminService "github.com/igortoigildin/goph-keeper/internal/server/service/admin"
+	dataRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/minio"
+	certRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/cert"
+	userRepository "github.com/igortoigildin/goph-keeper/internal/server/storage/pg/user"
+	"github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/logger"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+func TestCert_AuthenticatesBoundUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	if st.Cfg.GRPC.TLS.ClientCAFile == "" || st.Cfg.GRPC.TLS.RequireClientCert {
+		t.Skip("client certificates are not enabled or are required together with token")
+	}
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	otherLogin, otherPass := gofakeit.Email(), randomFakePassword()
+	_, err = st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: otherLogin, Password: otherPass})
+	require.NoError(t, err)
+	otherCtx := loginContext(t, ctx, st, otherLogin, otherPass)
+
+	account := account_v1.NewAccountV1Client(certConn(t, st, login))
+
+	// certificate is not bound yet
+	_, err = account.GetUsage(ctx, &account_v1.GetUsageRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	admin := certAdminService(t, ctx, st)
+	require.NoError(t, admin.BindCert(ctx, login, "email:"+login))
+
+	_, err = account.GetUsage(ctx, &account_v1.GetUsageRequest{})
+	require.NoError(t, err)
+
+	// token of another user is rejected
+	_, err = account.GetUsage(otherCtx, &account_v1.GetUsageRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	require.NoError(t, admin.UnbindCert(ctx, "email:"+login))
+
+	_, err = account.GetUsage(ctx, &account_v1.GetUsageRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// certConn returns connection to the server presenting client certificate for email signed by the client CA
+// of the server. Key of the CA is expected next to its certificate, as generated by make certs.
+func certConn(t *testing.T, st *suite.Suite, email string) *grpc.ClientConn {
+	t.Helper()
+
+	caFile := filepath.Join("..", st.Cfg.GRPC.TLS.ClientCAFile)
+	ca, err := tls.LoadX509KeyPair(caFile, strings.TrimSuffix(caFile, ".crt")+".key")
+	if errors.Is(err, os.ErrNotExist) {
+		t.Skip("key of the client CA is not available")
+	}
+	require.NoError(t, err)
+
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(time.Now().UnixNano()),
+		Subject:        pkix.Name{CommonName: email},
+		EmailAddresses: []string{email},
+		NotBefore:      time.Now().Add(-time.Minute),
+		NotAfter:       time.Now().Add(time.Hour),
+		KeyUsage:       x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:    []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, ca.PrivateKey)
+	require.NoError(t, err)
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates:       []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		InsecureSkipVerify: true, //nolint:gosec // self-signed test certificate
+	})
+
+	cc, err := grpc.NewClient(st.Cfg.GRPC.Address(), grpc.WithTransportCredentials(creds))
+	require.NoError(t, err)
+	t.Cleanup(func() { cc.Close() })
+
+	return cc
+}
+
+// certAdminService returns admin service binding certificates directly in the database,
+// as the admin command of the server does.
+func certAdminService(t *testing.T, ctx context.Context, st *suite.Suite) *adminService.AdminService {
+	t.Helper()
+
+	logger.Initialize("error")
+
+	dbClient, err := pg.New(ctx, st.Cfg.PG.DSN)
+	require.NoError(t, err)
+	t.Cleanup(func() { dbClient.Close() })
+
+	return adminService.New(userRepository.NewRepository(dbClient), dataRepository.NewRepository(st.Cfg.Minio),
+		certRepository.NewRepository(dbClient), st.Cfg.Auth.ResetTokenTTL)
+}
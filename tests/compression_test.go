@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/compression"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestCompressedText_Happy(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	md := metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	text := strings.Repeat(gofakeit.Sentence(10), 100)
+	payload, algo, err := compression.Compress([]byte(text))
+	require.NoError(t, err)
+	require.Equal(t, compression.Zstd, algo)
+
+	_, err = st.UploadClient.UploadText(ctx, &upload_v1.UploadTextRequest{
+		Text:        string(payload),
+		Compression: algo,
+	})
+	require.NoError(t, err)
+
+	downResp, err := st.DownloadClient.DownloadText(ctx, &download_v1.DownloadTextRequest{
+		Uuid: id,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, compression.Zstd, downResp.GetCompression())
+}
+
+func TestCompressedText_Unsupported(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = st.UploadClient.UploadText(ctx, &upload_v1.UploadTextRequest{
+		Text:        gofakeit.Sentence(10),
+		Compression: "lz4",
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/sync_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestDedup_SharedContent(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	data := []byte(gofakeit.LoremIpsumParagraph(20, 10, 20, "\n"))
+	sum := sha256.Sum256(data)
+
+	firstID, secondID := uuid.NewString(), uuid.NewString()
+	first := uploadFile(t, st, login, resp.GetToken(), firstID, "first", data)
+	second := uploadFile(t, st, login, resp.GetToken(), secondID, "second", data)
+
+	// both files are stored in the same blob
+	assert.Equal(t, first.GetEtag(), second.GetEtag())
+	assert.Equal(t, hex.EncodeToString(sum[:]), second.GetSha256())
+
+	firstCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", firstID, "authorization", "Bearer "+resp.GetToken()))
+	_, err = st.UploadClient.DeleteFile(firstCtx, &upload_v1.DeleteFileRequest{})
+	require.NoError(t, err)
+
+	// content is kept while referenced by the second file, metadata is kept per file
+	body, info := downloadFile(t, st, login, resp.GetToken(), secondID)
+	assert.Equal(t, data, body)
+	assert.Equal(t, "second", info)
+
+	secondCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", secondID, "authorization", "Bearer "+resp.GetToken()))
+	_, err = st.UploadClient.DeleteFile(secondCtx, &upload_v1.DeleteFileRequest{})
+	require.NoError(t, err)
+
+	_, err = st.UploadClient.DeleteFile(secondCtx, &upload_v1.DeleteFileRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestDedup_ListedSize(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	data := []byte(gofakeit.LoremIpsumParagraph(5, 5, 10, "\n"))
+	uploadFile(t, st, login, resp.GetToken(), id, "sized", data)
+
+	md := metadata.Pairs("login", login, "authorization", "Bearer "+resp.GetToken())
+	list, err := st.SyncClient.GetObjectList(metadata.NewOutgoingContext(context.Background(), md), &sync_v1.SyncRequest{Login: login})
+	require.NoError(t, err)
+
+	var found bool
+	for _, obj := range list.GetObjects() {
+		if obj.GetKey() == "bin_data_"+id {
+			found = true
+			assert.Equal(t, int64(len(data)), obj.GetSize())
+		}
+	}
+	assert.True(t, found, "uploaded file is not listed")
+}
+
+func TestDedup_FileOfAnotherUserIsKept(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	owner, ownerPass := gofakeit.Email(), randomFakePassword()
+	other, otherPass := gofakeit.Email(), randomFakePassword()
+	for login, pass := range map[string]string{owner: ownerPass, other: otherPass} {
+		_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+		require.NoError(t, err)
+	}
+
+	ownerResp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: owner, Password: ownerPass})
+	require.NoError(t, err)
+	otherResp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: other, Password: otherPass})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	data := []byte(gofakeit.LoremIpsumParagraph(2, 5, 10, "\n"))
+	uploadFile(t, st, owner, ownerResp.GetToken(), id, "owner", data)
+
+	md := metadata.Pairs("login", other, "id", id, "authorization", "Bearer "+otherResp.GetToken())
+	stream, err := st.UploadClient.UploadFile(metadata.NewOutgoingContext(context.Background(), md))
+	require.NoError(t, err)
+	// the server may reject the upload before the message is received, the error is returned on close
+	_ = stream.Send(&upload_v1.UploadFileRequest{FileName: "other.txt", Chunk: []byte("other content")})
+
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	body, info := downloadFile(t, st, owner, ownerResp.GetToken(), id)
+	assert.Equal(t, data, body)
+	assert.Equal(t, "owner", info)
+}
+
+func uploadFile(t *testing.T, st *suite.Suite, login, token, id, info string, data []byte) *upload_v1.UploadFileResponse {
+	t.Helper()
+
+	md := metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	sum := sha256.Sum256(data)
+
+	stream, err := st.UploadClient.UploadFile(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadFileRequest{
+		FileName: "lorem.txt",
+		Chunk:    data,
+		Metadata: info,
+		Sha256:   hex.EncodeToString(sum[:]),
+	}))
+
+	res, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	return res
+}
+
+func downloadFile(t *testing.T, st *suite.Suite, login, token, id string) ([]byte, string) {
+	t.Helper()
+
+	md := metadata.Pairs("login", login, "authorization", "Bearer "+token)
+	ctx := metadata.NewOutgoingContext(context.Background(), md)
+
+	stream, err := st.DownloadClient.DownloadFile(ctx, &download_v1.DownloadFileRequest{Uuid: id})
+	require.NoError(t, err)
+
+	var (
+		body []byte
+		info string
+	)
+	for {
+		res, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		if res.GetMetadata() != "" {
+			info = res.GetMetadata()
+		}
+		body = append(body, res.GetChunk()...)
+	}
+
+	return body, info
+}
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/internal/server/api/apierror"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrors_StatusCodesAndDetails(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.Error(t, err)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, "USER_EXISTS", errorReason(t, err))
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	authCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "authorization", "Bearer "+resp.GetToken()))
+
+	// missing object is reported as NotFound
+	_, err = st.DownloadClient.DownloadText(authCtx, &download_v1.DownloadTextRequest{Uuid: uuid.NewString()})
+	require.Error(t, err)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, "ACCESS_NOT_FOUND", errorReason(t, err))
+
+	// request without token is rejected as unauthenticated
+	_, err = st.DownloadClient.DownloadText(ctx, &download_v1.DownloadTextRequest{Uuid: uuid.NewString()})
+	require.Error(t, err)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	// range past the end of the file is reported as OutOfRange
+	id, data := uuid.NewString(), []byte(gofakeit.Sentence(10))
+	uploadFile(t, st, login, resp.GetToken(), id, "", data)
+
+	stream, err := st.DownloadClient.DownloadFile(authCtx, &download_v1.DownloadFileRequest{Uuid: id, Offset: uint64(len(data) + 1)})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Error(t, err)
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assert.Equal(t, "INVALID_RANGE", errorReason(t, err))
+}
+
+func errorReason(t *testing.T, err error) string {
+	t.Helper()
+
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			assert.Equal(t, apierror.Domain, info.GetDomain())
+
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
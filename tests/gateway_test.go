@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway_TextAndFile(t *testing.T) {
+	_, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	credentials, err := json.Marshal(map[string]string{"login": login, "password": pass})
+	require.NoError(t, err)
+
+	resp := gatewayCall(t, st, http.MethodPost, "/v1/auth/register", "", bytes.NewReader(credentials))
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayCall(t, st, http.MethodPost, "/v1/auth/login", "", bytes.NewReader(credentials))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var token struct {
+		Token string `json:"token"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&token))
+
+	// requests without token are rejected
+	resp = gatewayCall(t, st, http.MethodGet, "/v1/objects", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	textID := uuid.NewString()
+	text := gofakeit.Sentence(5)
+	body, err := json.Marshal(map[string]string{"text": text, "metadata": "note"})
+	require.NoError(t, err)
+
+	resp = gatewayCall(t, st, http.MethodPut, "/v1/texts/"+textID, token.Token, bytes.NewReader(body))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayCall(t, st, http.MethodGet, "/v1/texts/"+textID, token.Token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var downloaded struct {
+		Text     string `json:"text"`
+		Metadata string `json:"metadata"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&downloaded))
+	assert.Contains(t, downloaded.Text, text)
+	assert.Equal(t, "note", downloaded.Metadata)
+
+	fileID := uuid.NewString()
+	data := []byte(gofakeit.LoremIpsumParagraph(10, 10, 20, "\n"))
+
+	resp = gatewayCall(t, st, http.MethodPut, "/v1/files/"+fileID+"?file_name=notes.txt", token.Token, bytes.NewReader(data))
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayCall(t, st, http.MethodGet, "/v1/files/"+fileID, token.Token, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, data, content)
+	assert.NotEmpty(t, resp.Header.Get("X-File-Sha256"))
+
+	resp = gatewayCall(t, st, http.MethodDelete, "/v1/files/"+fileID, token.Token, nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayCall(t, st, http.MethodGet, "/v1/files/"+fileID, token.Token, nil)
+	assert.NotEqual(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGateway_OpenAPI(t *testing.T) {
+	_, st := suite.New(t)
+
+	resp := gatewayCall(t, st, http.MethodGet, "/openapi.json", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var spec struct {
+		OpenAPI string                    `json:"openapi"`
+		Paths   map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&spec))
+	assert.Equal(t, "3.0.3", spec.OpenAPI)
+	assert.Contains(t, spec.Paths["/v1/files/{id}"], "put")
+}
+
+func gatewayCall(t *testing.T, st *suite.Suite, method, path, token string, body io.Reader) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, st.GatewayURL+path, body)
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := st.HTTPClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealth_Serving(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	// health service is called without token
+	res, err := st.HealthClient.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+	res, err = st.HealthClient.Check(ctx, &healthpb.HealthCheckRequest{Service: upload_v1.UploadV1_ServiceDesc.ServiceName})
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, res.GetStatus())
+
+	resp := gatewayCall(t, st, http.MethodGet, "/healthz", "", nil)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp = gatewayCall(t, st, http.MethodGet, "/readyz", "", nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var ready struct {
+		Ready  bool              `json:"ready"`
+		Checks map[string]string `json:"checks"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&ready))
+	assert.True(t, ready.Ready)
+	assert.Equal(t, "ok", ready.Checks["postgres"])
+	assert.Equal(t, "ok", ready.Checks["minio"])
+}
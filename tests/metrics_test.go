@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics_Exposed(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    gofakeit.Email(),
+		Password: randomFakePassword(),
+	})
+	require.NoError(t, err)
+
+	resp, err := http.Get("http://localhost" + st.Cfg.Metrics.Address + st.Cfg.Metrics.Path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(body), `gophkeeper_grpc_handled_total{code="OK",method="/auth_v1.AuthV1/Register"}`)
+	assert.Contains(t, string(body), `gophkeeper_postgres_queries_total{query="user_repository.SaveUser",result="ok"}`)
+}
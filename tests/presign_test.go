@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+	"time"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestPresignedURL_Happy(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	md := metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	data := []byte(gofakeit.LoremIpsumParagraph(20, 10, 20, "\n"))
+	sum := sha256.Sum256(data)
+
+	urlResp, err := st.UploadClient.GetUploadURL(ctx, &upload_v1.GetUploadURLRequest{})
+	require.NoError(t, err)
+	assert.True(t, urlResp.GetExpiresAt().AsTime().After(time.Now()))
+
+	postResp := postFile(t, ctx, urlResp, data)
+	postResp.Body.Close()
+	require.Equal(t, http.StatusNoContent, postResp.StatusCode)
+
+	compResp, err := st.UploadClient.CompleteUpload(ctx, &upload_v1.CompleteUploadRequest{
+		FileName: "lorem.txt",
+		Metadata: "info",
+		Sha256:   hex.EncodeToString(sum[:]),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(data)), compResp.GetSize())
+	assert.NotEmpty(t, compResp.GetEtag())
+
+	downResp, err := st.DownloadClient.GetDownloadURL(ctx, &download_v1.GetDownloadURLRequest{Uuid: id})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(data)), downResp.GetSize())
+	assert.Equal(t, "info", downResp.GetMetadata())
+	assert.Equal(t, hex.EncodeToString(sum[:]), downResp.GetSha256())
+
+	// the second half of the file is requested, as a resumed download does
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downResp.GetUrl(), nil)
+	require.NoError(t, err)
+	req.Header.Set("Range", "bytes=10-")
+	getResp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer getResp.Body.Close()
+	require.Equal(t, http.StatusPartialContent, getResp.StatusCode)
+
+	body, err := io.ReadAll(getResp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, data[10:], body)
+}
+
+func TestPresignedURL_CompleteNotUploaded(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	_, err = st.UploadClient.GetUploadURL(ctx, &upload_v1.GetUploadURLRequest{})
+	require.NoError(t, err)
+
+	_, err = st.UploadClient.CompleteUpload(ctx, &upload_v1.CompleteUploadRequest{FileName: "missing.txt"})
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// postFile sends data as the file field of the form of the presigned POST policy.
+func postFile(t *testing.T, ctx context.Context, urlResp *upload_v1.GetUploadURLResponse, data []byte) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for k, v := range urlResp.GetFormData() {
+		require.NoError(t, form.WriteField(k, v))
+	}
+	w, err := form.CreateFormFile("file", "file")
+	require.NoError(t, err)
+	_, err = w.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlResp.GetUrl(), &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+
+	return resp
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestQuota_Enforced(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	quota := st.Cfg.Quota
+	require.NotZero(t, quota.MaxObjectSize)
+	require.Equal(t, 2*quota.MaxObjectSize, quota.MaxBytes, "test config must fit exactly two largest files")
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+	token := resp.GetToken()
+
+	usage := getUsage(t, st, login, token)
+	assert.Zero(t, usage.GetBytes())
+	assert.Zero(t, usage.GetObjects())
+	assert.Equal(t, uint64(quota.MaxBytes), usage.GetMaxBytes())
+	assert.Equal(t, uint64(quota.MaxObjectSize), usage.GetMaxObjectSize())
+
+	// file of unknown size is aborted while streaming
+	err = streamFile(t, st, login, token, uuid.NewString(), quota.MaxObjectSize+1)
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "OBJECT_TOO_LARGE", errorReason(t, err))
+
+	// declared size is checked before the upload starts
+	sessionCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+token))
+	_, err = st.UploadClient.InitUpload(sessionCtx, &upload_v1.InitUploadRequest{
+		FileName: "large.bin",
+		Size:     uint64(quota.MaxObjectSize + 1),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "OBJECT_TOO_LARGE", errorReason(t, err))
+
+	firstID, secondID := uuid.NewString(), uuid.NewString()
+	require.NoError(t, streamFile(t, st, login, token, firstID, quota.MaxObjectSize))
+	require.NoError(t, streamFile(t, st, login, token, secondID, quota.MaxObjectSize))
+
+	usage = getUsage(t, st, login, token)
+	assert.Equal(t, uint64(quota.MaxBytes), usage.GetBytes())
+	assert.Equal(t, uint64(2), usage.GetObjects())
+
+	textCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+token))
+	_, err = st.UploadClient.UploadText(textCtx, &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+	require.Error(t, err)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, "STORAGE_QUOTA_EXCEEDED", errorReason(t, err))
+
+	// file saved again is charged by its new size
+	require.NoError(t, streamFile(t, st, login, token, firstID, 1024))
+
+	secondCtx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", secondID, "authorization", "Bearer "+token))
+	_, err = st.UploadClient.DeleteFile(secondCtx, &upload_v1.DeleteFileRequest{})
+	require.NoError(t, err)
+
+	usage = getUsage(t, st, login, token)
+	assert.Equal(t, uint64(1024), usage.GetBytes())
+	assert.Equal(t, uint64(1), usage.GetObjects())
+
+	_, err = st.UploadClient.UploadText(textCtx, &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+	require.NoError(t, err)
+
+	usage = getUsage(t, st, login, token)
+	assert.Equal(t, uint64(2), usage.GetObjects())
+}
+
+func getUsage(t *testing.T, st *suite.Suite, login, token string) *account_v1.GetUsageResponse {
+	t.Helper()
+
+	ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "authorization", "Bearer "+token))
+
+	res, err := st.AccountClient.GetUsage(ctx, &account_v1.GetUsageRequest{})
+	require.NoError(t, err)
+
+	return res
+}
+
+// streamFile uploads random file of size in chunks, which fit grpc message size limit.
+func streamFile(t *testing.T, st *suite.Suite, login, token, id string, size int64) error {
+	t.Helper()
+
+	const chunkSize = 1024 * 1024
+
+	ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+token))
+
+	stream, err := st.UploadClient.UploadFile(ctx)
+	require.NoError(t, err)
+
+	chunk := make([]byte, chunkSize)
+	for sent := int64(0); sent < size; sent += chunkSize {
+		n := min(chunkSize, size-sent)
+		_, _ = rand.Read(chunk[:n])
+
+		// the stream is closed by the server once the upload is rejected, the error is returned by CloseAndRecv
+		if err := stream.Send(&upload_v1.UploadFileRequest{FileName: "random.bin", Chunk: chunk[:n]}); err != nil {
+			break
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+
+	return err
+}
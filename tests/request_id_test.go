@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v7"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/interceptors"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestID_Propagated(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	req := &auth_v1.RegisterRequest{Login: gofakeit.Email(), Password: randomFakePassword()}
+
+	var header metadata.MD
+	_, err := st.AuthClient.Register(metadata.AppendToOutgoingContext(ctx, interceptors.RequestIDKey, "test-request-id"),
+		req, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test-request-id"}, header.Get(interceptors.RequestIDKey))
+
+	// id is assigned by the server when the caller does not pass one
+	header = nil
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: req.GetLogin(), Password: req.GetPassword()},
+		grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, header.Get(interceptors.RequestIDKey), 1)
+	assert.NotEmpty(t, header.Get(interceptors.RequestIDKey)[0])
+}
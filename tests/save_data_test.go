@@ -2,17 +2,19 @@ package tests
 
 import (
 	"context"
-	"strconv"
 	"testing"
 
 	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
 	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
 	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
 	"github.com/igortoigildin/goph-keeper/tests/suite"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func TestSaveText_Happy(t *testing.T) {
@@ -36,7 +38,7 @@ func TestSaveText_Happy(t *testing.T) {
 	token := resp.GetToken()
 
 	text := gofakeit.Adverb()
-	id := gofakeit.Digit()
+	id := uuid.NewString()
 
 	md := metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+token)
 
@@ -69,8 +71,7 @@ func TestDownloadText_Happy(t *testing.T) {
 	token := resp.GetToken()
 
 	text := gofakeit.Adverb()
-	id := gofakeit.Number(23, 1948)
-	idUpd := strconv.Itoa(id)
+	idUpd := uuid.NewString()
 
 	md := metadata.Pairs("login", login, "id", idUpd, "authorization", "Bearer "+token)
 
@@ -92,6 +93,48 @@ func TestDownloadText_Happy(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestDownloadFolder_Happy(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	manifest := `{"name":"photos","files":[]}`
+	folderID, fileID := uuid.NewString(), uuid.NewString()
+
+	ctx = metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", folderID, "authorization", "Bearer "+resp.GetToken()))
+
+	_, err = st.UploadClient.UploadText(ctx, &upload_v1.UploadTextRequest{Text: manifest, DataType: "folder_data"})
+	require.NoError(t, err)
+
+	res, err := st.DownloadClient.DownloadText(ctx, &download_v1.DownloadTextRequest{Uuid: folderID, DataType: "folder_data"})
+	require.NoError(t, err)
+	assert.Equal(t, manifest, res.GetText())
+
+	// folder is not a text
+	_, err = st.DownloadClient.DownloadText(ctx, &download_v1.DownloadTextRequest{Uuid: folderID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// file with the same content is not a folder
+	ctx = metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", fileID, "authorization", "Bearer "+resp.GetToken()))
+
+	stream, err := st.UploadClient.UploadFile(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadFileRequest{FileName: "manifest.json", Chunk: []byte(manifest)}))
+	_, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	_, err = st.DownloadClient.DownloadText(ctx, &download_v1.DownloadTextRequest{Uuid: fileID, DataType: "folder_data"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
 func TestSaveText_Empty_Login(t *testing.T) {
 	ctx, st := suite.New(t)
 	login := gofakeit.Email()
@@ -111,7 +154,7 @@ func TestSaveText_Empty_Login(t *testing.T) {
 	require.NoError(t, err)
 
 	text := gofakeit.Adverb()
-	id := gofakeit.Digit()
+	id := uuid.NewString()
 
 	md := metadata.Pairs("login", "", "id", id)
 
@@ -142,7 +185,7 @@ func TestSaveText_Empty_Id(t *testing.T) {
 	require.NoError(t, err)
 
 	text := gofakeit.Adverb()
-	id := gofakeit.Digit()
+	id := uuid.NewString()
 
 	md := metadata.Pairs("login", "", "id", id)
 
@@ -158,7 +201,7 @@ func TestSaveBankDetails_Happy(t *testing.T) {
 	ctx, st := suite.New(t)
 	login := gofakeit.Email()
 	pass := randomFakePassword()
-	id := strconv.Itoa(gofakeit.Number(100, 1000))
+	id := uuid.NewString()
 
 	resReg, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
 		Login:    login,
@@ -194,7 +237,7 @@ func TestDownloadBankDetails_Happy(t *testing.T) {
 	ctx, st := suite.New(t)
 	login := gofakeit.Email()
 	pass := randomFakePassword()
-	id := strconv.Itoa(gofakeit.Number(100, 1000))
+	id := uuid.NewString()
 
 	resReg, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
 		Login:    login,
@@ -235,7 +278,7 @@ func TestSaveBankDetails_Emty_Login(t *testing.T) {
 	ctx, st := suite.New(t)
 	login := gofakeit.Email()
 	pass := randomFakePassword()
-	id := strconv.Itoa(gofakeit.Number(100, 1000))
+	id := uuid.NewString()
 
 	resReg, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
 		Login:    login,
@@ -269,7 +312,7 @@ func TestSaveBankDetails_Emty_Bank_details(t *testing.T) {
 	ctx, st := suite.New(t)
 	login := gofakeit.Email()
 	pass := randomFakePassword()
-	id := strconv.Itoa(gofakeit.Number(100, 1000))
+	id := uuid.NewString()
 
 	resReg, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
 		Login:    login,
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/igortoigildin/goph-keeper/internal/client/db"
+	"github.com/igortoigildin/goph-keeper/internal/client/db/pg"
+	"github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/srp"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSRP_LoginWithoutPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	salt, verifier, err := srp.NewVerifier(login, pass)
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Salt: salt, Verifier: verifier})
+	require.NoError(t, err)
+
+	client, start := srpStart(t, ctx, st, login, pass)
+	assert.Equal(t, salt, start.GetSalt())
+
+	proof, err := client.Proof(start.GetSalt(), start.GetServerKey())
+	require.NoError(t, err)
+
+	finishReq := &auth_v1.LoginFinishRequest{Login: login, SessionId: start.GetSessionId(), ClientProof: proof}
+	finish, err := st.AuthClient.LoginFinish(ctx, finishReq)
+	require.NoError(t, err)
+	assert.True(t, client.VerifyServer(finish.GetServerProof()))
+
+	_, err = st.AccountClient.GetUsage(bearerContext(login, finish.GetToken()), &account_v1.GetUsageRequest{})
+	require.NoError(t, err)
+
+	// each login is finished once
+	_, err = st.AuthClient.LoginFinish(ctx, finishReq)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.LoginFinish(ctx, &auth_v1.LoginFinishRequest{Login: login, SessionId: "not-a-uuid", ClientProof: proof})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// the password itself logs in as well
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	client, start = srpStart(t, ctx, st, login, randomFakePassword())
+	proof, err = client.Proof(start.GetSalt(), start.GetServerKey())
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LoginFinish(ctx, &auth_v1.LoginFinishRequest{Login: login, SessionId: start.GetSessionId(), ClientProof: proof})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestSRP_UnknownUser(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+
+	// salt of unknown user does not change, as salt of existing one
+	_, first := srpStart(t, ctx, st, login, pass)
+	client, second := srpStart(t, ctx, st, login, pass)
+	assert.Equal(t, first.GetSalt(), second.GetSalt())
+
+	proof, err := client.Proof(second.GetSalt(), second.GetServerKey())
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LoginFinish(ctx, &auth_v1.LoginFinishRequest{Login: login, SessionId: second.GetSessionId(), ClientProof: proof})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = st.AuthClient.LoginStart(ctx, &auth_v1.LoginStartRequest{Login: login, ClientKey: make([]byte, 256)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSRP_MigratesPasswordHash(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	saveLegacyUser(t, ctx, st, login, pass)
+
+	// legacy user is answered as unknown one, SRP login fails until the password is migrated
+	client, start := srpStart(t, ctx, st, login, pass)
+	_, fake := srpStart(t, ctx, st, login, randomFakePassword())
+	assert.Equal(t, fake.GetSalt(), start.GetSalt())
+
+	proof, err := client.Proof(start.GetSalt(), start.GetServerKey())
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.LoginFinish(ctx, &auth_v1.LoginFinishRequest{Login: login, SessionId: start.GetSessionId(), ClientProof: proof})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "INVALID_CREDENTIALS", errorReason(t, err))
+
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	client, start = srpStart(t, ctx, st, login, pass)
+	assert.NotEqual(t, fake.GetSalt(), start.GetSalt())
+
+	proof, err = client.Proof(start.GetSalt(), start.GetServerKey())
+	require.NoError(t, err)
+
+	finish, err := st.AuthClient.LoginFinish(ctx, &auth_v1.LoginFinishRequest{Login: login, SessionId: start.GetSessionId(), ClientProof: proof})
+	require.NoError(t, err)
+	assert.True(t, client.VerifyServer(finish.GetServerProof()))
+}
+
+func TestSRP_LoginAttemptsLimited(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	limit := st.Cfg.Auth.LoginAttempts
+	if limit == 0 {
+		t.Skip("login attempts are not limited")
+	}
+
+	login := gofakeit.Email()
+	for i := 0; i < limit; i++ {
+		_, err := st.AuthClient.LoginStart(ctx, &auth_v1.LoginStartRequest{Login: login, ClientKey: make([]byte, 256)})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err := st.AuthClient.LoginStart(ctx, &auth_v1.LoginStartRequest{Login: login, ClientKey: make([]byte, 256)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: randomFakePassword()})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// other users are not affected
+	_, err = st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: gofakeit.Email(), Password: randomFakePassword()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func srpStart(t *testing.T, ctx context.Context, st *suite.Suite, login, pass string) (*srp.Client, *auth_v1.LoginStartResponse) {
+	t.Helper()
+
+	client, err := srp.NewClient(login, pass)
+	require.NoError(t, err)
+
+	start, err := st.AuthClient.LoginStart(ctx, &auth_v1.LoginStartRequest{Login: login, ClientKey: client.PublicKey()})
+	require.NoError(t, err)
+
+	return client, start
+}
+
+// saveLegacyUser saves user with bcrypt hash of the password, as users registered before SRP are saved.
+func saveLegacyUser(t *testing.T, ctx context.Context, st *suite.Suite, login, pass string) {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	require.NoError(t, err)
+
+	dbClient, err := pg.New(ctx, st.Cfg.PG.DSN)
+	require.NoError(t, err)
+	defer dbClient.Close()
+
+	_, err = dbClient.DB().ExecContect(ctx, db.Query{
+		Name:     "tests.saveLegacyUser",
+		QueryRaw: "INSERT INTO users (login, password_hash) VALUES ($1, $2)",
+	}, login, hash)
+	require.NoError(t, err)
+}
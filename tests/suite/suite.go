@@ -2,16 +2,25 @@ package suite
 
 import (
 	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
 	"os"
 	"testing"
 
 	"github.com/igortoigildin/goph-keeper/internal/server/app"
 	config "github.com/igortoigildin/goph-keeper/internal/server/config"
+	account "github.com/igortoigildin/goph-keeper/pkg/account_v1"
+	admin "github.com/igortoigildin/goph-keeper/pkg/admin_v1"
+	audit "github.com/igortoigildin/goph-keeper/pkg/audit_v1"
 	auth "github.com/igortoigildin/goph-keeper/pkg/auth_v1"
 	download "github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	sync "github.com/igortoigildin/goph-keeper/pkg/sync_v1"
 	upload "github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	vault "github.com/igortoigildin/goph-keeper/pkg/vault_v2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Suite struct {
@@ -20,6 +29,14 @@ type Suite struct {
 	AuthClient     auth.AuthV1Client
 	UploadClient   upload.UploadV1Client
 	DownloadClient download.DownloadV1Client
+	VaultClient    vault.VaultV2Client
+	HealthClient   healthpb.HealthClient
+	AuditClient    audit.AuditV1Client
+	AccountClient  account.AccountV1Client
+	AdminClient    admin.AdminV1Client
+	SyncClient     sync.SyncV1Client
+	HTTPClient     *http.Client
+	GatewayURL     string
 	server         *app.App
 }
 
@@ -57,6 +74,16 @@ func New(t *testing.T) (context.Context, *Suite) {
 		AuthClient:     auth.NewAuthV1Client(cc),
 		UploadClient:   upload.NewUploadV1Client(cc),
 		DownloadClient: download.NewDownloadV1Client(cc),
+		VaultClient:    vault.NewVaultV2Client(cc),
+		HealthClient:   healthpb.NewHealthClient(cc),
+		AuditClient:    audit.NewAuditV1Client(cc),
+		AccountClient:  account.NewAccountV1Client(cc),
+		AdminClient:    admin.NewAdminV1Client(cc),
+		SyncClient:     sync.NewSyncV1Client(cc),
+		HTTPClient: &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // self-signed test certificate
+		}},
+		GatewayURL: "https://" + gatewayAddress(cfg),
 	}
 }
 
@@ -69,3 +96,11 @@ func configPath() string {
 
 	return "../config/local_tests.yaml"
 }
+
+func gatewayAddress(cfg *config.Config) string {
+	if cfg.HTTP.Host == "" {
+		return net.JoinHostPort(grpcHost, cfg.HTTP.Port)
+	}
+
+	return cfg.HTTP.Address()
+}
@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestToken_ScopeIsEnforced(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+	userCtx := loginContext(t, ctx, st, login, pass)
+
+	id, otherID := uuid.NewString(), uuid.NewString()
+	for _, dataID := range []string{id, otherID} {
+		_, err = st.UploadClient.UploadText(withID(userCtx, dataID), &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+		require.NoError(t, err)
+	}
+
+	created, err := st.AuthClient.CreateToken(userCtx, &auth_v1.CreateTokenRequest{
+		Name:      "ci",
+		ExpiresAt: timestamppb.New(time.Now().Add(time.Hour)),
+		Scope:     &auth_v1.TokenScope{ReadOnly: true, SecretIds: []string{id}},
+	})
+	require.NoError(t, err)
+
+	list, err := st.AuthClient.ListTokens(userCtx, &auth_v1.ListTokensRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetTokens(), 1)
+	assert.Equal(t, created.GetInfo().GetId(), list.GetTokens()[0].GetId())
+	assert.Equal(t, []string{id}, list.GetTokens()[0].GetScope().GetSecretIds())
+
+	// login of the token owner is not required
+	tokenCtx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+created.GetToken()))
+
+	_, err = st.DownloadClient.DownloadText(tokenCtx, &download_v1.DownloadTextRequest{Uuid: id})
+	require.NoError(t, err)
+
+	_, err = st.DownloadClient.DownloadText(tokenCtx, &download_v1.DownloadTextRequest{Uuid: otherID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.UploadClient.UploadText(withID(tokenCtx, id), &upload_v1.UploadTextRequest{Text: gofakeit.Sentence(5)})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// api tokens can not create other tokens
+	_, err = st.AuthClient.CreateToken(tokenCtx, &auth_v1.CreateTokenRequest{
+		Name:      "nested",
+		ExpiresAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = st.AuthClient.RevokeToken(userCtx, &auth_v1.RevokeTokenRequest{Id: created.GetInfo().GetId()})
+	require.NoError(t, err)
+
+	_, err = st.DownloadClient.DownloadText(tokenCtx, &download_v1.DownloadTextRequest{Uuid: id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestToken_ExpiryIsLimited(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	login, pass := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+	userCtx := loginContext(t, ctx, st, login, pass)
+
+	for _, expiresAt := range []time.Time{time.Now().Add(-time.Hour), time.Now().Add(st.Cfg.Auth.APITokenMaxTTL + time.Hour)} {
+		_, err = st.AuthClient.CreateToken(userCtx, &auth_v1.CreateTokenRequest{
+			Name:      "ci",
+			ExpiresAt: timestamppb.New(expiresAt),
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = st.DownloadClient.DownloadText(bearerContext("", "gkp_"+gofakeit.LetterN(43)),
+		&download_v1.DownloadTextRequest{Uuid: uuid.NewString()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// withID returns outgoing context with id of the item added to its metadata.
+func withID(ctx context.Context, id string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "id", id)
+}
@@ -0,0 +1,202 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUploadSession_Resume_Happy(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	data := []byte(gofakeit.LoremIpsumParagraph(200, 10, 20, "\n"))
+
+	initResp, err := st.UploadClient.InitUpload(ctx, &upload_v1.InitUploadRequest{
+		FileName: "lorem.txt",
+		Size:     uint64(len(data)),
+	})
+	require.NoError(t, err)
+	assert.Zero(t, initResp.GetCommittedOffset())
+
+	// first stream sends only the first half of the file, which is less than a part,
+	// so nothing is committed and the client has to resume from zero offset
+	stream, err := st.UploadClient.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadChunkRequest{
+		UploadId: initResp.GetUploadId(),
+		Chunk:    data[:len(data)/2],
+	}))
+	chunkResp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	statusResp, err := st.UploadClient.GetUploadStatus(ctx, &upload_v1.GetUploadStatusRequest{
+		UploadId: initResp.GetUploadId(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, chunkResp.GetCommittedOffset(), statusResp.GetCommittedOffset())
+
+	offset := statusResp.GetCommittedOffset()
+	stream, err = st.UploadClient.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadChunkRequest{
+		UploadId: initResp.GetUploadId(),
+		Offset:   offset,
+		Chunk:    data[offset:],
+	}))
+	chunkResp, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(data)), chunkResp.GetCommittedOffset())
+
+	sum := sha256.Sum256(data)
+	finResp, err := st.UploadClient.FinalizeUpload(ctx, &upload_v1.FinalizeUploadRequest{
+		UploadId: initResp.GetUploadId(),
+		Size:     uint64(len(data)),
+		Sha256:   hex.EncodeToString(sum[:]),
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, finResp.GetEtag())
+	assert.Equal(t, hex.EncodeToString(sum[:]), finResp.GetSha256())
+}
+
+func TestUploadSession_ChecksumMismatch(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	data := []byte(gofakeit.Sentence(10))
+
+	initResp, err := st.UploadClient.InitUpload(ctx, &upload_v1.InitUploadRequest{
+		FileName: "sentence.txt",
+		Size:     uint64(len(data)),
+	})
+	require.NoError(t, err)
+
+	stream, err := st.UploadClient.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadChunkRequest{
+		UploadId: initResp.GetUploadId(),
+		Chunk:    data,
+	}))
+	_, err = stream.CloseAndRecv()
+	require.NoError(t, err)
+
+	_, err = st.UploadClient.FinalizeUpload(ctx, &upload_v1.FinalizeUploadRequest{
+		UploadId: initResp.GetUploadId(),
+		Size:     uint64(len(data)),
+		Sha256:   hex.EncodeToString(make([]byte, sha256.Size)),
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.DataLoss, status.Code(err))
+}
+
+func TestUploadSession_OffsetMismatchAndIncomplete(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	data := []byte(gofakeit.Sentence(10))
+
+	initResp, err := st.UploadClient.InitUpload(ctx, &upload_v1.InitUploadRequest{
+		FileName: "sentence.txt",
+		Size:     uint64(len(data)),
+	})
+	require.NoError(t, err)
+
+	// nothing is uploaded yet, so the upload can not be finalized
+	sum := sha256.Sum256(data)
+	_, err = st.UploadClient.FinalizeUpload(ctx, &upload_v1.FinalizeUploadRequest{
+		UploadId: initResp.GetUploadId(),
+		Size:     uint64(len(data)),
+		Sha256:   hex.EncodeToString(sum[:]),
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Equal(t, "UPLOAD_INCOMPLETE", errorReason(t, err))
+
+	// the client has to resume from committed offset
+	stream, err := st.UploadClient.UploadChunk(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&upload_v1.UploadChunkRequest{
+		UploadId: initResp.GetUploadId(),
+		Offset:   1,
+		Chunk:    data[1:],
+	}))
+	_, err = stream.CloseAndRecv()
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, "OFFSET_MISMATCH", errorReason(t, err))
+}
+
+func TestUploadSession_TooLarge(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	md := metadata.Pairs("login", login, "id", uuid.NewString(), "authorization", "Bearer "+resp.GetToken())
+	ctx = metadata.NewOutgoingContext(context.Background(), md)
+
+	// storage accepts at most 10000 parts
+	_, err = st.UploadClient.InitUpload(ctx, &upload_v1.InitUploadRequest{
+		FileName: "huge.bin",
+		Size:     10000*5*1024*1024 + 1,
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "FILE_TOO_LARGE", errorReason(t, err))
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/download_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestValidation_InvalidRequests(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{Login: login, Password: pass})
+	require.NoError(t, err)
+
+	authCtx := func(id string) context.Context {
+		return metadata.NewOutgoingContext(context.Background(),
+			metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+resp.GetToken()))
+	}
+
+	// id of the item in request body must be a UUID
+	_, err = st.DownloadClient.DownloadText(authCtx(uuid.NewString()), &download_v1.DownloadTextRequest{Uuid: "not-a-uuid"})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"uuid": "string.uuid"}, fieldViolations(t, err))
+
+	// and so must be id passed in metadata
+	_, err = st.UploadClient.UploadText(authCtx("42"), &upload_v1.UploadTextRequest{Text: gofakeit.Adverb()})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, map[string]string{"metadata.id": "string.uuid"}, fieldViolations(t, err))
+
+	_, err = st.UploadClient.UploadText(authCtx(uuid.NewString()), &upload_v1.UploadTextRequest{})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "text is required")
+	assert.Equal(t, map[string]string{"text": "required"}, fieldViolations(t, err))
+
+	_, err = st.UploadClient.UploadPassword(authCtx(uuid.NewString()), &upload_v1.UploadPasswordRequest{
+		Data: map[string]string{"login": gofakeit.Username(), "pin": "1234"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, fieldViolations(t, err), "data")
+}
+
+// fieldViolations returns reasons of the violations listed in google.rpc.BadRequest details by field.
+func fieldViolations(t *testing.T, err error) map[string]string {
+	t.Helper()
+
+	violations := make(map[string]string)
+
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				violations[v.GetField()] = v.GetReason()
+			}
+		}
+	}
+
+	return violations
+}
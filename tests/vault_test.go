@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	gofakeit "github.com/brianvoe/gofakeit/v7"
+	"github.com/google/uuid"
+	"github.com/igortoigildin/goph-keeper/pkg/auth_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/upload_v1"
+	"github.com/igortoigildin/goph-keeper/pkg/vault_v2"
+	"github.com/igortoigildin/goph-keeper/tests/suite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestVault_SecretLifecycle(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	ctx = metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "authorization", "Bearer "+resp.GetToken()))
+
+	created, err := st.VaultClient.CreateSecret(ctx, &vault_v2.CreateSecretRequest{Secret: &vault_v2.Secret{
+		Name: "mail",
+		Tags: []string{"work"},
+		Payload: &vault_v2.Secret_Credential{Credential: &vault_v2.Credential{
+			Login:    gofakeit.Username(),
+			Password: pass,
+		}},
+	}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.GetId())
+	assert.Equal(t, vault_v2.SecretType_SECRET_TYPE_CREDENTIAL, created.GetType())
+	assert.Equal(t, int64(1), created.GetVersion())
+
+	// read mask limits returned fields
+	got, err := st.VaultClient.GetSecret(ctx, &vault_v2.GetSecretRequest{
+		Id:       created.GetId(),
+		ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "payload"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "mail", got.GetName())
+	assert.Equal(t, pass, got.GetCredential().GetPassword())
+	assert.Empty(t, got.GetTags())
+
+	updated, err := st.VaultClient.UpdateSecret(ctx, &vault_v2.UpdateSecretRequest{
+		Secret:     &vault_v2.Secret{Id: created.GetId(), Name: "personal mail"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		Version:    created.GetVersion(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "personal mail", updated.GetName())
+	assert.Equal(t, []string{"work"}, updated.GetTags())
+	assert.Equal(t, int64(2), updated.GetVersion())
+
+	// stale version is rejected
+	_, err = st.VaultClient.UpdateSecret(ctx, &vault_v2.UpdateSecretRequest{
+		Secret:     &vault_v2.Secret{Id: created.GetId(), Name: "stale"},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+		Version:    created.GetVersion(),
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// stale update does not replace the payload either
+	_, err = st.VaultClient.UpdateSecret(ctx, &vault_v2.UpdateSecretRequest{
+		Secret: &vault_v2.Secret{Id: created.GetId(), Payload: &vault_v2.Secret_Credential{Credential: &vault_v2.Credential{
+			Login:    gofakeit.Username(),
+			Password: randomFakePassword(),
+		}}},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"payload"}},
+		Version:    created.GetVersion(),
+	})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	got, err = st.VaultClient.GetSecret(ctx, &vault_v2.GetSecretRequest{Id: created.GetId()})
+	require.NoError(t, err)
+	assert.Equal(t, pass, got.GetCredential().GetPassword())
+	assert.Equal(t, int64(2), got.GetVersion())
+
+	list, err := st.VaultClient.ListSecrets(ctx, &vault_v2.ListSecretsRequest{Tag: "work"})
+	require.NoError(t, err)
+	require.Len(t, list.GetSecrets(), 1)
+	assert.Equal(t, created.GetId(), list.GetSecrets()[0].GetId())
+	assert.Nil(t, list.GetSecrets()[0].GetPayload())
+
+	_, err = st.VaultClient.DeleteSecret(ctx, &vault_v2.DeleteSecretRequest{Id: created.GetId()})
+	require.NoError(t, err)
+
+	_, err = st.VaultClient.GetSecret(ctx, &vault_v2.GetSecretRequest{Id: created.GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestVault_ServesV1Data(t *testing.T) {
+	ctx, st := suite.New(t)
+	login := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &auth_v1.RegisterRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	resp, err := st.AuthClient.Login(ctx, &auth_v1.LoginRequest{
+		Login:    login,
+		Password: pass,
+	})
+	require.NoError(t, err)
+
+	id := uuid.NewString()
+	text := gofakeit.Sentence(5)
+
+	v1Ctx := metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "id", id, "authorization", "Bearer "+resp.GetToken()))
+	_, err = st.UploadClient.UploadText(v1Ctx, &upload_v1.UploadTextRequest{
+		Text:     text,
+		Metadata: "note",
+	})
+	require.NoError(t, err)
+
+	ctx = metadata.NewOutgoingContext(context.Background(),
+		metadata.Pairs("login", login, "authorization", "Bearer "+resp.GetToken()))
+
+	list, err := st.VaultClient.ListSecrets(ctx, &vault_v2.ListSecretsRequest{Type: vault_v2.SecretType_SECRET_TYPE_TEXT})
+	require.NoError(t, err)
+	require.Len(t, list.GetSecrets(), 1)
+	assert.Equal(t, id, list.GetSecrets()[0].GetId())
+
+	got, err := st.VaultClient.GetSecret(ctx, &vault_v2.GetSecretRequest{Id: id})
+	require.NoError(t, err)
+	assert.Equal(t, "note", got.GetName())
+	assert.Equal(t, text, got.GetText().GetText())
+}